@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"pan-search-api/config"
+	"pan-search-api/database"
+	"pan-search-api/importer"
+	"pan-search-api/models"
+	"pan-search-api/search"
+	"pan-search-api/services"
+)
+
+// 命令行用法
+const commandUsage = `usage: pan-search-api [command]
+
+commands:
+  (none)                       启动API服务
+  set-role <username> <role>   设置用户角色，例如创建第一个管理员
+  import -file <path> [flags]  从CSV或JSONL批量导入资源，-h 查看参数
+  reindex                      从数据库重建embedded搜索引擎的索引文件，需先停止API服务
+  backfill                     重新计算资源的派生字段（标题和标签拼音、文件大小字节数、网盘分享去重键）`
+
+// runCommand 执行命令行子命令
+func runCommand(args []string) error {
+	switch args[0] {
+	case "set-role":
+		return setUserRole(args[1:])
+	case "import":
+		return importResources(args[1:])
+	case "reindex":
+		return rebuildSearchIndex()
+	case "backfill":
+		return backfillResources()
+	case "help", "-h", "--help":
+		fmt.Println(commandUsage)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], commandUsage)
+	}
+}
+
+// setUserRole 设置用户角色
+func setUserRole(args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: pan-search-api set-role <username> <role>")
+	}
+	username, role := args[0], args[1]
+
+	var count int64
+	if err := database.DB.Model(&models.Role{}).Where("name = ?", role).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("role %q does not exist", role)
+	}
+
+	result := database.DB.Model(&models.User{}).Where("username = ?", username).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user %q not found or already has role %q", username, role)
+	}
+
+	fmt.Printf("user %s now has role %s\n", username, role)
+	return nil
+}
+
+// importResources 批量导入资源，输出JSON格式的导入报告
+func importResources(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := fs.String("file", "", "CSV或JSONL文件路径")
+	format := fs.String("format", "", "文件格式 csv/jsonl，默认按扩展名判断")
+	mapping := fs.String("map", "", "列映射，如 title=名称,category=分类")
+	dryRun := fs.Bool("dry-run", false, "只校验不写入")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+
+	if *format == "" {
+		*format = importer.DetectFormat(*file)
+	}
+	columnMapping, err := importer.ParseMapping(*mapping)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	report, importErr := importer.Import(database.DB, f, importer.Options{
+		Format:  *format,
+		Mapping: columnMapping,
+		DryRun:  *dryRun,
+	})
+	// 命令行导入不经过运行中的服务，标记embedded索引在服务下次启动时重建
+	if report != nil && report.Created > 0 && !*dryRun {
+		if err := search.MarkIndexStale(config.GlobalConfig.Search); err != nil {
+			return err
+		}
+	}
+	if report != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	}
+	return importErr
+}
+
+// rebuildSearchIndex 重建embedded搜索引擎的索引文件
+func rebuildSearchIndex() error {
+	cfg := config.GlobalConfig.Search
+	if cfg.Engine != search.EngineEmbedded {
+		fmt.Printf("search engine %q queries the database directly, nothing to rebuild\n", cfg.Engine)
+		return nil
+	}
+
+	if cfg.UserDict != "" {
+		if err := search.LoadUserDictionary(cfg.UserDict); err != nil {
+			return err
+		}
+	}
+	cfg.RebuildOnStart = true
+	engine, err := search.OpenEmbeddedEngine(cfg, database.DB)
+	if err != nil {
+		return err
+	}
+	return engine.Close()
+}
+
+// backfillResources 回填资源的派生字段
+func backfillResources() error {
+	count, err := services.BackfillResources(database.DB)
+	fmt.Printf("%d resources updated\n", count)
+	return err
+}
@@ -0,0 +1,74 @@
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"pan-search-api/config"
+	"strings"
+)
+
+// ErrInvalidCursor 游标格式错误、签名不匹配或不属于当前查询
+var ErrInvalidCursor = errors.New("无效的分页游标")
+
+// Cursor 游标分页的位置，记录上一页最后一条记录的排序字段值。
+// 游标经过签名，客户端只能原样传回，不能修改或伪造
+type Cursor struct {
+	Scope    string  `json:"s"`           // 查询条件指纹，游标只能用于生成它的查询
+	Keys     []int64 `json:"k"`           // 排序字段的值，时间为Unix纳秒
+	ID       string  `json:"i"`           // 最后一条记录的ID，排序字段相同时用于定位
+	Snapshot int64   `json:"t,omitempty"` // 第一页的查询时间，用于复用当时的排序结果
+}
+
+// CursorPagination 游标分页信息
+type CursorPagination struct {
+	PageSize       int    `json:"pageSize"`
+	NextCursor     string `json:"nextCursor,omitempty"` // 下一页的游标，没有更多数据时为空
+	HasMore        bool   `json:"hasMore"`
+	Total          *int64 `json:"total,omitempty"`          // 只在第一页返回
+	TotalEstimated bool   `json:"totalEstimated,omitempty"` // total为估算值
+}
+
+// EncodeCursor 编码并签名游标
+func EncodeCursor(cursor Cursor) string {
+	payload, _ := json.Marshal(cursor)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signCursor(encoded))
+}
+
+// DecodeCursor 校验签名并解码游标，scope与游标中的查询条件指纹不一致时返回ErrInvalidCursor
+func DecodeCursor(token, scope string) (*Cursor, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, signCursor(encoded)) {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil || cursor.Scope != scope {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// CursorScope 根据查询参数生成查询条件指纹
+func CursorScope(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+// signCursor 使用JWT密钥签名，加前缀与token签名区分
+func signCursor(encoded string) []byte {
+	mac := hmac.New(sha256.New, []byte("cursor:"+config.GlobalConfig.JWT.Secret))
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,95 @@
+package common
+
+import (
+	"encoding/base64"
+	"errors"
+	"pan-search-api/config"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func withCursorSecret(t *testing.T, secret string) {
+	t.Helper()
+	old := config.GlobalConfig
+	config.GlobalConfig = &config.Config{JWT: config.JWTConfig{Secret: secret}}
+	t.Cleanup(func() { config.GlobalConfig = old })
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	withCursorSecret(t, "test-secret")
+
+	scope := CursorScope("电影", "hot")
+	cursor := Cursor{Scope: scope, Keys: []int64{0, 3, -1}, ID: "abc", Snapshot: 1700000000}
+	got, err := DecodeCursor(EncodeCursor(cursor), scope)
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	if !reflect.DeepEqual(*got, cursor) {
+		t.Errorf("DecodeCursor() = %+v, want %+v", *got, cursor)
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	withCursorSecret(t, "test-secret")
+
+	scope := CursorScope("电影")
+	token := EncodeCursor(Cursor{Scope: scope, Keys: []int64{1}, ID: "abc"})
+	encoded, signature, _ := strings.Cut(token, ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"` + scope + `","k":[1],"i":"xyz"}`))
+
+	tests := []struct {
+		name  string
+		token string
+		scope string
+	}{
+		{"empty", "", scope},
+		{"missing separator", encoded + signature, scope},
+		{"tampered payload", forged + "." + signature, scope},
+		{"tampered signature", encoded + "." + signature[:len(signature)-2] + "AA", scope},
+		{"signature not base64", encoded + ".!!!", scope},
+		{"other scope", token, CursorScope("音乐")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.token, tt.scope); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("DecodeCursor(%q) error = %v, want %v", tt.token, err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorOtherSecret(t *testing.T) {
+	withCursorSecret(t, "old-secret")
+	scope := CursorScope("电影")
+	token := EncodeCursor(Cursor{Scope: scope, ID: "abc"})
+
+	// 更换密钥后旧游标失效
+	withCursorSecret(t, "new-secret")
+	if _, err := DecodeCursor(token, scope); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("DecodeCursor() error = %v, want %v", err, ErrInvalidCursor)
+	}
+}
+
+func TestCursorScope(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		same bool
+	}{
+		{"same parts", []string{"电影", "hot"}, []string{"电影", "hot"}, true},
+		{"different value", []string{"电影", "hot"}, []string{"电影", "new"}, false},
+		{"different order", []string{"a", "b"}, []string{"b", "a"}, false},
+		// 各部分之间有分隔符，拼接结果相同也不会冲突
+		{"boundary", []string{"ab", "c"}, []string{"a", "bc"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CursorScope(tt.a...) == CursorScope(tt.b...); got != tt.same {
+				t.Errorf("CursorScope(%q) == CursorScope(%q) is %v, want %v", tt.a, tt.b, got, tt.same)
+			}
+		})
+	}
+}
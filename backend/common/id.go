@@ -0,0 +1,16 @@
+package common
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// GenerateID 生成32位十六进制随机ID。随机源不可用时panic，与uuid.New一致，
+// 不退化为可预测的ID
+func GenerateID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic("common: failed to read random bytes: " + err.Error())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,87 @@
+package common
+
+import (
+	"errors"
+	"pan-search-api/config"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 默认token有效期
+const defaultTokenExpire = 24 * time.Hour
+
+var (
+	// ErrTokenExpired token已过期
+	ErrTokenExpired = errors.New("token已过期")
+	// ErrTokenInvalid token无效（签名、签发者或格式错误）
+	ErrTokenInvalid = errors.New("无效的token")
+)
+
+// Claims JWT声明
+type Claims struct {
+	UserID    string `json:"uid"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// GenerateToken 签发访问token，sessionID关联服务端会话以支持吊销
+func GenerateToken(userID, role, sessionID string) (string, time.Time, error) {
+	cfg := config.GlobalConfig.JWT
+	expire := cfg.Expire
+	if expire <= 0 {
+		expire = defaultTokenExpire
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(expire)
+	claims := Claims{
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    cfg.Issuer,
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(cfg.Secret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseToken 解析并校验token的签名、有效期和签发者
+func ParseToken(tokenString string) (*Claims, error) {
+	cfg := config.GlobalConfig.JWT
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(cfg.Secret), nil
+	}, options...)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, ErrTokenInvalid
+	}
+	if !token.Valid || claims.UserID == "" || claims.SessionID == "" {
+		return nil, ErrTokenInvalid
+	}
+
+	return claims, nil
+}
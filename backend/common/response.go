@@ -21,6 +21,7 @@ type Pagination struct {
 	PageSize  int `json:"pageSize"`
 	Total     int `json:"total"`
 	TotalPages int `json:"totalPages"`
+	TotalEstimated bool `json:"totalEstimated,omitempty"` // total为估算值
 }
 
 // PaginatedResponse 分页响应
@@ -29,6 +30,12 @@ type PaginatedResponse struct {
 	Pagination Pagination  `json:"pagination"`
 }
 
+// CursorPaginatedResponse 游标分页响应
+type CursorPaginatedResponse struct {
+	List       interface{}      `json:"list"`
+	Pagination CursorPagination `json:"pagination"`
+}
+
 // Success 成功响应
 func Success(c *gin.Context, data interface{}) {
 	c.JSON(http.StatusOK, Response{
@@ -41,22 +48,35 @@ func Success(c *gin.Context, data interface{}) {
 
 // SuccessWithPagination 带分页的成功响应
 func SuccessWithPagination(c *gin.Context, list interface{}, page, pageSize, total int) {
+	data := PaginatedResponse{
+		List:       list,
+		Pagination: NewPagination(page, pageSize, total),
+	}
+
+	Success(c, data)
+}
+
+// SuccessWithCursor 带游标分页的成功响应
+func SuccessWithCursor(c *gin.Context, list interface{}, pagination CursorPagination) {
+	Success(c, CursorPaginatedResponse{
+		List:       list,
+		Pagination: pagination,
+	})
+}
+
+// NewPagination 计算分页信息
+func NewPagination(page, pageSize, total int) Pagination {
 	totalPages := total / pageSize
 	if total%pageSize > 0 {
 		totalPages++
 	}
 
-	data := PaginatedResponse{
-		List: list,
-		Pagination: Pagination{
-			Page:      page,
-			PageSize:  pageSize,
-			Total:     total,
-			TotalPages: totalPages,
-		},
+	return Pagination{
+		Page:      page,
+		PageSize:  pageSize,
+		Total:     total,
+		TotalPages: totalPages,
 	}
-
-	Success(c, data)
 }
 
 // Error 错误响应
@@ -74,6 +94,16 @@ func BadRequest(c *gin.Context, message string) {
 	Error(c, http.StatusBadRequest, message)
 }
 
+// BadRequestWithData 400错误，data中携带错误详情
+func BadRequestWithData(c *gin.Context, message string, data interface{}) {
+	c.JSON(http.StatusBadRequest, Response{
+		Code:      http.StatusBadRequest,
+		Message:   message,
+		Data:      data,
+		Timestamp: time.Now().UnixMilli(),
+	})
+}
+
 // Unauthorized 401错误
 func Unauthorized(c *gin.Context, message string) {
 	Error(c, http.StatusUnauthorized, message)
@@ -89,6 +119,11 @@ func NotFound(c *gin.Context, message string) {
 	Error(c, http.StatusNotFound, message)
 }
 
+// Conflict 409错误
+func Conflict(c *gin.Context, message string) {
+	Error(c, http.StatusConflict, message)
+}
+
 // InternalServerError 500错误
 func InternalServerError(c *gin.Context, message string) {
 	Error(c, http.StatusInternalServerError, message)
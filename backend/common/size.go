@@ -0,0 +1,45 @@
+package common
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSize 无法识别的文件大小
+var ErrInvalidSize = errors.New("无法识别的文件大小")
+
+// sizeUnits 文件大小单位，按1024换算，长单位在前以便优先匹配
+var sizeUnits = []struct {
+	Suffix string
+	Bytes  float64
+}{
+	{"TIB", 1 << 40}, {"GIB", 1 << 30}, {"MIB", 1 << 20}, {"KIB", 1 << 10},
+	{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+	{"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize 解析"1.5GB"、"700 MB"、"1.2G"、"1,024KB"等格式的文件大小为字节数，单位不区分大小写，无单位时按字节计算
+func ParseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), ",", ""))
+	if s == "" {
+		return 0, ErrInvalidSize
+	}
+
+	multiplier := 1.0
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(s, unit.Suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.Suffix))
+			multiplier = unit.Bytes
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 || math.IsNaN(value) || value*multiplier >= math.MaxInt64 {
+		return 0, ErrInvalidSize
+	}
+	return int64(value * multiplier), nil
+}
@@ -0,0 +1,54 @@
+package common
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+		ok    bool
+	}{
+		{"1.5GB", 1610612736, true},
+		{"700 MB", 734003200, true},
+		{"1.2G", 1288490188, true},
+		{"1,024KB", 1048576, true},
+		{"2tb", 2199023255552, true},
+		{"4 GiB", 4294967296, true},
+		{"512k", 524288, true},
+		{"10 B", 10, true},
+		{"123456", 123456, true},
+		{"0", 0, true},
+		{"  3 MB  ", 3145728, true},
+		{"1e3KB", 1024000, true},
+
+		{"", 0, false},
+		{"   ", 0, false},
+		{"GB", 0, false},
+		{"-1GB", 0, false},
+		{"abc", 0, false},
+		{"1.5 PB", 0, false},
+		{"10XB", 0, false},
+		{"NaN", 0, false},
+		{"Inf", 0, false},
+		{"99999999TB", 0, false},
+		{"未知", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSize(tt.input)
+			if !tt.ok {
+				if !errors.Is(err, ErrInvalidSize) {
+					t.Errorf("ParseSize(%q) = %d, %v, want ErrInvalidSize", tt.input, got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseSize(%q) = %d, %v, want %d", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
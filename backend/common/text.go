@@ -0,0 +1,11 @@
+package common
+
+import "unicode/utf8"
+
+// Truncate 按字符截断字符串，不会截断多字节字符
+func Truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,20 @@
+package common
+
+import "net/http"
+
+// UserAgentTransport 为请求设置User-Agent，用于链接检测和资源采集等对外请求
+type UserAgentTransport struct {
+	UserAgent string
+	Base      http.RoundTripper // 为空时使用http.DefaultTransport
+}
+
+// RoundTrip 复制请求后设置User-Agent，不修改调用方的请求
+func (t *UserAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("User-Agent", t.UserAgent)
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -11,18 +12,25 @@ import (
 
 // Config 全局配置
 type Config struct {
-	App      AppConfig      `yaml:"app"`
-	Database DatabaseConfig `yaml:"database"`
-	JWT      JWTConfig      `yaml:"jwt"`
-	Log      LogConfig      `yaml:"log"`
+	App       AppConfig       `yaml:"app"`
+	Database  DatabaseConfig  `yaml:"database"`
+	JWT       JWTConfig       `yaml:"jwt"`
+	Log       LogConfig       `yaml:"log"`
+	Search    SearchConfig    `yaml:"search"`
+	Trending  TrendingConfig  `yaml:"trending"`
+	LinkCheck LinkCheckConfig `yaml:"linkCheck"`
+	Expiry    ExpiryConfig    `yaml:"expiry"`
+	Report    ReportConfig    `yaml:"report"`
+	Connector ConnectorConfig `yaml:"connector"`
 }
 
 // AppConfig 应用配置
 type AppConfig struct {
-	Name    string `yaml:"name"`
-	Version string `yaml:"version"`
-	Port    int    `yaml:"port"`
-	Mode    string `yaml:"mode"`
+	Name           string   `yaml:"name"`
+	Version        string   `yaml:"version"`
+	Port           int      `yaml:"port"`
+	Mode           string   `yaml:"mode"`
+	TrustedProxies []string `yaml:"trustedProxies"` // 可信反向代理的IP或CIDR，只采信它们设置的X-Forwarded-For
 }
 
 // DatabaseConfig 数据库配置
@@ -42,9 +50,106 @@ type DatabaseConfig struct {
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	Secret string        `yaml:"secret"`
-	Issuer string        `yaml:"issuer"`
-	Expire time.Duration `yaml:"expire"`
+	Secret        string        `yaml:"secret"`
+	Issuer        string        `yaml:"issuer"`
+	Expire        time.Duration `yaml:"expire"`
+	RefreshExpire time.Duration `yaml:"refreshExpire"`
+}
+
+// SearchConfig 搜索引擎配置
+type SearchConfig struct {
+	Engine         string          `yaml:"engine"`         // like/mysql/embedded
+	MaxCandidates  int             `yaml:"maxCandidates"`  // 单次搜索最多返回的候选结果数
+	UserDict       string          `yaml:"userDict"`       // 用户分词词典路径
+	IndexPath      string          `yaml:"indexPath"`      // embedded引擎的索引文件路径
+	SaveInterval   time.Duration   `yaml:"saveInterval"`   // embedded引擎索引落盘间隔
+	RebuildOnStart bool            `yaml:"rebuildOnStart"` // 启动时从数据库重建embedded索引
+	Highlight      HighlightConfig `yaml:"highlight"`
+	Suggest        SuggestConfig   `yaml:"suggest"`
+}
+
+// SuggestConfig 搜索建议配置
+type SuggestConfig struct {
+	RefreshInterval time.Duration `yaml:"refreshInterval"` // 重建前缀索引的间隔
+	HalfLife        time.Duration `yaml:"halfLife"`        // 搜索次数的衰减半衰期
+	Lookback        time.Duration `yaml:"lookback"`        // 统计多长时间内的搜索记录
+	MaxTitles       int           `yaml:"maxTitles"`       // 最多收录的资源标题数，按浏览量选取
+}
+
+// HighlightConfig 搜索结果高亮配置
+type HighlightConfig struct {
+	PreTag        string `yaml:"preTag"`        // 命中词前的标记，默认<em>
+	PostTag       string `yaml:"postTag"`       // 命中词后的标记，默认</em>
+	SnippetLength int    `yaml:"snippetLength"` // 描述摘要的最大字符数
+	NoEscape      bool   `yaml:"noEscape"`      // 不对结果做HTML转义，标记不是HTML时使用
+}
+
+// TrendingConfig 热门趋势配置
+type TrendingConfig struct {
+	Interval       time.Duration `yaml:"interval"`       // 重新计算的间隔
+	TopKeywords    int           `yaml:"topKeywords"`    // 每小时统计的热门搜索词数量
+	HitsPerKeyword int           `yaml:"hitsPerKeyword"` // 每个搜索词计入的搜索结果数量
+}
+
+// LinkCheckConfig 分享链接检测配置
+type LinkCheckConfig struct {
+	Enabled          bool              `yaml:"enabled"`
+	Interval         time.Duration     `yaml:"interval"`         // 查询待检测资源的间隔
+	BatchSize        int               `yaml:"batchSize"`        // 每轮最多检测的资源数
+	Concurrency      int               `yaml:"concurrency"`      // 同时检测的资源数
+	Timeout          time.Duration     `yaml:"timeout"`          // 单次检测的超时时间
+	FailureThreshold int               `yaml:"failureThreshold"` // 连续失效多少次后标记资源失效
+	UserAgent        string            `yaml:"userAgent"`
+	Hosts            map[string]string `yaml:"hosts"` // 各网盘检测接口的地址，按网盘名覆盖默认值
+}
+
+// ExpiryConfig 资源过期处理配置
+type ExpiryConfig struct {
+	SweepInterval time.Duration `yaml:"sweepInterval"` // 将已过期资源标记失效的间隔
+	SoonWindow    time.Duration `yaml:"soonWindow"`    // 即将过期列表默认的时间范围
+}
+
+// ReportConfig 资源举报配置
+type ReportConfig struct {
+	AutoHideThreshold int           `yaml:"autoHideThreshold"` // 多少个用户举报失效后自动隐藏资源，0表示不自动隐藏
+	RateLimit         int           `yaml:"rateLimit"`         // 同一IP或用户在rateWindow内最多提交的举报数
+	RateWindow        time.Duration `yaml:"rateWindow"`
+}
+
+// ConnectorConfig 资源自动采集配置
+type ConnectorConfig struct {
+	Enabled   bool           `yaml:"enabled"`
+	Interval  time.Duration  `yaml:"interval"` // 默认采集间隔，来源未配置interval时使用
+	Timeout   time.Duration  `yaml:"timeout"`  // 单次抓取的超时时间
+	MaxItems  int            `yaml:"maxItems"` // 每次采集最多处理的条目数
+	UserAgent string         `yaml:"userAgent"`
+	Sources   []SourceConfig `yaml:"sources"`
+}
+
+// SourceConfig 采集来源
+type SourceConfig struct {
+	Name         string        `yaml:"name"` // 唯一名称，用于保存采集游标和统计
+	Kind         string        `yaml:"kind"` // rss（同时支持Atom）或 html
+	URL          string        `yaml:"url"`
+	Interval     time.Duration `yaml:"interval"`
+	Category     string        `yaml:"category"`     // 采集的资源所属分类的value
+	ResourceType string        `yaml:"resourceType"` // 条目中没有类型时使用的资源类型
+	Tags         []string      `yaml:"tags"`         // 附加到采集资源的标签
+	Selectors    HTMLSelectors `yaml:"selectors"`    // kind为html时的页面解析规则
+}
+
+// HTMLSelectors 列表页的CSS选择器，item之外的选择器在每个条目内查找。
+// 选择器后加 @属性名 表示读取属性，如 a.title@href，否则读取文本
+type HTMLSelectors struct {
+	Item        string `yaml:"item"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Content     string `yaml:"content"` // 包含分享链接和提取码的部分，为空时使用整个条目
+	Size        string `yaml:"size"`
+	Type        string `yaml:"type"`
+	Tags        string `yaml:"tags"`
+	Date        string `yaml:"date"`
+	DateLayout  string `yaml:"dateLayout"` // Go时间格式，为空时尝试常见格式
 }
 
 // LogConfig 日志配置
@@ -57,6 +162,9 @@ type LogConfig struct {
 
 var GlobalConfig *Config
 
+// 示例配置中的默认JWT密钥，生产环境必须替换
+const defaultJWTSecret = "your-secret-key-change-in-production"
+
 // LoadConfig 加载配置
 func LoadConfig(configPath string) error {
 	// 从YAML文件加载配置
@@ -88,6 +196,9 @@ func loadFromEnv(config *Config) {
 	if mode := os.Getenv("APP_MODE"); mode != "" {
 		config.App.Mode = mode
 	}
+	if proxies := os.Getenv("APP_TRUSTED_PROXIES"); proxies != "" {
+		config.App.TrustedProxies = strings.Split(proxies, ",")
+	}
 
 	// 数据库配置
 	if host := os.Getenv("DB_HOST"); host != "" {
@@ -123,6 +234,22 @@ func loadFromEnv(config *Config) {
 			config.JWT.Expire = d
 		}
 	}
+	if refreshExpire := os.Getenv("JWT_REFRESH_EXPIRE"); refreshExpire != "" {
+		if d, err := time.ParseDuration(refreshExpire); err == nil {
+			config.JWT.RefreshExpire = d
+		}
+	}
+
+	// 搜索配置
+	if engine := os.Getenv("SEARCH_ENGINE"); engine != "" {
+		config.Search.Engine = engine
+	}
+	if indexPath := os.Getenv("SEARCH_INDEX_PATH"); indexPath != "" {
+		config.Search.IndexPath = indexPath
+	}
+	if userDict := os.Getenv("SEARCH_USER_DICT"); userDict != "" {
+		config.Search.UserDict = userDict
+	}
 
 	// 日志配置
 	if level := os.Getenv("LOG_LEVEL"); level != "" {
@@ -149,5 +276,11 @@ func Init() {
 	if err := LoadConfig("config/config.yaml"); err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	if GlobalConfig.JWT.Secret == "" {
+		log.Fatal("JWT secret must not be empty")
+	}
+	if GlobalConfig.App.Mode == "release" && GlobalConfig.JWT.Secret == defaultJWTSecret {
+		log.Println("WARNING: using the default JWT secret in release mode, set JWT_SECRET")
+	}
 	log.Println("Configuration loaded successfully")
 }
\ No newline at end of file
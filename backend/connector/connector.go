@@ -0,0 +1,113 @@
+// Package connector 从RSS/Atom订阅和网页列表等外部来源自动采集网盘分享资源。
+// 每个来源按 抓取 → 解析 → 识别分享链接 → 写入资源 的流程处理，游标和统计保存在connector_states表
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"pan-search-api/config"
+	"strings"
+	"time"
+)
+
+// 来源类型
+const (
+	KindRSS  = "rss"
+	KindHTML = "html"
+)
+
+// 单次抓取的最大响应大小
+const maxBodySize = 5 << 20
+
+// Item 来源中的一个条目
+type Item struct {
+	GUID        string // 条目的唯一标识，没有时为链接或标题
+	Title       string
+	Description string     // 纯文本描述
+	Content     string     // 包含分享链接和提取码的原始文本或HTML
+	Size        string     // 文件大小，来源中没有时为空
+	Type        string     // 资源类型，来源中没有时为空
+	Tags        []string   // 来源中的分类或标签
+	PublishedAt *time.Time // 发布时间，来源中没有时为空
+}
+
+// Connector 一个采集来源
+type Connector interface {
+	// Name 来源名称，与配置中的name一致
+	Name() string
+	// Fetch 抓取来源的原始内容
+	Fetch(ctx context.Context, client *http.Client) ([]byte, error)
+	// Parse 将抓取的内容解析为条目
+	Parse(data []byte) ([]Item, error)
+}
+
+// New 按来源配置创建采集器
+func New(src config.SourceConfig) (Connector, error) {
+	if src.Name == "" {
+		return nil, errors.New("connector name is required")
+	}
+	if src.URL == "" {
+		return nil, fmt.Errorf("connector %s: url is required", src.Name)
+	}
+
+	switch strings.ToLower(src.Kind) {
+	case KindRSS:
+		return &RSS{name: src.Name, url: src.URL}, nil
+	case KindHTML:
+		if src.Selectors.Item == "" || src.Selectors.Title == "" {
+			return nil, fmt.Errorf("connector %s: selectors.item and selectors.title are required", src.Name)
+		}
+		return &HTML{name: src.Name, url: src.URL, selectors: src.Selectors}, nil
+	default:
+		return nil, fmt.Errorf("connector %s: unsupported kind %q", src.Name, src.Kind)
+	}
+}
+
+// fetch 请求来源地址，返回响应内容和Content-Type
+func fetch(ctx context.Context, client *http.Client, url string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("fetch %s: unexpected status %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, "", err
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// 来源中常见的时间格式
+var dateLayouts = []string{
+	time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST",
+	"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02", "2006/01/02 15:04", "2006/01/02", "2006年1月2日",
+}
+
+// parseDate 解析时间，layout为空时依次尝试常见格式，无法解析时返回nil
+func parseDate(value, layout string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	layouts := dateLayouts
+	if layout != "" {
+		layouts = []string{layout}
+	}
+	for _, l := range layouts {
+		if t, err := time.ParseInLocation(l, value, time.Local); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
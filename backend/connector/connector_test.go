@@ -0,0 +1,362 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"pan-search-api/config"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// fixtureServer 提供testdata目录下的样例文件
+func fixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// collectItems 通过来源配置抓取并解析条目
+func collectItems(t *testing.T, src config.SourceConfig) []Item {
+	t.Helper()
+	c, err := New(src)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	data, err := c.Fetch(context.Background(), http.DefaultClient)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	items, err := c.Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return items
+}
+
+// shareSummary 条目中识别出的分享，格式为 key#提取码
+func shareSummary(item Item, src config.SourceConfig) []string {
+	var result []string
+	for _, s := range normalize(item, src, 1) {
+		result = append(result, s.key+"#"+s.input.ExtractCode)
+	}
+	return result
+}
+
+func TestRSSConnector(t *testing.T) {
+	srv := fixtureServer(t)
+	src := config.SourceConfig{Name: "feed", Kind: KindRSS, URL: srv.URL + "/feed.xml", ResourceType: "video", Tags: []string{"订阅"}}
+	items := collectItems(t, src)
+	if len(items) != 3 {
+		t.Fatalf("got %d items, want 3", len(items))
+	}
+
+	first := items[0]
+	if first.GUID != "post-3" || first.Title != "流浪地球2 4K HDR" {
+		t.Errorf("first item = %q %q", first.GUID, first.Title)
+	}
+	if first.Description != "郭帆导演作品。 链接: https://pan.baidu.com/s/1AbCdEfGh 提取码: x7k2" {
+		t.Errorf("description = %q", first.Description)
+	}
+	if want := time.Date(2024, 2, 3, 2, 0, 0, 0, time.UTC); first.PublishedAt == nil || !first.PublishedAt.Equal(want) {
+		t.Errorf("published = %v, want %v", first.PublishedAt, want)
+	}
+	if !reflect.DeepEqual(first.Tags, []string{"科幻", "4K"}) {
+		t.Errorf("tags = %v", first.Tags)
+	}
+	if got, want := shareSummary(first, src), []string{"baidu:1AbCdEfGh#x7k2", "aliyun:AbC123dEf45#"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("shares = %v, want %v", got, want)
+	}
+
+	if items[1].Title != "Photoshop 2024 & 插件合集" || items[1].GUID != "https://share.example.com/posts/2" {
+		t.Errorf("second item = %q %q", items[1].GUID, items[1].Title)
+	}
+	if got, want := shareSummary(items[1], src), []string{"quark:1a2b3c4d5e6f#"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("shares = %v, want %v", got, want)
+	}
+	if got := shareSummary(items[2], src); len(got) != 0 {
+		t.Errorf("item without share link produced %v", got)
+	}
+}
+
+func TestAtomConnector(t *testing.T) {
+	srv := fixtureServer(t)
+	src := config.SourceConfig{Name: "atom", Kind: KindRSS, URL: srv.URL + "/atom.xml"}
+	items := collectItems(t, src)
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+
+	if items[0].Title != "三体 全集" || items[0].GUID != "tag:share.example.com,2024:post-12" {
+		t.Errorf("first item = %q %q", items[0].GUID, items[0].Title)
+	}
+	// 优先使用published，没有时使用updated
+	if want := time.Date(2024, 2, 3, 2, 0, 0, 0, time.UTC); items[0].PublishedAt == nil || !items[0].PublishedAt.Equal(want) {
+		t.Errorf("published = %v, want %v", items[0].PublishedAt, want)
+	}
+	if want := time.Date(2024, 2, 2, 8, 0, 0, 0, time.UTC); items[1].PublishedAt == nil || !items[1].PublishedAt.Equal(want) {
+		t.Errorf("published = %v, want %v", items[1].PublishedAt, want)
+	}
+	if !reflect.DeepEqual(items[0].Tags, []string{"电视剧"}) {
+		t.Errorf("tags = %v", items[0].Tags)
+	}
+	if got, want := shareSummary(items[0], src), []string{"tianyi:AbCdEf123456#5x9d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("shares = %v, want %v", got, want)
+	}
+	if got, want := shareSummary(items[1], src), []string{"115:sw3abcd1234#q1w2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("shares = %v, want %v", got, want)
+	}
+	if items[1].Description != "115网盘 点击下载" {
+		t.Errorf("description = %q", items[1].Description)
+	}
+}
+
+// listSource 解析testdata/list.html的来源配置
+func listSource(url string) config.SourceConfig {
+	return config.SourceConfig{
+		Name:         "list",
+		Kind:         KindHTML,
+		URL:          url,
+		ResourceType: "software",
+		Selectors: config.HTMLSelectors{
+			Item:        "ul.list > li.post",
+			Title:       "h3",
+			Description: ".intro",
+			Content:     ".share",
+			Size:        ".size",
+			Tags:        ".tags a",
+			Date:        "time@datetime",
+		},
+	}
+}
+
+func TestHTMLConnector(t *testing.T) {
+	srv := fixtureServer(t)
+	src := listSource(srv.URL + "/list.html")
+	items := collectItems(t, src)
+	// 没有标题的条目被忽略，列表之外的链接不会被采集
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2", len(items))
+	}
+
+	first := items[0]
+	if first.Title != "Office 2021 专业增强版" || first.Description != "含激活工具， 解压即用" || first.Size != "4.2 GB" {
+		t.Errorf("first item = %+v", first)
+	}
+	if !reflect.DeepEqual(first.Tags, []string{"办公", "Windows"}) {
+		t.Errorf("tags = %v", first.Tags)
+	}
+	if want := time.Date(2024, 2, 3, 12, 0, 0, 0, time.Local); first.PublishedAt == nil || !first.PublishedAt.Equal(want) {
+		t.Errorf("published = %v, want %v", first.PublishedAt, want)
+	}
+	if got, want := shareSummary(first, src), []string{"123pan:abcD-EfGh#zx90"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("shares = %v, want %v", got, want)
+	}
+	if got, want := shareSummary(items[1], src), []string{"lanzou:iAbC123dEf#6g3h"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("shares = %v, want %v", got, want)
+	}
+}
+
+func TestHTMLConnectorCharset(t *testing.T) {
+	page := `<html><body><ul><li><h3>中文资源</h3><p>链接：https://pan.quark.cn/s/1a2b3c4d5e6f 提取码：ab12</p></li></ul></body></html>`
+	encoded, err := simplifiedchinese.GBK.NewEncoder().String(page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=gbk")
+		w.Write([]byte(encoded))
+	}))
+	defer srv.Close()
+
+	src := config.SourceConfig{
+		Name: "gbk", Kind: KindHTML, URL: srv.URL,
+		Selectors: config.HTMLSelectors{Item: "li", Title: "h3"},
+	}
+	items := collectItems(t, src)
+	if len(items) != 1 || items[0].Title != "中文资源" {
+		t.Fatalf("items = %+v", items)
+	}
+	if got, want := shareSummary(items[0], src), []string{"quark:1a2b3c4d5e6f#ab12"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("shares = %v, want %v", got, want)
+	}
+}
+
+func TestFetchErrorStatus(t *testing.T) {
+	srv := fixtureServer(t)
+	c, err := New(config.SourceConfig{Name: "missing", Kind: KindRSS, URL: srv.URL + "/missing.xml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Fetch(context.Background(), http.DefaultClient); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Fetch error = %v, want status 404", err)
+	}
+}
+
+func TestParseNotFeed(t *testing.T) {
+	c, err := New(config.SourceConfig{Name: "feed", Kind: KindRSS, URL: "http://127.0.0.1/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Parse([]byte(`<html><body>not a feed</body></html>`)); err == nil {
+		t.Error("Parse should reject a document that is not a feed")
+	}
+}
+
+func TestNewValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		src  config.SourceConfig
+	}{
+		{"missing name", config.SourceConfig{Kind: KindRSS, URL: "http://127.0.0.1/"}},
+		{"missing url", config.SourceConfig{Name: "a", Kind: KindRSS}},
+		{"unknown kind", config.SourceConfig{Name: "a", Kind: "json", URL: "http://127.0.0.1/"}},
+		{"html without selectors", config.SourceConfig{Name: "a", Kind: KindHTML, URL: "http://127.0.0.1/"}},
+	}
+	for _, tt := range tests {
+		if _, err := New(tt.src); err == nil {
+			t.Errorf("%s: New should fail", tt.name)
+		}
+	}
+
+	_, err := NewRunner(nil, config.ConnectorConfig{Sources: []config.SourceConfig{
+		{Name: "a", Kind: KindRSS, URL: "http://127.0.0.1/a"},
+		{Name: "a", Kind: KindRSS, URL: "http://127.0.0.1/b"},
+	}})
+	if err == nil {
+		t.Error("NewRunner should reject duplicate names")
+	}
+}
+
+func TestNewItems(t *testing.T) {
+	at := func(hour int) *time.Time {
+		t := time.Date(2024, 2, 1, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+	items := []Item{
+		{GUID: "new", PublishedAt: at(12)},
+		{GUID: "same second", PublishedAt: at(10)},
+		{GUID: "undated"},
+		{GUID: "old", PublishedAt: at(8)},
+	}
+
+	got, next := newItems(items, at(10), 10)
+	var guids []string
+	for _, item := range got {
+		guids = append(guids, item.GUID)
+	}
+	if want := []string{"new", "same second", "undated"}; !reflect.DeepEqual(guids, want) {
+		t.Errorf("items = %v, want %v", guids, want)
+	}
+	if !next.Equal(*at(12)) {
+		t.Errorf("cursor = %v, want %v", next, at(12))
+	}
+
+	// 第一次采集处理全部条目
+	if got, next := newItems(items, nil, 10); len(got) != len(items) || !next.Equal(*at(12)) {
+		t.Errorf("without cursor got %d items, cursor %v", len(got), next)
+	}
+	// 没有新条目时游标不变
+	if _, next := newItems(items[3:], at(10), 10); !next.Equal(*at(10)) {
+		t.Errorf("cursor moved to %v", next)
+	}
+}
+
+func TestNewItemsLimit(t *testing.T) {
+	at := func(hour int) *time.Time {
+		t := time.Date(2024, 2, 1, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+	tests := []struct {
+		name   string
+		items  []Item
+		cursor *time.Time
+		want   []string
+		next   *time.Time
+	}{
+		{
+			// 按时间正序的订阅，先过滤再截取，旧条目不会占用名额
+			name:   "oldest first",
+			items:  []Item{{GUID: "a", PublishedAt: at(1)}, {GUID: "b", PublishedAt: at(2)}, {GUID: "c", PublishedAt: at(11)}, {GUID: "d", PublishedAt: at(12)}},
+			cursor: at(10),
+			want:   []string{"c", "d"},
+			next:   at(12),
+		},
+		{
+			// 按时间倒序的订阅，保留最早的新条目，游标只前进到已处理的条目
+			name:   "newest first",
+			items:  []Item{{GUID: "d", PublishedAt: at(14)}, {GUID: "c", PublishedAt: at(13)}, {GUID: "b", PublishedAt: at(12)}, {GUID: "a", PublishedAt: at(1)}},
+			cursor: at(10),
+			want:   []string{"b", "c"},
+			next:   at(13),
+		},
+		{
+			name:   "undated last",
+			items:  []Item{{GUID: "x"}, {GUID: "b", PublishedAt: at(12)}, {GUID: "c", PublishedAt: at(13)}},
+			cursor: at(10),
+			want:   []string{"b", "c"},
+			next:   at(13),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, next := newItems(tt.items, tt.cursor, 2)
+			var guids []string
+			for _, item := range got {
+				guids = append(guids, item.GUID)
+			}
+			if !reflect.DeepEqual(guids, tt.want) {
+				t.Errorf("items = %v, want %v", guids, tt.want)
+			}
+			if !next.Equal(*tt.next) {
+				t.Errorf("cursor = %v, want %v", next, tt.next)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	published := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	src := config.SourceConfig{ResourceType: "video", Tags: []string{"订阅"}}
+	item := Item{
+		Title:       strings.Repeat("长", 300),
+		Description: "简介",
+		Content:     "https://pan.baidu.com/s/1AbCdEfGh?pwd=abcd",
+		Tags:        []string{"科幻"},
+		PublishedAt: &published,
+	}
+
+	shares := normalize(item, src, 7)
+	if len(shares) != 1 {
+		t.Fatalf("got %d shares, want 1", len(shares))
+	}
+	input := shares[0].input
+	if len([]rune(input.Title)) != maxTitleLength {
+		t.Errorf("title length = %d, want %d", len([]rune(input.Title)), maxTitleLength)
+	}
+	if input.Source != "baidu" || input.DownloadURL != "https://pan.baidu.com/s/1AbCdEfGh" || input.ExtractCode != "abcd" {
+		t.Errorf("share fields = %q %q %q", input.Source, input.DownloadURL, input.ExtractCode)
+	}
+	if input.Size != unknownSize || input.Type != "video" || input.CategoryID != 7 || input.UploadTime != &published {
+		t.Errorf("input = %+v", input)
+	}
+	if !reflect.DeepEqual(input.Tags, []string{"订阅", "科幻"}) {
+		t.Errorf("tags = %v", input.Tags)
+	}
+
+	// 条目中的类型优先于来源配置，两者都没有时为other
+	item.Type = "iso"
+	if got := normalize(item, src, 7)[0].input.Type; got != "iso" {
+		t.Errorf("type = %q, want iso", got)
+	}
+	item.Type = ""
+	if got := normalize(item, config.SourceConfig{}, 7)[0].input.Type; got != unknownType {
+		t.Errorf("type = %q, want %q", got, unknownType)
+	}
+}
@@ -0,0 +1,124 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"pan-search-api/config"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/charset"
+)
+
+// HTML 按CSS选择器从网页列表中采集条目
+type HTML struct {
+	name      string
+	url       string
+	selectors config.HTMLSelectors
+}
+
+// Name 来源名称
+func (h *HTML) Name() string {
+	return h.name
+}
+
+// Fetch 抓取页面并按Content-Type或meta声明的编码转换为UTF-8
+func (h *HTML) Fetch(ctx context.Context, client *http.Client) ([]byte, error) {
+	data, contentType, err := fetch(ctx, client, h.url)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := charset.NewReader(bytes.NewReader(data), contentType)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(reader)
+}
+
+// Parse 解析页面中匹配selectors.item的条目，没有标题的条目被忽略
+func (h *HTML) Parse(data []byte) ([]Item, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	sel := h.selectors
+	var items []Item
+	doc.Find(sel.Item).Each(func(_ int, node *goquery.Selection) {
+		title := selectText(node, sel.Title)
+		if title == "" {
+			return
+		}
+
+		content := node
+		if sel.Content != "" {
+			content = node.Find(sel.Content)
+		}
+		var parts []string
+		content.Each(func(_ int, s *goquery.Selection) {
+			if html, err := goquery.OuterHtml(s); err == nil {
+				parts = append(parts, html)
+			}
+		})
+
+		var tags []string
+		if sel.Tags != "" {
+			node.Find(sel.Tags).Each(func(_ int, s *goquery.Selection) {
+				if tag := collapseSpace(s.Text()); tag != "" {
+					tags = append(tags, tag)
+				}
+			})
+		}
+
+		item := Item{
+			Title:       title,
+			Description: selectText(node, sel.Description),
+			Content:     joinContent(parts...),
+			Size:        selectText(node, sel.Size),
+			Type:        selectText(node, sel.Type),
+			Tags:        tags,
+			PublishedAt: parseDate(selectText(node, sel.Date), sel.DateLayout),
+		}
+		item.GUID = item.Title
+		items = append(items, item)
+	})
+	return items, nil
+}
+
+// selectText 读取条目中第一个匹配元素的文本，选择器以 @属性名 结尾时读取属性
+func selectText(node *goquery.Selection, selector string) string {
+	if selector == "" {
+		return ""
+	}
+	selector, attr, hasAttr := strings.Cut(selector, "@")
+	target := node
+	if selector = strings.TrimSpace(selector); selector != "" {
+		target = node.Find(selector).First()
+	}
+	if hasAttr {
+		value, _ := target.Attr(strings.TrimSpace(attr))
+		return strings.TrimSpace(value)
+	}
+	return collapseSpace(target.Text())
+}
+
+// htmlText 提取HTML片段中的纯文本
+func htmlText(fragment string) string {
+	if !strings.Contains(fragment, "<") {
+		return collapseSpace(fragment)
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(fragment))
+	if err != nil {
+		return collapseSpace(fragment)
+	}
+	// 换行标签转为空格，避免相邻两行的文字粘连
+	doc.Find("br").ReplaceWithHtml(" ")
+	return collapseSpace(doc.Text())
+}
+
+// collapseSpace 合并连续的空白字符
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,176 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// RSS 采集RSS 2.0、RSS 1.0和Atom订阅，分享链接可以在条目的链接、描述或正文中
+type RSS struct {
+	name string
+	url  string
+}
+
+// feed 兼容RSS和Atom的订阅结构，按根元素区分
+type feed struct {
+	XMLName xml.Name
+	Channel struct {
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items   []rssItem   `xml:"item"` // RSS 1.0的条目在根元素下
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	GUID        string   `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Categories  []string `xml:"category"`
+}
+
+type atomEntry struct {
+	ID    string `xml:"id"`
+	Title string `xml:"title"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Summary    atomText `xml:"summary"`
+	Content    atomText `xml:"content"`
+	Published  string   `xml:"published"`
+	Updated    string   `xml:"updated"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+}
+
+// atomText Atom的文本元素，type为xhtml时内容是子元素而不是转义的HTML
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t atomText) String() string {
+	if t.Type == "xhtml" {
+		return t.Inner
+	}
+	return t.Text
+}
+
+// Name 来源名称
+func (r *RSS) Name() string {
+	return r.name
+}
+
+// Fetch 抓取订阅，编码由XML声明决定
+func (r *RSS) Fetch(ctx context.Context, client *http.Client) ([]byte, error) {
+	data, _, err := fetch(ctx, client, r.url)
+	return data, err
+}
+
+// Parse 解析订阅中的条目
+func (r *RSS) Parse(data []byte) ([]Item, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	// 订阅中常见未转义的&等字符
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+
+	var f feed
+	if err := decoder.Decode(&f); err != nil {
+		return nil, err
+	}
+
+	switch f.XMLName.Local {
+	case "rss":
+		return rssItems(f.Channel.Items), nil
+	case "RDF":
+		return rssItems(f.Items), nil
+	case "feed":
+		return atomItems(f.Entries), nil
+	default:
+		return nil, errors.New("not an RSS or Atom feed")
+	}
+}
+
+func rssItems(entries []rssItem) []Item {
+	items := make([]Item, 0, len(entries))
+	for _, entry := range entries {
+		published := parseDate(entry.PubDate, "")
+		if published == nil {
+			published = parseDate(entry.Date, "")
+		}
+		link := strings.TrimSpace(entry.Link)
+		items = append(items, Item{
+			GUID:        firstNonEmpty(entry.GUID, link, entry.Title),
+			Title:       strings.TrimSpace(entry.Title),
+			Description: htmlText(entry.Description),
+			Content:     joinContent(link, entry.Description, entry.Content),
+			Tags:        entry.Categories,
+			PublishedAt: published,
+		})
+	}
+	return items
+}
+
+func atomItems(entries []atomEntry) []Item {
+	items := make([]Item, 0, len(entries))
+	for _, entry := range entries {
+		published := parseDate(entry.Published, "")
+		if published == nil {
+			published = parseDate(entry.Updated, "")
+		}
+		var link string
+		for _, l := range entry.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				link = strings.TrimSpace(l.Href)
+				break
+			}
+		}
+		var tags []string
+		for _, category := range entry.Categories {
+			tags = append(tags, category.Term)
+		}
+		summary := firstNonEmpty(entry.Summary.String(), entry.Content.String())
+		items = append(items, Item{
+			GUID:        firstNonEmpty(entry.ID, link, entry.Title),
+			Title:       strings.TrimSpace(entry.Title),
+			Description: htmlText(summary),
+			Content:     joinContent(link, entry.Summary.String(), entry.Content.String()),
+			Tags:        tags,
+			PublishedAt: published,
+		})
+	}
+	return items
+}
+
+// joinContent 拼接可能包含分享链接的各部分，用换行分隔避免相邻的链接粘连
+func joinContent(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, "\n")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,471 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"pan-search-api/common"
+	"pan-search-api/config"
+	"pan-search-api/models"
+	"pan-search-api/services"
+	"pan-search-api/shareurl"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 采集默认配置
+const (
+	defaultInterval  = 30 * time.Minute
+	defaultTimeout   = 30 * time.Second
+	defaultMaxItems  = 200
+	defaultUserAgent = "Mozilla/5.0 (compatible; pan-search-connector/1.0)"
+)
+
+// 检查是否有到期来源的间隔
+const tickInterval = time.Minute
+
+// 来源中没有大小和类型时使用的值
+const (
+	unknownSize = "未知"
+	unknownType = "other"
+)
+
+// 写入资源的字段长度，与数据库列宽一致
+const (
+	maxTitleLength       = 255
+	maxDescriptionLength = 2000
+	maxSizeLength        = 20
+	maxTypeLength        = 50
+	maxErrorLength       = 500
+)
+
+// ErrConnectorNotFound 来源不存在
+var ErrConnectorNotFound = errors.New("采集来源不存在")
+
+// Stats 一次采集的统计
+type Stats struct {
+	Fetched int `json:"fetched"` // 游标之后的条目数
+	Created int `json:"created"` // 新建的资源数
+	Updated int `json:"updated"` // 提取码变化而更新的资源数
+	Skipped int `json:"skipped"` // 没有可识别分享链接的条目数和已收录的分享数
+	Failed  int `json:"failed"`  // 写入失败的分享数
+}
+
+// Status 来源的配置和采集状态
+type Status struct {
+	Name     string                 `json:"name"`
+	Kind     string                 `json:"kind"`
+	URL      string                 `json:"url"`
+	Interval string                 `json:"interval"`
+	Category string                 `json:"category"`
+	State    *models.ConnectorState `json:"state"` // 从未采集时为空
+}
+
+// source 一个已配置的来源
+type source struct {
+	connector Connector
+	cfg       config.SourceConfig
+}
+
+// share 条目中识别出的一个分享
+type share struct {
+	key   string
+	input models.ResourceCreate
+}
+
+// Runner 按间隔调度各来源的采集，同一时间只运行一个来源
+type Runner struct {
+	db        *gorm.DB
+	cfg       config.ConnectorConfig
+	client    *http.Client
+	sources   []*source
+	mu        sync.Mutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup // 定时采集的goroutine
+}
+
+var (
+	runnerMu      sync.RWMutex
+	defaultRunner *Runner
+)
+
+// Start 创建采集调度器并设为默认实例，配置启用时在后台定时采集。
+// 未启用时仍可以通过Run手动采集
+func Start(db *gorm.DB, cfg config.ConnectorConfig) (*Runner, error) {
+	r, err := NewRunner(db, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Enabled {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			r.loop()
+		}()
+	}
+
+	runnerMu.Lock()
+	defaultRunner = r
+	runnerMu.Unlock()
+	return r, nil
+}
+
+// Default 返回默认实例，未启动时返回nil
+func Default() *Runner {
+	runnerMu.RLock()
+	defer runnerMu.RUnlock()
+	return defaultRunner
+}
+
+// NewRunner 创建采集调度器，不启动定时采集。来源配置错误或名称重复时返回错误
+func NewRunner(db *gorm.DB, cfg config.ConnectorConfig) (*Runner, error) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.MaxItems <= 0 {
+		cfg.MaxItems = defaultMaxItems
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = defaultUserAgent
+	}
+
+	names := make(map[string]bool, len(cfg.Sources))
+	sources := make([]*source, 0, len(cfg.Sources))
+	for _, src := range cfg.Sources {
+		if names[src.Name] {
+			return nil, fmt.Errorf("duplicate connector name %q", src.Name)
+		}
+		names[src.Name] = true
+
+		connector, err := New(src)
+		if err != nil {
+			return nil, err
+		}
+		if src.Interval <= 0 {
+			src.Interval = cfg.Interval
+		}
+		sources = append(sources, &source{connector: connector, cfg: src})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner{
+		db:  db,
+		cfg: cfg,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: &common.UserAgentTransport{UserAgent: cfg.UserAgent},
+		},
+		sources: sources,
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// Stop 停止定时采集并取消正在进行的采集，等待采集结果写入完成后返回
+func (r *Runner) Stop() {
+	r.closeOnce.Do(func() {
+		r.cancel()
+		close(r.done)
+	})
+	r.wg.Wait()
+}
+
+// loop 立即采集到期的来源，之后每分钟检查一次
+func (r *Runner) loop() {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		r.runDue()
+		select {
+		case <-ticker.C:
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// runDue 依次采集距上次采集已超过间隔的来源
+func (r *Runner) runDue() {
+	for _, src := range r.sources {
+		if r.ctx.Err() != nil {
+			return
+		}
+
+		state, err := r.loadState(src.cfg.Name)
+		if err != nil {
+			log.Printf("Failed to load connector state %s: %v", src.cfg.Name, err)
+			continue
+		}
+		if state.LastRunAt != nil && time.Since(*state.LastRunAt) < src.cfg.Interval {
+			continue
+		}
+
+		stats, err := r.run(r.ctx, src)
+		if err != nil {
+			log.Printf("Connector %s failed: %v", src.cfg.Name, err)
+			continue
+		}
+		if stats.Created > 0 || stats.Updated > 0 || stats.Failed > 0 {
+			log.Printf("Connector %s: %d created, %d updated, %d skipped, %d failed",
+				src.cfg.Name, stats.Created, stats.Updated, stats.Skipped, stats.Failed)
+		}
+	}
+}
+
+// Run 立即采集指定来源
+func (r *Runner) Run(ctx context.Context, name string) (*Stats, error) {
+	for _, src := range r.sources {
+		if src.cfg.Name == name {
+			return r.run(ctx, src)
+		}
+	}
+	return nil, ErrConnectorNotFound
+}
+
+// Statuses 返回全部来源的配置和采集状态
+func (r *Runner) Statuses() ([]Status, error) {
+	var states []models.ConnectorState
+	if err := r.db.Find(&states).Error; err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*models.ConnectorState, len(states))
+	for i := range states {
+		byName[states[i].Name] = &states[i]
+	}
+
+	statuses := make([]Status, 0, len(r.sources))
+	for _, src := range r.sources {
+		statuses = append(statuses, Status{
+			Name:     src.cfg.Name,
+			Kind:     src.cfg.Kind,
+			URL:      src.cfg.URL,
+			Interval: src.cfg.Interval.String(),
+			Category: src.cfg.Category,
+			State:    byName[src.cfg.Name],
+		})
+	}
+	return statuses, nil
+}
+
+// run 抓取并解析来源，将游标之后的新条目写入资源，保存游标和统计。
+// 有分享写入失败时不推进游标，下次采集时重试
+func (r *Runner) run(ctx context.Context, src *source) (*Stats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, err := r.loadState(src.cfg.Name)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	state.LastRunAt = &now
+	state.Runs++
+
+	stats, cursor, err := r.collect(ctx, src, parseCursor(state.Cursor))
+	if err != nil {
+		state.FailedRuns++
+		state.LastError = common.Truncate(err.Error(), maxErrorLength)
+		if saveErr := r.saveState(state); saveErr != nil {
+			log.Printf("Failed to save connector state %s: %v", src.cfg.Name, saveErr)
+		}
+		return nil, err
+	}
+
+	if stats.Failed == 0 && cursor != nil {
+		state.Cursor = cursor.Format(time.RFC3339)
+	}
+	state.LastSuccessAt = &now
+	state.LastError = ""
+	state.LastFetched = stats.Fetched
+	state.LastCreated = stats.Created
+	state.LastUpdated = stats.Updated
+	state.LastSkipped = stats.Skipped
+	state.LastFailed = stats.Failed
+	state.TotalCreated += stats.Created
+	state.TotalUpdated += stats.Updated
+	return stats, r.saveState(state)
+}
+
+// collect 执行一次采集，返回统计和新的游标
+func (r *Runner) collect(ctx context.Context, src *source, cursor *time.Time) (*Stats, *time.Time, error) {
+	var category models.Category
+	if err := r.db.Select("id").Where("value = ?", src.cfg.Category).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("%w: %s", services.ErrCategoryNotFound, src.cfg.Category)
+		}
+		return nil, nil, err
+	}
+
+	data, err := src.connector.Fetch(ctx, r.client)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := src.connector.Parse(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse %s: %w", src.cfg.URL, err)
+	}
+
+	stats := &Stats{}
+	items, next := newItems(items, cursor, r.cfg.MaxItems)
+	for _, item := range items {
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		stats.Fetched++
+
+		shares := normalize(item, src.cfg, category.ID)
+		if len(shares) == 0 {
+			stats.Skipped++
+			continue
+		}
+		for _, s := range shares {
+			created, updated, err := upsert(r.db, s)
+			switch {
+			case err != nil:
+				stats.Failed++
+				log.Printf("Connector %s: failed to save %s (%s): %v", src.cfg.Name, s.key, item.GUID, err)
+			case created:
+				stats.Created++
+			case updated:
+				stats.Updated++
+			default:
+				stats.Skipped++
+			}
+		}
+	}
+	return stats, next, nil
+}
+
+// newItems 过滤掉发布时间早于游标的条目。与游标同一秒发布的条目可能上次没有采集到，
+// 和没有发布时间的条目一样保留，依靠分享去重。新条目超过limit条时只保留发布最早的limit条，
+// 其余的留到下次采集。返回保留的条目和其中最新的发布时间，没有更新的发布时间时返回原游标
+func newItems(items []Item, cursor *time.Time, limit int) ([]Item, *time.Time) {
+	result := make([]Item, 0, len(items))
+	for _, item := range items {
+		if item.PublishedAt != nil && cursor != nil && item.PublishedAt.Before(*cursor) {
+			continue
+		}
+		result = append(result, item)
+	}
+
+	if len(result) > limit {
+		// 没有发布时间的条目排在最后
+		sort.SliceStable(result, func(i, j int) bool {
+			pi, pj := result[i].PublishedAt, result[j].PublishedAt
+			if pi == nil || pj == nil {
+				return pi != nil && pj == nil
+			}
+			return pi.Before(*pj)
+		})
+		result = result[:limit]
+	}
+
+	next := cursor
+	for _, item := range result {
+		if item.PublishedAt != nil && (next == nil || item.PublishedAt.After(*next)) {
+			next = item.PublishedAt
+		}
+	}
+	return result, next
+}
+
+// normalize 识别条目中的分享链接，每个分享生成一个资源，标题、描述等字段相同
+func normalize(item Item, src config.SourceConfig, categoryID uint) []share {
+	title := common.Truncate(item.Title, maxTitleLength)
+	if title == "" {
+		return nil
+	}
+
+	size := item.Size
+	if size == "" {
+		size = unknownSize
+	}
+	resourceType := item.Type
+	if resourceType == "" {
+		resourceType = src.ResourceType
+	}
+	if resourceType == "" {
+		resourceType = unknownType
+	}
+	tags := append(append([]string{}, src.Tags...), item.Tags...)
+
+	var shares []share
+	for _, s := range shareurl.ParseAll(item.Content) {
+		shares = append(shares, share{
+			key: s.Key(),
+			input: models.ResourceCreate{
+				Title:       title,
+				Description: common.Truncate(item.Description, maxDescriptionLength),
+				Size:        common.Truncate(size, maxSizeLength),
+				Type:        common.Truncate(resourceType, maxTypeLength),
+				CategoryID:  categoryID,
+				Source:      s.Provider,
+				DownloadURL: s.URL,
+				ExtractCode: s.ExtractCode,
+				UploadTime:  item.PublishedAt,
+				Tags:        tags,
+			},
+		})
+	}
+	return shares
+}
+
+// upsert 按分享去重写入资源。已收录的分享只在提取码变化时更新提取码，
+// 不覆盖管理员修改过的其他字段；已删除的资源不会被重新采集
+func upsert(db *gorm.DB, s share) (created, updated bool, err error) {
+	var existing models.Resource
+	err = db.Unscoped().Select("id", "extract_code", "deleted_at").
+		Where("share_key = ?", s.key).
+		First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		_, err = services.CreateResource(db, s.input)
+		return err == nil, false, err
+	}
+	if err != nil || existing.DeletedAt.Valid {
+		return false, false, err
+	}
+
+	if s.input.ExtractCode == "" || s.input.ExtractCode == existing.ExtractCode {
+		return false, false, nil
+	}
+	_, err = services.UpdateResource(db, existing.ID, models.ResourceUpdate{ExtractCode: &s.input.ExtractCode})
+	return false, err == nil, err
+}
+
+// loadState 读取来源的采集状态，从未采集时返回新的状态
+func (r *Runner) loadState(name string) (*models.ConnectorState, error) {
+	var state models.ConnectorState
+	err := r.db.Where("name = ?", name).First(&state).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.ConnectorState{Name: name}, nil
+	}
+	return &state, err
+}
+
+// saveState 保存来源的采集状态
+func (r *Runner) saveState(state *models.ConnectorState) error {
+	return r.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(state).Error
+}
+
+// parseCursor 解析保存的游标，为空或格式错误时返回nil
+func parseCursor(cursor string) *time.Time {
+	t, err := time.Parse(time.RFC3339, cursor)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
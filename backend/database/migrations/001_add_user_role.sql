@@ -0,0 +1,7 @@
+-- Add role column to users table
+-- Apply to databases created from an earlier pan_search_minimal.sql
+
+USE `pan_search`;
+
+ALTER TABLE `users`
+  ADD COLUMN `role` VARCHAR(20) NOT NULL DEFAULT 'user' COMMENT 'User role' AFTER `avatar`;
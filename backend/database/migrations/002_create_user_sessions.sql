@@ -0,0 +1,20 @@
+-- Create user sessions table for refresh token rotation and revocation
+
+USE `pan_search`;
+
+CREATE TABLE `user_sessions` (
+  `id` VARCHAR(32) NOT NULL COMMENT 'Session ID',
+  `user_id` VARCHAR(32) NOT NULL COMMENT 'User ID',
+  `refresh_token_hash` VARCHAR(64) NOT NULL COMMENT 'Current refresh token hash',
+  `previous_token_hash` VARCHAR(64) COMMENT 'Rotated refresh token hash, used for reuse detection',
+  `user_agent` TEXT COMMENT 'User agent',
+  `ip_address` VARCHAR(45) COMMENT 'IP address',
+  `expires_at` DATETIME NOT NULL COMMENT 'Refresh token expire time',
+  `revoked_at` DATETIME COMMENT 'Revoked time',
+  `last_used_at` DATETIME NOT NULL COMMENT 'Last used time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='User sessions table';
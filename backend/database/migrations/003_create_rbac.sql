@@ -0,0 +1,64 @@
+-- Create role based access control tables and built-in roles
+
+USE `pan_search`;
+
+CREATE TABLE `roles` (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Role ID',
+  `name` VARCHAR(20) NOT NULL COMMENT 'Role name, referenced by users.role',
+  `label` VARCHAR(50) NOT NULL COMMENT 'Role label',
+  `description` VARCHAR(255) COMMENT 'Description',
+  `is_system` TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Is built-in role',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Roles table';
+
+CREATE TABLE `permissions` (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Permission ID',
+  `code` VARCHAR(50) NOT NULL COMMENT 'Permission code',
+  `description` VARCHAR(255) COMMENT 'Description',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_code` (`code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Permissions table';
+
+CREATE TABLE `role_permissions` (
+  `role_id` INT UNSIGNED NOT NULL COMMENT 'Role ID',
+  `permission_id` INT UNSIGNED NOT NULL COMMENT 'Permission ID',
+  PRIMARY KEY (`role_id`, `permission_id`),
+  KEY `idx_permission_id` (`permission_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Role permissions table';
+
+-- Insert roles and permissions
+INSERT INTO `roles` (`name`, `label`, `description`, `is_system`) VALUES
+('admin', '管理员', '拥有全部权限', 1),
+('moderator', '审核员', '处理求助请求和用户管理', 1),
+('editor', '编辑', '维护资源和分类', 1),
+('user', '普通用户', '注册用户默认角色', 1);
+
+INSERT INTO `permissions` (`code`, `description`) VALUES
+('*', '全部权限'),
+('admin:access', '访问管理接口'),
+('resource:write', '创建、编辑、下架资源'),
+('category:write', '管理分类'),
+('help_request:read', '查看求助请求'),
+('help_request:process', '处理求助请求'),
+('user:manage', '管理用户'),
+('role:manage', '管理角色权限'),
+('config:read', '查看系统配置'),
+('config:write', '修改系统配置');
+
+INSERT INTO `role_permissions` (`role_id`, `permission_id`)
+SELECT r.id, p.id FROM `roles` r JOIN `permissions` p
+WHERE (r.name, p.code) IN (
+  ('admin', '*'),
+  ('moderator', 'admin:access'),
+  ('moderator', 'help_request:read'),
+  ('moderator', 'help_request:process'),
+  ('moderator', 'user:manage'),
+  ('editor', 'admin:access'),
+  ('editor', 'resource:write'),
+  ('editor', 'category:write'),
+  ('editor', 'help_request:read')
+);
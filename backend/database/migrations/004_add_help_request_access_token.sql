@@ -0,0 +1,6 @@
+-- Add status lookup token to help requests
+
+USE `pan_search`;
+
+ALTER TABLE `help_requests`
+  ADD COLUMN `access_token_hash` VARCHAR(64) COMMENT 'Status lookup token hash' AFTER `admin_notes`;
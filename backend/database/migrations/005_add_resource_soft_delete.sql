@@ -0,0 +1,7 @@
+-- Add soft delete support to resources
+
+USE `pan_search`;
+
+ALTER TABLE `resources`
+  ADD COLUMN `deleted_at` DATETIME COMMENT 'Soft deleted time' AFTER `updated_at`,
+  ADD KEY `idx_deleted_at` (`deleted_at`);
@@ -0,0 +1,6 @@
+-- Add data export permission
+
+USE `pan_search`;
+
+INSERT INTO `permissions` (`code`, `description`) VALUES
+('data:export', '导出数据');
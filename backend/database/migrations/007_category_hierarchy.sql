@@ -0,0 +1,15 @@
+-- Support nested categories and drop the stored "all" pseudo-category
+
+USE `pan_search`;
+
+ALTER TABLE `categories`
+  ADD COLUMN `parent_id` INT UNSIGNED COMMENT 'Parent category ID, NULL for top level' AFTER `icon`,
+  ADD KEY `idx_parent_id` (`parent_id`);
+
+-- Resources filed under "all" are moved to "other" before the row is removed
+UPDATE `resources` r
+JOIN `categories` a ON a.id = r.category_id AND a.value = 'all'
+JOIN `categories` o ON o.value = 'other'
+SET r.category_id = o.id;
+
+DELETE FROM `categories` WHERE `value` = 'all';
@@ -0,0 +1,8 @@
+-- Rebuild the title/description FULLTEXT index with the ngram parser so
+-- Chinese text is indexed as bigrams instead of whitespace-separated words
+
+USE `pan_search`;
+
+ALTER TABLE `resources`
+  DROP INDEX `ft_title_description`,
+  ADD FULLTEXT KEY `ft_title_description` (`title`, `description`) WITH PARSER ngram;
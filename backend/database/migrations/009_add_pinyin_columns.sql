@@ -0,0 +1,12 @@
+-- Precomputed pinyin for title and tag matching ("lldq" / "liulangdiqiu" -> 流浪地球)
+-- Existing rows are filled by running: pan-search-api backfill
+
+USE `pan_search`;
+
+ALTER TABLE `resources`
+  ADD COLUMN `title_pinyin` VARCHAR(1600) NOT NULL DEFAULT '' COMMENT 'Title full pinyin' AFTER `title`,
+  ADD COLUMN `title_initials` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Title pinyin initials' AFTER `title_pinyin`;
+
+ALTER TABLE `resource_tags`
+  ADD COLUMN `pinyin` VARCHAR(300) NOT NULL DEFAULT '' COMMENT 'Tag full pinyin' AFTER `tag_name`,
+  ADD COLUMN `initials` VARCHAR(50) NOT NULL DEFAULT '' COMMENT 'Tag pinyin initials' AFTER `pinyin`;
@@ -0,0 +1,22 @@
+-- Store the file size as a byte count so it can be sorted and filtered numerically.
+-- Existing values like "4.5GB", "700 MB", "1.2G" and "1,024KB" are parsed below
+-- (requires MySQL 8.0 for REGEXP_SUBSTR); rows that cannot be parsed stay NULL.
+-- On MySQL 5.7 skip the UPDATE and run: pan-search-api backfill
+
+USE `pan_search`;
+
+ALTER TABLE `resources`
+  ADD COLUMN `size_bytes` BIGINT UNSIGNED COMMENT 'File size in bytes parsed from size, NULL if unknown' AFTER `size`,
+  ADD KEY `idx_size_bytes` (`size_bytes`);
+
+UPDATE `resources`
+SET `size_bytes` = ROUND(
+  CAST(REGEXP_SUBSTR(REPLACE(`size`, ',', ''), '[0-9]+(\\.[0-9]+)?') AS DECIMAL(24,4)) *
+  CASE
+    WHEN UPPER(TRIM(`size`)) REGEXP 'TI?B?$' THEN 1099511627776
+    WHEN UPPER(TRIM(`size`)) REGEXP 'GI?B?$' THEN 1073741824
+    WHEN UPPER(TRIM(`size`)) REGEXP 'MI?B?$' THEN 1048576
+    WHEN UPPER(TRIM(`size`)) REGEXP 'KI?B?$' THEN 1024
+    ELSE 1
+  END)
+WHERE REPLACE(UPPER(TRIM(`size`)), ',', '') REGEXP '^[0-9]+(\\.[0-9]+)?[[:space:]]*([KMGT]I?B?|B)?$';
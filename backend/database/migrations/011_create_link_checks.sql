@@ -0,0 +1,24 @@
+-- Track share-link health: per-resource check state used for scheduling,
+-- and a history table with the result of each check.
+
+USE `pan_search`;
+
+ALTER TABLE `resources`
+  ADD COLUMN `link_status` VARCHAR(20) NOT NULL DEFAULT '' COMMENT 'Last link check result: alive/dead/unknown, empty if never checked' AFTER `valid`,
+  ADD COLUMN `check_failures` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Consecutive dead link checks' AFTER `link_status`,
+  ADD COLUMN `last_checked_at` DATETIME COMMENT 'Last link check time' AFTER `check_failures`,
+  ADD COLUMN `next_check_at` DATETIME COMMENT 'Next scheduled link check time, NULL if never checked' AFTER `last_checked_at`,
+  ADD KEY `idx_next_check_at` (`next_check_at`);
+
+CREATE TABLE `link_checks` (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Check ID',
+  `resource_id` VARCHAR(32) NOT NULL COMMENT 'Resource ID',
+  `provider` VARCHAR(20) NOT NULL COMMENT 'Probe used for the check',
+  `status` VARCHAR(20) NOT NULL COMMENT 'alive/dead/unknown',
+  `http_status` INT NOT NULL DEFAULT 0 COMMENT 'HTTP status code, 0 if the request failed',
+  `detail` VARCHAR(255) COMMENT 'Check detail',
+  `duration_ms` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Check duration in milliseconds',
+  `checked_at` DATETIME NOT NULL COMMENT 'Check time',
+  PRIMARY KEY (`id`),
+  KEY `idx_resource_id` (`resource_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Link checks table';
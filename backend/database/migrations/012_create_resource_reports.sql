@@ -0,0 +1,22 @@
+-- User reports of broken links, wrong extract codes, wrong categories and illegal content.
+
+USE `pan_search`;
+
+CREATE TABLE `resource_reports` (
+  `id` VARCHAR(32) NOT NULL COMMENT 'Report ID',
+  `resource_id` VARCHAR(32) NOT NULL COMMENT 'Resource ID',
+  `reason` VARCHAR(20) NOT NULL COMMENT 'dead_link/wrong_code/wrong_category/illegal/other',
+  `description` VARCHAR(500) COMMENT 'Report description',
+  `user_id` VARCHAR(32) NOT NULL DEFAULT '' COMMENT 'Reporter user ID, empty for anonymous reports',
+  `ip_address` VARCHAR(45) NOT NULL COMMENT 'Reporter IP address',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT 'pending/resolved/dismissed',
+  `admin_notes` VARCHAR(500) COMMENT 'Admin notes',
+  `resolved_by` VARCHAR(32) COMMENT 'Admin user ID that handled the report',
+  `resolved_at` DATETIME COMMENT 'Resolved time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  PRIMARY KEY (`id`),
+  KEY `idx_resource_status` (`resource_id`, `status`),
+  KEY `idx_status_created_at` (`status`, `created_at`),
+  KEY `idx_ip_created_at` (`ip_address`, `created_at`),
+  KEY `idx_user_created_at` (`user_id`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Resource reports table';
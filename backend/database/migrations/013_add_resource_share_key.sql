@@ -0,0 +1,9 @@
+-- Deduplicate cloud-drive shares by provider and share ID instead of the raw download URL,
+-- so the same share pasted in different URL forms is only stored once.
+-- Existing rows get their share_key after running: pan-search-api backfill
+
+USE `pan_search`;
+
+ALTER TABLE `resources`
+  ADD COLUMN `share_key` VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'Share dedup key provider:shareId, empty if the URL is not recognized' AFTER `download_url`,
+  ADD KEY `idx_share_key` (`share_key`);
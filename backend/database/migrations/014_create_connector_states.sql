@@ -0,0 +1,22 @@
+-- Cursors and run stats of the automated resource connectors, one row per configured source.
+
+USE `pan_search`;
+
+CREATE TABLE `connector_states` (
+  `name` VARCHAR(100) NOT NULL COMMENT 'Connector name from config',
+  `cursor` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Publish time of the newest collected item',
+  `last_run_at` DATETIME COMMENT 'Last run time',
+  `last_success_at` DATETIME COMMENT 'Last successful run time',
+  `last_error` VARCHAR(500) NOT NULL DEFAULT '' COMMENT 'Error of the last run, empty if it succeeded',
+  `last_fetched` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Items after the cursor in the last run',
+  `last_created` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Resources created in the last run',
+  `last_updated` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Resources updated in the last run',
+  `last_skipped` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Items or shares skipped in the last run',
+  `last_failed` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Shares that failed to save in the last run',
+  `runs` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Total runs',
+  `failed_runs` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Runs that failed to fetch or parse',
+  `total_created` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Total resources created',
+  `total_updated` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Total resources updated',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Connector cursors and stats table';
@@ -0,0 +1,8 @@
+-- Record why a resource was marked invalid, so that moving the expire time of a resource
+-- invalidated only by the expiry sweeper back into the future can make it valid again.
+-- Resources swept before this migration are not marked and need valid: true explicitly.
+
+USE `pan_search`;
+
+ALTER TABLE `resources`
+  ADD COLUMN `invalid_reason` VARCHAR(20) NOT NULL DEFAULT '' COMMENT 'Why the resource is invalid: expired, empty otherwise' AFTER `valid`;
@@ -16,12 +16,14 @@ CREATE TABLE `categories` (
   `value` VARCHAR(50) NOT NULL COMMENT 'Category value',
   `label` VARCHAR(50) NOT NULL COMMENT 'Category label',
   `icon` VARCHAR(20) NOT NULL COMMENT 'Icon',
+  `parent_id` INT UNSIGNED COMMENT 'Parent category ID, NULL for top level',
   `sort_order` INT NOT NULL DEFAULT 0 COMMENT 'Sort order',
   `is_active` TINYINT(1) NOT NULL DEFAULT 1 COMMENT 'Is active',
   `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
   `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
   PRIMARY KEY (`id`),
   UNIQUE KEY `uk_value` (`value`),
+  KEY `idx_parent_id` (`parent_id`),
   KEY `idx_sort_order` (`sort_order`),
   KEY `idx_is_active` (`is_active`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Categories table';
@@ -30,31 +32,45 @@ CREATE TABLE `categories` (
 CREATE TABLE `resources` (
   `id` VARCHAR(32) NOT NULL COMMENT 'Resource ID',
   `title` VARCHAR(255) NOT NULL COMMENT 'Resource title',
+  `title_pinyin` VARCHAR(1600) NOT NULL DEFAULT '' COMMENT 'Title full pinyin',
+  `title_initials` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Title pinyin initials',
   `description` TEXT COMMENT 'Resource description',
   `size` VARCHAR(20) NOT NULL COMMENT 'File size',
+  `size_bytes` BIGINT UNSIGNED COMMENT 'File size in bytes parsed from size, NULL if unknown',
   `type` VARCHAR(50) NOT NULL COMMENT 'Resource type',
   `category_id` INT UNSIGNED NOT NULL COMMENT 'Category ID',
   `source` VARCHAR(100) NOT NULL COMMENT 'Source platform',
   `download_url` VARCHAR(500) NOT NULL COMMENT 'Download URL',
+  `share_key` VARCHAR(100) NOT NULL DEFAULT '' COMMENT 'Share dedup key provider:shareId, empty if the URL is not recognized',
   `extract_code` VARCHAR(20) COMMENT 'Extract code',
   `file_count` INT UNSIGNED NOT NULL DEFAULT 1 COMMENT 'File count',
   `view_count` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'View count',
   `download_count` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Download count',
   `valid` TINYINT(1) NOT NULL DEFAULT 1 COMMENT 'Is valid',
+  `invalid_reason` VARCHAR(20) NOT NULL DEFAULT '' COMMENT 'Why the resource is invalid: expired, empty otherwise',
+  `link_status` VARCHAR(20) NOT NULL DEFAULT '' COMMENT 'Last link check result: alive/dead/unknown, empty if never checked',
+  `check_failures` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Consecutive dead link checks',
+  `last_checked_at` DATETIME COMMENT 'Last link check time',
+  `next_check_at` DATETIME COMMENT 'Next scheduled link check time, NULL if never checked',
   `expire_time` DATETIME COMMENT 'Expire time',
   `upload_time` DATETIME NOT NULL COMMENT 'Upload time',
   `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
   `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  `deleted_at` DATETIME COMMENT 'Soft deleted time',
   PRIMARY KEY (`id`),
   KEY `idx_category_id` (`category_id`),
   KEY `idx_type` (`type`),
   KEY `idx_source` (`source`),
+  KEY `idx_share_key` (`share_key`),
   KEY `idx_upload_time` (`upload_time`),
+  KEY `idx_size_bytes` (`size_bytes`),
   KEY `idx_view_count` (`view_count`),
   KEY `idx_download_count` (`download_count`),
   KEY `idx_valid` (`valid`),
+  KEY `idx_next_check_at` (`next_check_at`),
   KEY `idx_expire_time` (`expire_time`),
-  FULLTEXT KEY `ft_title_description` (`title`, `description`)
+  KEY `idx_deleted_at` (`deleted_at`),
+  FULLTEXT KEY `ft_title_description` (`title`, `description`) WITH PARSER ngram
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Resources table';
 
 -- 3. Resource tags table
@@ -62,6 +78,8 @@ CREATE TABLE `resource_tags` (
   `id` INT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Tag ID',
   `resource_id` VARCHAR(32) NOT NULL COMMENT 'Resource ID',
   `tag_name` VARCHAR(50) NOT NULL COMMENT 'Tag name',
+  `pinyin` VARCHAR(300) NOT NULL DEFAULT '' COMMENT 'Tag full pinyin',
+  `initials` VARCHAR(50) NOT NULL DEFAULT '' COMMENT 'Tag pinyin initials',
   `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
   PRIMARY KEY (`id`),
   KEY `idx_resource_id` (`resource_id`),
@@ -78,6 +96,7 @@ CREATE TABLE `help_requests` (
   `contact_type` ENUM('email', 'phone') NOT NULL COMMENT 'Contact type',
   `status` ENUM('pending', 'processing', 'completed', 'rejected') NOT NULL DEFAULT 'pending' COMMENT 'Status',
   `admin_notes` TEXT COMMENT 'Admin notes',
+  `access_token_hash` VARCHAR(64) COMMENT 'Status lookup token hash',
   `completed_time` DATETIME COMMENT 'Completed time',
   `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
   `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
@@ -123,7 +142,7 @@ CREATE TABLE `search_records` (
   KEY `idx_user_id` (`user_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Search records table';
 
--- 7. Users table (reserved for future extension)
+-- 7. Users table
 CREATE TABLE `users` (
   `id` VARCHAR(32) NOT NULL COMMENT 'User ID',
   `username` VARCHAR(50) NOT NULL COMMENT 'Username',
@@ -131,6 +150,7 @@ CREATE TABLE `users` (
   `phone` VARCHAR(20) COMMENT 'Phone',
   `password_hash` VARCHAR(255) NOT NULL COMMENT 'Password hash',
   `avatar` VARCHAR(500) COMMENT 'Avatar URL',
+  `role` VARCHAR(20) NOT NULL DEFAULT 'user' COMMENT 'User role',
   `status` ENUM('active', 'inactive', 'banned') NOT NULL DEFAULT 'active' COMMENT 'User status',
   `last_login_at` DATETIME COMMENT 'Last login time',
   `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
@@ -158,11 +178,114 @@ CREATE TABLE `system_configs` (
   KEY `idx_is_public` (`is_public`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='System configs table';
 
+-- 9. User sessions table
+CREATE TABLE `user_sessions` (
+  `id` VARCHAR(32) NOT NULL COMMENT 'Session ID',
+  `user_id` VARCHAR(32) NOT NULL COMMENT 'User ID',
+  `refresh_token_hash` VARCHAR(64) NOT NULL COMMENT 'Current refresh token hash',
+  `previous_token_hash` VARCHAR(64) COMMENT 'Rotated refresh token hash, used for reuse detection',
+  `user_agent` TEXT COMMENT 'User agent',
+  `ip_address` VARCHAR(45) COMMENT 'IP address',
+  `expires_at` DATETIME NOT NULL COMMENT 'Refresh token expire time',
+  `revoked_at` DATETIME COMMENT 'Revoked time',
+  `last_used_at` DATETIME NOT NULL COMMENT 'Last used time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='User sessions table';
+
+-- 10. Roles table
+CREATE TABLE `roles` (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Role ID',
+  `name` VARCHAR(20) NOT NULL COMMENT 'Role name, referenced by users.role',
+  `label` VARCHAR(50) NOT NULL COMMENT 'Role label',
+  `description` VARCHAR(255) COMMENT 'Description',
+  `is_system` TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Is built-in role',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Roles table';
+
+-- 11. Permissions table
+CREATE TABLE `permissions` (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Permission ID',
+  `code` VARCHAR(50) NOT NULL COMMENT 'Permission code',
+  `description` VARCHAR(255) COMMENT 'Description',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_code` (`code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Permissions table';
+
+-- 12. Role permissions table
+CREATE TABLE `role_permissions` (
+  `role_id` INT UNSIGNED NOT NULL COMMENT 'Role ID',
+  `permission_id` INT UNSIGNED NOT NULL COMMENT 'Permission ID',
+  PRIMARY KEY (`role_id`, `permission_id`),
+  KEY `idx_permission_id` (`permission_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Role permissions table';
+
+-- 13. Link checks table
+CREATE TABLE `link_checks` (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Check ID',
+  `resource_id` VARCHAR(32) NOT NULL COMMENT 'Resource ID',
+  `provider` VARCHAR(20) NOT NULL COMMENT 'Probe used for the check',
+  `status` VARCHAR(20) NOT NULL COMMENT 'alive/dead/unknown',
+  `http_status` INT NOT NULL DEFAULT 0 COMMENT 'HTTP status code, 0 if the request failed',
+  `detail` VARCHAR(255) COMMENT 'Check detail',
+  `duration_ms` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Check duration in milliseconds',
+  `checked_at` DATETIME NOT NULL COMMENT 'Check time',
+  PRIMARY KEY (`id`),
+  KEY `idx_resource_id` (`resource_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Link checks table';
+
+-- 14. Resource reports table
+CREATE TABLE `resource_reports` (
+  `id` VARCHAR(32) NOT NULL COMMENT 'Report ID',
+  `resource_id` VARCHAR(32) NOT NULL COMMENT 'Resource ID',
+  `reason` VARCHAR(20) NOT NULL COMMENT 'dead_link/wrong_code/wrong_category/illegal/other',
+  `description` VARCHAR(500) COMMENT 'Report description',
+  `user_id` VARCHAR(32) NOT NULL DEFAULT '' COMMENT 'Reporter user ID, empty for anonymous reports',
+  `ip_address` VARCHAR(45) NOT NULL COMMENT 'Reporter IP address',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT 'pending/resolved/dismissed',
+  `admin_notes` VARCHAR(500) COMMENT 'Admin notes',
+  `resolved_by` VARCHAR(32) COMMENT 'Admin user ID that handled the report',
+  `resolved_at` DATETIME COMMENT 'Resolved time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  PRIMARY KEY (`id`),
+  KEY `idx_resource_status` (`resource_id`, `status`),
+  KEY `idx_status_created_at` (`status`, `created_at`),
+  KEY `idx_ip_created_at` (`ip_address`, `created_at`),
+  KEY `idx_user_created_at` (`user_id`, `created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Resource reports table';
+
+-- 15. Connector states table
+CREATE TABLE `connector_states` (
+  `name` VARCHAR(100) NOT NULL COMMENT 'Connector name from config',
+  `cursor` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Publish time of the newest collected item',
+  `last_run_at` DATETIME COMMENT 'Last run time',
+  `last_success_at` DATETIME COMMENT 'Last successful run time',
+  `last_error` VARCHAR(500) NOT NULL DEFAULT '' COMMENT 'Error of the last run, empty if it succeeded',
+  `last_fetched` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Items after the cursor in the last run',
+  `last_created` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Resources created in the last run',
+  `last_updated` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Resources updated in the last run',
+  `last_skipped` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Items or shares skipped in the last run',
+  `last_failed` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Shares that failed to save in the last run',
+  `runs` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Total runs',
+  `failed_runs` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Runs that failed to fetch or parse',
+  `total_created` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Total resources created',
+  `total_updated` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Total resources updated',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Connector cursors and stats table';
+
 -- Insert initial data
 
 -- Insert categories data
+-- The "all" pseudo-category is generated by the API and is not stored
 INSERT INTO `categories` (`value`, `label`, `icon`, `sort_order`) VALUES
-('all', '全部', '📁', 0),
 ('movie', '电影', '🎬', 1),
 ('tv', '电视剧', '📺', 2),
 ('music', '音乐', '🎵', 3),
@@ -170,6 +293,40 @@ INSERT INTO `categories` (`value`, `label`, `icon`, `sort_order`) VALUES
 ('document', '文档', '📄', 5),
 ('other', '其他', '📦', 6);
 
+-- Insert roles and permissions
+INSERT INTO `roles` (`name`, `label`, `description`, `is_system`) VALUES
+('admin', '管理员', '拥有全部权限', 1),
+('moderator', '审核员', '处理求助请求和用户管理', 1),
+('editor', '编辑', '维护资源和分类', 1),
+('user', '普通用户', '注册用户默认角色', 1);
+
+INSERT INTO `permissions` (`code`, `description`) VALUES
+('*', '全部权限'),
+('admin:access', '访问管理接口'),
+('resource:write', '创建、编辑、下架资源'),
+('category:write', '管理分类'),
+('help_request:read', '查看求助请求'),
+('help_request:process', '处理求助请求'),
+('user:manage', '管理用户'),
+('role:manage', '管理角色权限'),
+('config:read', '查看系统配置'),
+('config:write', '修改系统配置'),
+('data:export', '导出数据');
+
+INSERT INTO `role_permissions` (`role_id`, `permission_id`)
+SELECT r.id, p.id FROM `roles` r JOIN `permissions` p
+WHERE (r.name, p.code) IN (
+  ('admin', '*'),
+  ('moderator', 'admin:access'),
+  ('moderator', 'help_request:read'),
+  ('moderator', 'help_request:process'),
+  ('moderator', 'user:manage'),
+  ('editor', 'admin:access'),
+  ('editor', 'resource:write'),
+  ('editor', 'category:write'),
+  ('editor', 'help_request:read')
+);
+
 -- Insert system configs
 INSERT INTO `system_configs` (`config_key`, `config_value`, `config_type`, `description`, `is_public`) VALUES
 ('site_title', '网盘资源搜索', 'string', '网站标题', 1),
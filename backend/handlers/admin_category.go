@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"errors"
+	"pan-search-api/common"
+	"pan-search-api/database"
+	"pan-search-api/models"
+	"pan-search-api/services"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminListCategories 分类列表
+// @Summary 分类列表
+// @Description 管理端获取全部分类（包含停用的分类），按树形结构返回
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response
+// @Router /admin/categories [get]
+func AdminListCategories(c *gin.Context) {
+	var categories []models.Category
+	if err := database.DB.Order("sort_order ASC").Find(&categories).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	common.Success(c, map[string]interface{}{
+		"list": toAdminCategoryTree(services.BuildCategoryTree(categories)),
+	})
+}
+
+// AdminCreateCategory 创建分类
+// @Summary 创建分类
+// @Description 创建分类，可指定父分类
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body models.CategoryCreate true "分类信息"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.Category}
+// @Router /admin/categories [post]
+func AdminCreateCategory(c *gin.Context) {
+	var req models.CategoryCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	category := models.Category{
+		Value:     strings.TrimSpace(req.Value),
+		Label:     strings.TrimSpace(req.Label),
+		Icon:      req.Icon,
+		SortOrder: req.SortOrder,
+		IsActive:  true,
+	}
+	if category.Value == services.CategoryAll {
+		common.BadRequest(c, "分类值all为保留值")
+		return
+	}
+	if req.IsActive != nil {
+		category.IsActive = *req.IsActive
+	}
+	if req.ParentID != nil && *req.ParentID != 0 {
+		if err := services.CheckCategoryParent(database.DB, 0, *req.ParentID); err != nil {
+			respondCategoryError(c, err)
+			return
+		}
+		category.ParentID = req.ParentID
+	}
+
+	// Select("*") 保证 is_active=false 也会写入
+	if err := database.DB.Select("*").Create(&category).Error; err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	common.Success(c, category)
+}
+
+// AdminUpdateCategory 更新分类
+// @Summary 更新分类
+// @Description 更新分类信息，parentId为0表示移动到顶级
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "分类ID"
+// @Param body body models.CategoryUpdate true "分类信息"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.Category}
+// @Router /admin/categories/{id} [put]
+func AdminUpdateCategory(c *gin.Context) {
+	id, ok := categoryIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.CategoryUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Value != nil {
+		value := strings.TrimSpace(*req.Value)
+		if value == services.CategoryAll {
+			common.BadRequest(c, "分类值all为保留值")
+			return
+		}
+		updates["value"] = value
+	}
+	if req.Label != nil {
+		updates["label"] = strings.TrimSpace(*req.Label)
+	}
+	if req.Icon != nil {
+		updates["icon"] = *req.Icon
+	}
+	if req.SortOrder != nil {
+		updates["sort_order"] = *req.SortOrder
+	}
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			updates["parent_id"] = nil
+		} else {
+			if err := services.CheckCategoryParent(database.DB, id, *req.ParentID); err != nil {
+				respondCategoryError(c, err)
+				return
+			}
+			updates["parent_id"] = *req.ParentID
+		}
+	}
+
+	updateCategory(c, id, updates)
+}
+
+// AdminUpdateCategoryStatus 启用或停用分类
+// @Summary 启用或停用分类
+// @Description 停用的分类及其子分类不在前台分类列表中展示
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "分类ID"
+// @Param body body models.CategoryStatusUpdate true "状态"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.Category}
+// @Router /admin/categories/{id}/status [put]
+func AdminUpdateCategoryStatus(c *gin.Context) {
+	id, ok := categoryIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.CategoryStatusUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	updateCategory(c, id, map[string]interface{}{"is_active": *req.IsActive})
+}
+
+// AdminSortCategories 调整分类排序
+// @Summary 调整分类排序
+// @Description 批量设置分类的SortOrder
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body models.CategorySortUpdate true "排序"
+// @Security BearerAuth
+// @Success 200 {object} common.Response
+// @Router /admin/categories/sort [put]
+func AdminSortCategories(c *gin.Context) {
+	var req models.CategorySortUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, item := range req.Items {
+			result := tx.Model(&models.Category{}).Where("id = ?", item.ID).Update("sort_order", item.SortOrder)
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		common.InternalServerError(c, "更新失败")
+		return
+	}
+
+	common.Success(c, nil)
+}
+
+// AdminDeleteCategory 删除分类
+// @Summary 删除分类
+// @Description 删除没有子分类和资源的分类
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "分类ID"
+// @Security BearerAuth
+// @Success 200 {object} common.Response
+// @Router /admin/categories/{id} [delete]
+func AdminDeleteCategory(c *gin.Context) {
+	id, ok := categoryIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := services.DeleteCategory(database.DB, id); err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	common.Success(c, nil)
+}
+
+// updateCategory 更新分类并返回最新数据
+func updateCategory(c *gin.Context, id uint, updates map[string]interface{}) {
+	var category models.Category
+	if err := database.DB.First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			common.NotFound(c, services.ErrCategoryNotFound.Error())
+		} else {
+			common.InternalServerError(c, "查询失败")
+		}
+		return
+	}
+
+	if len(updates) > 0 {
+		if err := database.DB.Model(&category).Updates(updates).Error; err != nil {
+			respondCategoryError(c, err)
+			return
+		}
+	}
+
+	common.Success(c, category)
+}
+
+// categoryIDParam 解析路径中的分类ID
+func categoryIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil || id == 0 {
+		common.BadRequest(c, "分类ID错误")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondCategoryError 返回分类操作错误
+func respondCategoryError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrCategoryNotFound):
+		common.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrCategoryCycle), errors.Is(err, services.ErrCategoryInUse),
+		errors.Is(err, services.ErrParentCategoryNotFound):
+		common.BadRequest(c, err.Error())
+	case isDuplicateKeyError(err):
+		common.Conflict(c, "分类值已存在")
+	default:
+		common.InternalServerError(c, "操作失败")
+	}
+}
+
+// adminCategoryNode 管理端分类树节点
+type adminCategoryNode struct {
+	models.Category
+	Children []adminCategoryNode `json:"children"`
+}
+
+// toAdminCategoryTree 转换为管理端分类树
+func toAdminCategoryTree(nodes []*services.CategoryNode) []adminCategoryNode {
+	result := make([]adminCategoryNode, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, adminCategoryNode{
+			Category: node.Category,
+			Children: toAdminCategoryTree(node.Children),
+		})
+	}
+	return result
+}
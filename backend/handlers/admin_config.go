@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"pan-search-api/common"
+	"pan-search-api/database"
+	"pan-search-api/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminListConfigs 系统配置列表
+// @Summary 系统配置列表
+// @Description 获取全部系统配置
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=[]models.SystemConfig}
+// @Router /admin/configs [get]
+func AdminListConfigs(c *gin.Context) {
+	var configs []models.SystemConfig
+	if err := database.DB.Order("config_key ASC").Find(&configs).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	common.Success(c, map[string]interface{}{
+		"list": configs,
+	})
+}
+
+// AdminUpdateConfig 修改系统配置
+// @Summary 修改系统配置
+// @Description 修改系统配置值，值需要符合配置类型
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key path string true "配置键"
+// @Param body body models.SystemConfigUpdate true "配置值"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.SystemConfig}
+// @Router /admin/configs/{key} [put]
+func AdminUpdateConfig(c *gin.Context) {
+	var req models.SystemConfigUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	var cfg models.SystemConfig
+	if err := database.DB.First(&cfg, "config_key = ?", c.Param("key")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			common.NotFound(c, "配置不存在")
+		} else {
+			common.InternalServerError(c, "查询失败")
+		}
+		return
+	}
+
+	if !validConfigValue(cfg.ConfigType, req.Value) {
+		common.BadRequest(c, "配置值与类型"+cfg.ConfigType+"不匹配")
+		return
+	}
+
+	if err := database.DB.Model(&cfg).Update("config_value", req.Value).Error; err != nil {
+		common.InternalServerError(c, "更新失败")
+		return
+	}
+
+	common.Success(c, cfg)
+}
+
+// validConfigValue 校验配置值是否符合类型
+func validConfigValue(configType, value string) bool {
+	switch configType {
+	case "number":
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	case "boolean":
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	case "json":
+		return json.Valid([]byte(value))
+	}
+	return true
+}
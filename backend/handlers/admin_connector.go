@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"pan-search-api/common"
+	"pan-search-api/connector"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminListConnectors 采集来源列表
+// @Summary 采集来源列表
+// @Description 返回配置的采集来源及其游标、最近一次采集的统计和累计统计
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=[]connector.Status}
+// @Router /admin/connectors [get]
+func AdminListConnectors(c *gin.Context) {
+	runner := connector.Default()
+	if runner == nil {
+		common.InternalServerError(c, "资源采集未启动")
+		return
+	}
+
+	statuses, err := runner.Statuses()
+	if err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	common.Success(c, statuses)
+}
+
+// AdminRunConnector 立即采集
+// @Summary 立即采集
+// @Description 立即采集指定来源并返回本次统计，未启用定时采集时同样可用
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param name path string true "来源名称"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=connector.Stats}
+// @Failure 502 {object} common.Response "抓取或解析来源失败"
+// @Router /admin/connectors/{name}/run [post]
+func AdminRunConnector(c *gin.Context) {
+	runner := connector.Default()
+	if runner == nil {
+		common.InternalServerError(c, "资源采集未启动")
+		return
+	}
+
+	stats, err := runner.Run(c.Request.Context(), c.Param("name"))
+	switch {
+	case errors.Is(err, connector.ErrConnectorNotFound):
+		common.NotFound(c, err.Error())
+	case err != nil:
+		common.Error(c, http.StatusBadGateway, "采集失败: "+err.Error())
+	default:
+		common.Success(c, stats)
+	}
+}
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"pan-search-api/common"
+	"pan-search-api/database"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 每批读取的行数
+const exportChunkSize = 1000
+
+// exportColumn 导出列
+type exportColumn struct {
+	Name string // 输出列名
+	Expr string // SELECT表达式
+}
+
+// exportDataset 可导出的数据集
+type exportDataset struct {
+	Table      string
+	Key        string // 主键列，用于分批读取
+	DateColumn string // 日期范围筛选列
+	Columns    []exportColumn
+	// Filter 附加固定筛选和分类筛选
+	Filter func(db *gorm.DB, category string) *gorm.DB
+}
+
+// exportDatasets 支持导出的数据集
+var exportDatasets = map[string]exportDataset{
+	"resources": {
+		Table:      "resources",
+		Key:        "resources.id",
+		DateColumn: "resources.created_at",
+		Columns: []exportColumn{
+			{"id", "resources.id"},
+			{"title", "resources.title"},
+			{"description", "resources.description"},
+			{"size", "resources.size"},
+			{"size_bytes", "resources.size_bytes"},
+			{"type", "resources.type"},
+			{"category", "categories.value"},
+			{"source", "resources.source"},
+			{"download_url", "resources.download_url"},
+			{"extract_code", "resources.extract_code"},
+			{"file_count", "resources.file_count"},
+			{"view_count", "resources.view_count"},
+			{"download_count", "resources.download_count"},
+			{"valid", "resources.valid"},
+			{"expire_time", "resources.expire_time"},
+			{"upload_time", "resources.upload_time"},
+			{"created_at", "resources.created_at"},
+			{"tags", "(SELECT GROUP_CONCAT(resource_tags.tag_name ORDER BY resource_tags.id SEPARATOR ',') FROM resource_tags WHERE resource_tags.resource_id = resources.id)"},
+		},
+		Filter: func(db *gorm.DB, category string) *gorm.DB {
+			db = db.Joins("LEFT JOIN categories ON categories.id = resources.category_id").
+				Where("resources.deleted_at IS NULL")
+			if category != "" {
+				db = db.Where("categories.value = ?", category)
+			}
+			return db
+		},
+	},
+	"search_records": {
+		Table:      "search_records",
+		Key:        "search_records.id",
+		DateColumn: "search_records.search_time",
+		Columns: []exportColumn{
+			{"id", "search_records.id"},
+			{"keyword", "search_records.keyword"},
+			{"category", "search_records.category"},
+			{"sort_by", "search_records.sort_by"},
+			{"user_id", "search_records.user_id"},
+			{"ip_address", "search_records.ip_address"},
+			{"user_agent", "search_records.user_agent"},
+			{"result_count", "search_records.result_count"},
+			{"search_time", "search_records.search_time"},
+		},
+		Filter: func(db *gorm.DB, category string) *gorm.DB {
+			if category != "" {
+				db = db.Where("search_records.category = ?", category)
+			}
+			return db
+		},
+	},
+	"download_records": {
+		Table:      "download_records",
+		Key:        "download_records.id",
+		DateColumn: "download_records.download_time",
+		Columns: []exportColumn{
+			{"id", "download_records.id"},
+			{"resource_id", "download_records.resource_id"},
+			{"resource_title", "resources.title"},
+			{"category", "categories.value"},
+			{"user_id", "download_records.user_id"},
+			{"ip_address", "download_records.ip_address"},
+			{"user_agent", "download_records.user_agent"},
+			{"download_time", "download_records.download_time"},
+		},
+		Filter: func(db *gorm.DB, category string) *gorm.DB {
+			db = db.Joins("LEFT JOIN resources ON resources.id = download_records.resource_id").
+				Joins("LEFT JOIN categories ON categories.id = resources.category_id")
+			if category != "" {
+				db = db.Where("categories.value = ?", category)
+			}
+			return db
+		},
+	},
+	"help_requests": {
+		Table:      "help_requests",
+		Key:        "help_requests.id",
+		DateColumn: "help_requests.created_at",
+		Columns: []exportColumn{
+			{"id", "help_requests.id"},
+			{"resource_name", "help_requests.resource_name"},
+			{"resource_type", "help_requests.resource_type"},
+			{"description", "help_requests.description"},
+			{"contact", "help_requests.contact"},
+			{"contact_type", "help_requests.contact_type"},
+			{"status", "help_requests.status"},
+			{"admin_notes", "help_requests.admin_notes"},
+			{"completed_time", "help_requests.completed_time"},
+			{"created_at", "help_requests.created_at"},
+		},
+		Filter: func(db *gorm.DB, category string) *gorm.DB {
+			if category != "" {
+				db = db.Where("help_requests.resource_type = ?", category)
+			}
+			return db
+		},
+	},
+}
+
+// exportWriter 按格式写出行数据
+type exportWriter interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []sql.NullString) error
+	Flush() error
+}
+
+// AdminExportData 导出数据
+// @Summary 导出数据
+// @Description 以CSV或JSONL流式导出资源、搜索记录、下载记录或求助请求，分批读取，不会一次性加载全表
+// @Tags admin
+// @Produce plain
+// @Param dataset path string true "数据集 (resources/search_records/download_records/help_requests)"
+// @Param format query string false "导出格式 (csv/jsonl)，默认csv"
+// @Param startDate query string false "开始日期 (2006-01-02)"
+// @Param endDate query string false "结束日期 (2006-01-02)"
+// @Param category query string false "分类value，求助请求按资源类型筛选"
+// @Security BearerAuth
+// @Success 200 {string} string "导出文件"
+// @Router /admin/export/{dataset} [get]
+func AdminExportData(c *gin.Context) {
+	name := c.Param("dataset")
+	dataset, ok := exportDatasets[name]
+	if !ok {
+		common.NotFound(c, "不支持导出的数据集: "+name)
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "jsonl" {
+		common.BadRequest(c, "不支持的导出格式，仅支持csv和jsonl")
+		return
+	}
+
+	start, end, err := parseDateRange(c.Query("startDate"), c.Query("endDate"))
+	if err != nil {
+		common.BadRequest(c, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format("20060102150405"), format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Header("X-Content-Type-Options", "nosniff")
+	var writer exportWriter
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		// 写入BOM，方便Excel正确识别UTF-8
+		c.Writer.WriteString("\ufeff")
+		writer = &csvExportWriter{w: csv.NewWriter(c.Writer)}
+	} else {
+		c.Header("Content-Type", "application/x-ndjson; charset=utf-8")
+		writer = &jsonlExportWriter{w: c.Writer}
+	}
+	c.Status(200)
+
+	if err := streamDataset(c, dataset, start, end, c.Query("category"), writer); err != nil {
+		// 响应已开始写出，只能记录日志并中断连接
+		log.Printf("export %s failed: %v", name, err)
+		c.Abort()
+	}
+}
+
+// streamDataset 按主键分批读取并写出
+func streamDataset(c *gin.Context, dataset exportDataset, start, end time.Time, category string, writer exportWriter) error {
+	names := make([]string, len(dataset.Columns))
+	selects := make([]string, len(dataset.Columns)+1)
+	for i, column := range dataset.Columns {
+		names[i] = column.Name
+		selects[i] = column.Expr + " AS `" + column.Name + "`"
+	}
+	// 最后一列为分批游标
+	selects[len(dataset.Columns)] = dataset.Key + " AS `export_key`"
+
+	if err := writer.WriteHeader(names); err != nil {
+		return err
+	}
+
+	var lastKey string
+	for first := true; ; first = false {
+		query := dataset.Filter(database.DB.Table(dataset.Table), category).
+			Select(selects).
+			Order(dataset.Key).
+			Limit(exportChunkSize)
+		if !start.IsZero() {
+			query = query.Where(dataset.DateColumn+" >= ?", start)
+		}
+		if !end.IsZero() {
+			query = query.Where(dataset.DateColumn+" < ?", end)
+		}
+		if !first {
+			query = query.Where(dataset.Key+" > ?", lastKey)
+		}
+
+		count, key, err := writeChunk(query, len(selects), writer)
+		if err != nil {
+			return err
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+
+		if count < exportChunkSize {
+			return nil
+		}
+		lastKey = key
+	}
+}
+
+// writeChunk 写出一批数据，返回行数和最后一行的游标
+func writeChunk(query *gorm.DB, columns int, writer exportWriter) (int, string, error) {
+	rows, err := query.Rows()
+	if err != nil {
+		return 0, "", err
+	}
+	defer rows.Close()
+
+	values := make([]sql.NullString, columns)
+	dest := make([]interface{}, columns)
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	count := 0
+	var lastKey string
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return count, lastKey, err
+		}
+		if err := writer.WriteRow(values[:columns-1]); err != nil {
+			return count, lastKey, err
+		}
+		lastKey = values[columns-1].String
+		count++
+	}
+	return count, lastKey, rows.Err()
+}
+
+// csvExportWriter CSV格式输出
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (cw *csvExportWriter) WriteHeader(columns []string) error {
+	return cw.w.Write(columns)
+}
+
+func (cw *csvExportWriter) WriteRow(values []sql.NullString) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = v.String
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvExportWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// jsonlExportWriter JSONL格式输出，每行一个JSON对象
+type jsonlExportWriter struct {
+	w       io.Writer
+	columns []string
+}
+
+func (jw *jsonlExportWriter) WriteHeader(columns []string) error {
+	jw.columns = columns
+	return nil
+}
+
+func (jw *jsonlExportWriter) WriteRow(values []sql.NullString) error {
+	obj := make(map[string]interface{}, len(values))
+	for i, v := range values {
+		if v.Valid {
+			obj[jw.columns[i]] = v.String
+		} else {
+			obj[jw.columns[i]] = nil
+		}
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	_, err = jw.w.Write(append(data, '\n'))
+	return err
+}
+
+func (jw *jsonlExportWriter) Flush() error {
+	return nil
+}
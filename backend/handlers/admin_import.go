@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"pan-search-api/common"
+	"pan-search-api/database"
+	"pan-search-api/importer"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 导入文件大小上限
+const maxImportFileSize = 10 << 20
+
+// AdminImportResources 批量导入资源
+// @Summary 批量导入资源
+// @Description 上传CSV或JSONL文件批量导入资源和标签，按下载链接去重，单行错误记入报告而不中断整批导入
+// @Tags admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV或JSONL文件"
+// @Param format formData string false "文件格式 (csv/jsonl)，默认按扩展名判断"
+// @Param dryRun formData bool false "只校验不写入"
+// @Param mapping formData string false "列映射JSON，如 {\"title\":\"名称\",\"category\":\"分类\"}"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=importer.Report}
+// @Router /admin/resources/import [post]
+func AdminImportResources(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		common.BadRequest(c, "请上传导入文件")
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		common.BadRequest(c, "导入文件不能超过10MB")
+		return
+	}
+
+	format := c.PostForm("format")
+	if format == "" {
+		format = importer.DetectFormat(fileHeader.Filename)
+	}
+	if format != importer.FormatCSV && format != importer.FormatJSONL {
+		common.BadRequest(c, "不支持的文件格式，仅支持csv和jsonl")
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.PostForm("dryRun"))
+
+	var mapping map[string]string
+	if raw := c.PostForm("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+			common.BadRequest(c, "列映射格式错误")
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		common.InternalServerError(c, "读取文件失败")
+		return
+	}
+	defer file.Close()
+
+	report, err := importer.Import(database.DB, file, importer.Options{
+		Format:  format,
+		Mapping: mapping,
+		DryRun:  dryRun,
+	})
+	if err != nil {
+		if report == nil {
+			common.BadRequest(c, err.Error())
+			return
+		}
+		// 读取中途失败时仍返回已处理部分的报告
+		c.JSON(http.StatusBadRequest, common.Response{
+			Code:      http.StatusBadRequest,
+			Message:   err.Error(),
+			Data:      report,
+			Timestamp: time.Now().UnixMilli(),
+		})
+		return
+	}
+
+	common.Success(c, report)
+}
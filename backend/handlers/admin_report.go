@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"errors"
+	"pan-search-api/common"
+	"pan-search-api/database"
+	"pan-search-api/linkcheck"
+	"pan-search-api/middleware"
+	"pan-search-api/models"
+	"pan-search-api/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminListReports 举报队列
+// @Summary 举报队列
+// @Description 按资源汇总举报，举报人多的资源排在前面，已删除资源的举报不返回
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param status query string false "举报状态 (pending/resolved/dismissed)，默认pending"
+// @Param reason query string false "举报原因 (dead_link/wrong_code/wrong_category/illegal/other)"
+// @Param page query int false "页码，默认1"
+// @Param pageSize query int false "每页数量，默认10"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=common.PaginatedResponse{list=[]models.ReportedResource}}
+// @Router /admin/reports [get]
+func AdminListReports(c *gin.Context) {
+	var req models.AdminReportQuery
+	if !bindReportQuery(c, &req) {
+		return
+	}
+
+	db := reportQuery(database.DB, req).
+		Where("resource_id IN (?)", database.DB.Model(&models.Resource{}).Select("id"))
+
+	var total int64
+	if err := db.Session(&gorm.Session{}).Distinct("resource_id").Count(&total).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	var rows []models.ReportedResource
+	if err := db.Session(&gorm.Session{}).
+		Select("resource_id, COUNT(*) AS report_count, COUNT(DISTINCT " + services.ReporterKey + ") AS reporter_count, " +
+			"MIN(created_at) AS first_report_at, MAX(created_at) AS last_report_at").
+		Group("resource_id").
+		Order("reporter_count DESC, last_report_at DESC, resource_id ASC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Scan(&rows).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+	if len(rows) == 0 {
+		common.SuccessWithPagination(c, []models.ReportedResource{}, req.Page, req.PageSize, int(total))
+		return
+	}
+
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ResourceID
+	}
+
+	// 各资源按原因的举报数
+	var reasonCounts []struct {
+		ResourceID string
+		Reason     string
+		Count      int
+	}
+	if err := db.Session(&gorm.Session{}).
+		Select("resource_id, reason, COUNT(*) AS count").
+		Where("resource_id IN ?", ids).
+		Group("resource_id, reason").
+		Scan(&reasonCounts).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	var resources []models.Resource
+	if err := database.DB.Select("id", "title", "source", "valid", "link_status").
+		Where("id IN ?", ids).
+		Find(&resources).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+	byID := make(map[string]*models.Resource, len(resources))
+	for i := range resources {
+		byID[resources[i].ID] = &resources[i]
+	}
+
+	index := make(map[string]*models.ReportedResource, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		row.Reasons = map[string]int{}
+		if resource, ok := byID[row.ResourceID]; ok {
+			row.Title = resource.Title
+			row.Source = resource.Source
+			row.Valid = resource.Valid
+			row.LinkStatus = resource.LinkStatus
+		}
+		index[row.ResourceID] = row
+	}
+	for _, rc := range reasonCounts {
+		if row, ok := index[rc.ResourceID]; ok {
+			row.Reasons[rc.Reason] = rc.Count
+		}
+	}
+
+	common.SuccessWithPagination(c, rows, req.Page, req.PageSize, int(total))
+}
+
+// AdminListResourceReports 资源的举报记录
+// @Summary 资源的举报记录
+// @Description 查询资源的举报明细，按提交时间倒序
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "资源ID"
+// @Param status query string false "举报状态 (pending/resolved/dismissed)，默认pending"
+// @Param reason query string false "举报原因"
+// @Param page query int false "页码，默认1"
+// @Param pageSize query int false "每页数量，默认10"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=common.PaginatedResponse{list=[]models.ResourceReport}}
+// @Router /admin/resources/{id}/reports [get]
+func AdminListResourceReports(c *gin.Context) {
+	var req models.AdminReportQuery
+	if !bindReportQuery(c, &req) {
+		return
+	}
+
+	db := reportQuery(database.DB, req).Where("resource_id = ?", c.Param("id"))
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	var reports []models.ResourceReport
+	if err := db.Order("created_at DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&reports).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	common.SuccessWithPagination(c, reports, req.Page, req.PageSize, int(total))
+}
+
+// AdminResolveReports 处理资源的举报
+// @Summary 处理资源的举报
+// @Description 将资源全部待处理的举报标记为已处理或驳回，可同时修改资源有效状态，处理后立即重新检测链接
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "资源ID"
+// @Param body body models.ReportResolve true "处理结果"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.ReportResolveResponse}
+// @Router /admin/resources/{id}/reports/resolve [post]
+func AdminResolveReports(c *gin.Context) {
+	var req models.ReportResolve
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	id := c.Param("id")
+	resolved, err := services.ResolveReports(database.DB, id, middleware.GetUserID(c), req)
+	switch {
+	case errors.Is(err, services.ErrNoPendingReports), errors.Is(err, services.ErrResourceNotFound):
+		common.NotFound(c, err.Error())
+		return
+	case err != nil:
+		common.InternalServerError(c, "处理失败")
+		return
+	}
+
+	// 举报处理后链接可能已被修复或确认失效，立即重新检测
+	response := models.ReportResolveResponse{Resolved: resolved}
+	if checker := linkcheck.Default(); checker != nil {
+		response.RecheckQueued = checker.Enqueue(id)
+	}
+
+	common.Success(c, response)
+}
+
+// bindReportQuery 解析举报查询参数，状态默认为pending
+func bindReportQuery(c *gin.Context, req *models.AdminReportQuery) bool {
+	if err := c.ShouldBindQuery(req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return false
+	}
+	normalizePage(&req.Page, &req.PageSize)
+
+	switch req.Status {
+	case "":
+		req.Status = services.ReportPending
+	case services.ReportPending, services.ReportResolved, services.ReportDismissed:
+	default:
+		common.BadRequest(c, "status只能为pending/resolved/dismissed")
+		return false
+	}
+	if _, ok := services.ReportReasons[req.Reason]; req.Reason != "" && !ok {
+		common.BadRequest(c, services.ErrInvalidReportReason.Error())
+		return false
+	}
+	return true
+}
+
+// reportQuery 按状态和原因筛选举报
+func reportQuery(db *gorm.DB, req models.AdminReportQuery) *gorm.DB {
+	db = db.Model(&models.ResourceReport{}).Where("status = ?", req.Status)
+	if req.Reason != "" {
+		db = db.Where("reason = ?", req.Reason)
+	}
+	return db
+}
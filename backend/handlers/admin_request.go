@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"pan-search-api/common"
+	"pan-search-api/database"
+	"pan-search-api/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 日期参数格式
+const dateLayout = "2006-01-02"
+
+// helpRequestTransitions 求助请求允许的状态流转
+var helpRequestTransitions = map[string][]string{
+	"pending":    {"processing", "rejected"},
+	"processing": {"completed", "rejected"},
+}
+
+var errIllegalTransition = errors.New("illegal status transition")
+
+// AdminListHelpRequests 求助请求列表
+// @Summary 求助请求列表
+// @Description 管理端分页查询求助请求，支持按状态、资源类型、提交日期筛选
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param status query string false "状态 (pending/processing/completed/rejected)"
+// @Param resourceType query string false "资源类型"
+// @Param keyword query string false "资源名称关键词"
+// @Param startDate query string false "开始日期 (2006-01-02)"
+// @Param endDate query string false "结束日期 (2006-01-02)"
+// @Param page query int false "页码，默认1"
+// @Param pageSize query int false "每页数量，默认10"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=common.PaginatedResponse{list=[]models.HelpRequest}}
+// @Router /admin/help-requests [get]
+func AdminListHelpRequests(c *gin.Context) {
+	var req models.AdminHelpRequestQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+	normalizePage(&req.Page, &req.PageSize)
+
+	start, end, err := parseDateRange(req.StartDate, req.EndDate)
+	if err != nil {
+		common.BadRequest(c, err.Error())
+		return
+	}
+
+	db := database.DB.Model(&models.HelpRequest{})
+	if req.Status != "" {
+		db = db.Where("status = ?", req.Status)
+	}
+	if req.ResourceType != "" {
+		db = db.Where("resource_type = ?", req.ResourceType)
+	}
+	if req.Keyword != "" {
+		db = db.Where("resource_name LIKE ?", "%"+req.Keyword+"%")
+	}
+	if !start.IsZero() {
+		db = db.Where("created_at >= ?", start)
+	}
+	if !end.IsZero() {
+		db = db.Where("created_at < ?", end)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	var helpRequests []models.HelpRequest
+	if err := db.Order("created_at DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&helpRequests).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	common.SuccessWithPagination(c, helpRequests, req.Page, req.PageSize, int(total))
+}
+
+// AdminGetHelpRequest 求助请求详情
+// @Summary 求助请求详情
+// @Description 管理端查看求助请求详情
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "求助请求ID"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.HelpRequest}
+// @Router /admin/help-requests/{id} [get]
+func AdminGetHelpRequest(c *gin.Context) {
+	var helpRequest models.HelpRequest
+	if err := database.DB.First(&helpRequest, "id = ?", c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			common.NotFound(c, "求助请求不存在")
+		} else {
+			common.InternalServerError(c, "查询失败")
+		}
+		return
+	}
+
+	common.Success(c, helpRequest)
+}
+
+// AdminUpdateHelpRequest 处理求助请求
+// @Summary 处理求助请求
+// @Description 更新求助请求状态和管理员备注，状态只能按 pending→processing→completed/rejected 流转，pending 也可直接 rejected
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "求助请求ID"
+// @Param body body models.HelpRequestUpdate true "更新内容"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.HelpRequest}
+// @Router /admin/help-requests/{id} [put]
+func AdminUpdateHelpRequest(c *gin.Context) {
+	var req models.HelpRequestUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+	if req.Status == "" && req.AdminNotes == nil {
+		common.BadRequest(c, "没有需要更新的内容")
+		return
+	}
+
+	var helpRequest models.HelpRequest
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&helpRequest, "id = ?", c.Param("id")).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+		if req.Status != "" && req.Status != helpRequest.Status {
+			if !canTransitHelpRequest(helpRequest.Status, req.Status) {
+				return errIllegalTransition
+			}
+			updates["status"] = req.Status
+			if req.Status == "completed" || req.Status == "rejected" {
+				updates["completed_time"] = time.Now()
+			}
+		}
+		if req.AdminNotes != nil {
+			updates["admin_notes"] = *req.AdminNotes
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+
+		return tx.Model(&helpRequest).Updates(updates).Error
+	})
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		common.NotFound(c, "求助请求不存在")
+		return
+	case errors.Is(err, errIllegalTransition):
+		common.BadRequest(c, "不允许从 "+helpRequest.Status+" 变更为 "+req.Status)
+		return
+	case err != nil:
+		common.InternalServerError(c, "更新失败")
+		return
+	}
+
+	common.Success(c, helpRequest)
+}
+
+// canTransitHelpRequest 检查求助请求状态流转是否合法
+func canTransitHelpRequest(from, to string) bool {
+	for _, next := range helpRequestTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDateRange 解析日期范围，结束日期包含当天，返回的结束时间为次日零点
+func parseDateRange(startDate, endDate string) (time.Time, time.Time, error) {
+	var start, end time.Time
+	var err error
+	if startDate != "" {
+		if start, err = time.ParseInLocation(dateLayout, startDate, time.Local); err != nil {
+			return start, end, errors.New("开始日期格式错误，应为 YYYY-MM-DD")
+		}
+	}
+	if endDate != "" {
+		if end, err = time.ParseInLocation(dateLayout, endDate, time.Local); err != nil {
+			return start, end, errors.New("结束日期格式错误，应为 YYYY-MM-DD")
+		}
+		end = end.AddDate(0, 0, 1)
+	}
+	if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+		return start, end, errors.New("开始日期不能晚于结束日期")
+	}
+	return start, end, nil
+}
@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"errors"
+	"pan-search-api/common"
+	"pan-search-api/config"
+	"pan-search-api/database"
+	"pan-search-api/linkcheck"
+	"pan-search-api/models"
+	"pan-search-api/services"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminListResources 资源列表
+// @Summary 资源列表
+// @Description 管理端分页查询资源，包含已失效的资源，deleted=true时查询回收站
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param keyword query string false "标题关键词"
+// @Param categoryId query int false "分类ID"
+// @Param source query string false "来源平台"
+// @Param valid query bool false "是否有效"
+// @Param deleted query bool false "只查询已删除的资源"
+// @Param page query int false "页码，默认1"
+// @Param pageSize query int false "每页数量，默认10"
+// @Param cursor query string false "游标分页，第一页传空值，之后传上一页的nextCursor，传入时忽略page"
+// @Param estimateTotal query bool false "估算总数，结果超过10000条时不再精确统计"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=common.PaginatedResponse{list=[]models.Resource}}
+// @Router /admin/resources [get]
+func AdminListResources(c *gin.Context) {
+	var req models.AdminResourceQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+	normalizePage(&req.Page, &req.PageSize)
+
+	db := database.DB.Model(&models.Resource{})
+	if req.Deleted {
+		db = db.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+	if req.Keyword != "" {
+		db = db.Where("title LIKE ?", "%"+req.Keyword+"%")
+	}
+	if req.CategoryID != 0 {
+		db = db.Where("category_id = ?", req.CategoryID)
+	}
+	if req.Source != "" {
+		db = db.Where("source = ?", req.Source)
+	}
+	if req.Valid != nil {
+		db = db.Where("valid = ?", *req.Valid)
+	}
+
+	order := keysetOrder{
+		Columns: []keysetColumn{{Expr: "resources.created_at", Desc: true, Time: true}},
+		IDExpr:  "resources.id",
+	}
+
+	// 传入cursor参数时使用游标分页，第一页传空值
+	if _, ok := c.GetQuery("cursor"); ok {
+		valid := ""
+		if req.Valid != nil {
+			valid = strconv.FormatBool(*req.Valid)
+		}
+		scope := common.CursorScope("admin_resources", req.Keyword, strconv.FormatUint(uint64(req.CategoryID), 10),
+			req.Source, valid, strconv.FormatBool(req.Deleted))
+
+		pagination := common.CursorPagination{PageSize: req.PageSize}
+		if req.Cursor != "" {
+			cursor, err := common.DecodeCursor(req.Cursor, scope)
+			if err == nil {
+				db, err = order.after(db, cursor)
+			}
+			if err != nil {
+				common.BadRequest(c, err.Error())
+				return
+			}
+		} else {
+			total, estimated, err := countTotal(db, req.EstimateTotal)
+			if err != nil {
+				common.InternalServerError(c, "查询失败")
+				return
+			}
+			pagination.Total = &total
+			pagination.TotalEstimated = estimated
+		}
+
+		var resources []models.Resource
+		if err := order.apply(db.Preload("Category").Preload("Tags")).
+			Limit(req.PageSize + 1).
+			Find(&resources).Error; err != nil {
+			common.InternalServerError(c, "查询失败")
+			return
+		}
+		if len(resources) > req.PageSize {
+			resources = resources[:req.PageSize]
+			last := resources[len(resources)-1]
+			pagination.HasMore = true
+			pagination.NextCursor = common.EncodeCursor(common.Cursor{
+				Scope: scope,
+				Keys:  []int64{last.CreatedAt.UnixNano()},
+				ID:    last.ID,
+			})
+		}
+
+		common.SuccessWithCursor(c, resources, pagination)
+		return
+	}
+
+	total, estimated, err := countTotal(db, req.EstimateTotal)
+	if err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	var resources []models.Resource
+	if err := order.apply(db.Preload("Category").Preload("Tags")).
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&resources).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	pagination := common.NewPagination(req.Page, req.PageSize, int(total))
+	pagination.TotalEstimated = estimated
+	common.Success(c, common.PaginatedResponse{List: resources, Pagination: pagination})
+}
+
+// 即将过期列表最大的时间范围
+const maxExpiringWindow = 30 * 24 * time.Hour
+
+// AdminListExpiringResources 即将过期的资源
+// @Summary 即将过期的资源
+// @Description 查询在指定时间范围内过期的有效资源，按过期时间升序，便于在失效前更新链接
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param within query string false "时间范围，如 24h、72h，最大720h，默认72h"
+// @Param page query int false "页码，默认1"
+// @Param pageSize query int false "每页数量，默认10"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=common.PaginatedResponse{list=[]models.Resource}}
+// @Router /admin/resources/expiring [get]
+func AdminListExpiringResources(c *gin.Context) {
+	var req models.ExpiringResourceQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+	normalizePage(&req.Page, &req.PageSize)
+
+	within := config.GlobalConfig.Expiry.SoonWindow
+	if within <= 0 {
+		within = services.DefaultExpiringSoonWindow
+	}
+	if req.Within != "" {
+		d, err := time.ParseDuration(req.Within)
+		if err != nil || d <= 0 || d > maxExpiringWindow {
+			common.BadRequest(c, "within格式错误，如 24h，最大720h")
+			return
+		}
+		within = d
+	}
+
+	now := time.Now()
+	db := services.WhereVisible(database.DB.Model(&models.Resource{}), false).
+		Where("resources.expire_time <= ?", now.Add(within))
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	var resources []models.Resource
+	if err := db.Preload("Category").Preload("Tags").
+		Order("resources.expire_time ASC, resources.id ASC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&resources).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	common.SuccessWithPagination(c, resources, req.Page, req.PageSize, int(total))
+}
+
+// AdminGetResource 资源详情
+// @Summary 资源详情
+// @Description 管理端查看资源详情，包含已删除的资源
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "资源ID"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.Resource}
+// @Router /admin/resources/{id} [get]
+func AdminGetResource(c *gin.Context) {
+	resource, err := services.GetResource(database.DB, c.Param("id"), true)
+	respondResource(c, resource, err)
+}
+
+// AdminCreateResource 创建资源
+// @Summary 创建资源
+// @Description 创建资源及其标签，可识别的网盘分享链接（可直接粘贴分享文本）会转换为规范链接，并自动填写来源和提取码
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body models.ResourceCreate true "资源信息"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.Resource}
+// @Router /admin/resources [post]
+func AdminCreateResource(c *gin.Context) {
+	var req models.ResourceCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	resource, err := services.CreateResource(database.DB, req)
+	respondResource(c, resource, err)
+}
+
+// AdminUpdateResource 更新资源
+// @Summary 更新资源
+// @Description 更新资源信息，传入tags时整体替换标签。已因过期被标记失效的资源改为不过期时自动恢复有效
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "资源ID"
+// @Param body body models.ResourceUpdate true "资源信息"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.Resource}
+// @Failure 409 {object} common.Response "资源因其他原因失效，修改过期时间时需要同时传入valid"
+// @Router /admin/resources/{id} [put]
+func AdminUpdateResource(c *gin.Context) {
+	var req models.ResourceUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	resource, err := services.UpdateResource(database.DB, c.Param("id"), req)
+	respondResource(c, resource, err)
+}
+
+// AdminUpdateResourceValidity 修改资源有效状态
+// @Summary 修改资源有效状态
+// @Description 标记资源失效或恢复有效，搜索和热门推荐立即生效
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "资源ID"
+// @Param body body models.ResourceValidityUpdate true "有效状态"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.Resource}
+// @Router /admin/resources/{id}/validity [put]
+func AdminUpdateResourceValidity(c *gin.Context) {
+	var req models.ResourceValidityUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	resource, err := services.SetResourceValid(database.DB, c.Param("id"), *req.Valid)
+	respondResource(c, resource, err)
+}
+
+// AdminListLinkChecks 链接检测记录
+// @Summary 链接检测记录
+// @Description 查询资源分享链接最近的检测记录，按检测时间倒序
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "资源ID"
+// @Param limit query int false "返回数量，默认20，最大50"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=[]models.LinkCheck}
+// @Router /admin/resources/{id}/checks [get]
+func AdminListLinkChecks(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	checks, err := linkcheck.History(database.DB, c.Param("id"), limit)
+	if err != nil {
+		common.InternalServerError(c, "查询检测记录失败")
+		return
+	}
+
+	common.Success(c, checks)
+}
+
+// AdminCheckResource 立即检测链接
+// @Summary 立即检测链接
+// @Description 立即检测资源的分享链接并返回检测记录，连续失效达到阈值时资源被标记失效
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "资源ID"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.LinkCheck}
+// @Router /admin/resources/{id}/check [post]
+func AdminCheckResource(c *gin.Context) {
+	checker := linkcheck.Default()
+	if checker == nil {
+		common.InternalServerError(c, "链接检测未启动")
+		return
+	}
+
+	check, err := checker.CheckResource(c.Request.Context(), c.Param("id"))
+	switch {
+	case errors.Is(err, services.ErrResourceNotFound):
+		common.NotFound(c, err.Error())
+	case err != nil:
+		common.InternalServerError(c, "检测失败")
+	default:
+		common.Success(c, check)
+	}
+}
+
+// AdminDeleteResource 删除资源
+// @Summary 删除资源
+// @Description 软删除资源，可通过恢复接口还原
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "资源ID"
+// @Security BearerAuth
+// @Success 200 {object} common.Response
+// @Router /admin/resources/{id} [delete]
+func AdminDeleteResource(c *gin.Context) {
+	if err := services.DeleteResource(database.DB, c.Param("id")); err != nil {
+		respondResource(c, nil, err)
+		return
+	}
+
+	common.Success(c, nil)
+}
+
+// AdminRestoreResource 恢复资源
+// @Summary 恢复资源
+// @Description 恢复已删除的资源
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "资源ID"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.Resource}
+// @Router /admin/resources/{id}/restore [post]
+func AdminRestoreResource(c *gin.Context) {
+	resource, err := services.RestoreResource(database.DB, c.Param("id"))
+	respondResource(c, resource, err)
+}
+
+// respondResource 根据资源写入结果返回响应
+func respondResource(c *gin.Context, resource *models.Resource, err error) {
+	switch {
+	case errors.Is(err, services.ErrResourceNotFound):
+		common.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrCategoryNotFound), errors.Is(err, services.ErrSourceRequired):
+		common.BadRequest(c, err.Error())
+	case errors.Is(err, services.ErrResourceInvalid):
+		common.Conflict(c, err.Error())
+	case err != nil:
+		common.InternalServerError(c, "操作失败")
+	default:
+		common.Success(c, resource)
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"pan-search-api/common"
+	"pan-search-api/database"
+	"pan-search-api/middleware"
+	"pan-search-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminListRoles 角色列表
+// @Summary 角色列表
+// @Description 获取全部角色及其权限
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=[]models.RoleResponse}
+// @Router /admin/roles [get]
+func AdminListRoles(c *gin.Context) {
+	var roles []models.Role
+	if err := database.DB.Preload("Permissions").Order("id ASC").Find(&roles).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	roleList := make([]models.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		permissions := make([]string, len(role.Permissions))
+		for i, permission := range role.Permissions {
+			permissions[i] = permission.Code
+		}
+
+		roleList = append(roleList, models.RoleResponse{
+			Name:        role.Name,
+			Label:       role.Label,
+			Description: role.Description,
+			IsSystem:    role.IsSystem,
+			Permissions: permissions,
+		})
+	}
+
+	var permissions []models.Permission
+	if err := database.DB.Order("id ASC").Find(&permissions).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	common.Success(c, map[string]interface{}{
+		"list":        roleList,
+		"permissions": permissions,
+	})
+}
+
+// AdminUpdateRolePermissions 修改角色权限
+// @Summary 修改角色权限
+// @Description 使用给定的权限列表替换角色现有权限
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param name path string true "角色名"
+// @Param body body models.RolePermissionsUpdate true "权限编码列表"
+// @Security BearerAuth
+// @Success 200 {object} common.Response
+// @Router /admin/roles/{name}/permissions [put]
+func AdminUpdateRolePermissions(c *gin.Context) {
+	var req models.RolePermissionsUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	var role models.Role
+	if err := database.DB.First(&role, "name = ?", c.Param("name")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			common.NotFound(c, "角色不存在")
+		} else {
+			common.InternalServerError(c, "查询失败")
+		}
+		return
+	}
+	// 超级管理员权限固定，防止误操作导致无人可管理
+	if role.Name == "admin" {
+		common.Forbidden(c, "不能修改管理员角色的权限")
+		return
+	}
+
+	var permissions []models.Permission
+	if len(req.Permissions) > 0 {
+		if err := database.DB.Where("code IN ?", req.Permissions).Find(&permissions).Error; err != nil {
+			common.InternalServerError(c, "查询失败")
+			return
+		}
+	}
+	if len(permissions) != len(uniqueStrings(req.Permissions)) {
+		common.BadRequest(c, "包含不存在的权限")
+		return
+	}
+
+	if err := database.DB.Model(&role).Association("Permissions").Replace(permissions); err != nil {
+		common.InternalServerError(c, "更新失败")
+		return
+	}
+
+	middleware.InvalidatePermissionCache()
+	common.Success(c, nil)
+}
+
+// uniqueStrings 去重并保持顺序
+func uniqueStrings(items []string) []string {
+	seen := make(map[string]struct{}, len(items))
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if _, ok := seen[item]; ok {
+			continue
+		}
+		seen[item] = struct{}{}
+		result = append(result, item)
+	}
+	return result
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"errors"
+	"pan-search-api/common"
+	"pan-search-api/database"
+	"pan-search-api/middleware"
+	"pan-search-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminListUsers 用户列表
+// @Summary 用户列表
+// @Description 管理端分页查询用户，支持按关键词、角色、状态筛选
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param keyword query string false "用户名/邮箱/手机号"
+// @Param role query string false "角色"
+// @Param status query string false "状态 (active/inactive/banned)"
+// @Param page query int false "页码，默认1"
+// @Param pageSize query int false "每页数量，默认10"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=common.PaginatedResponse{list=[]models.UserProfileResponse}}
+// @Router /admin/users [get]
+func AdminListUsers(c *gin.Context) {
+	var req models.AdminUserQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+	normalizePage(&req.Page, &req.PageSize)
+
+	db := database.DB.Model(&models.User{})
+	if req.Keyword != "" {
+		like := "%" + req.Keyword + "%"
+		db = db.Where("username LIKE ? OR email LIKE ? OR phone LIKE ?", like, like, like)
+	}
+	if req.Role != "" {
+		db = db.Where("role = ?", req.Role)
+	}
+	if req.Status != "" {
+		db = db.Where("status = ?", req.Status)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	var users []models.User
+	if err := db.Order("created_at DESC").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&users).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	userList := make([]models.UserProfileResponse, 0, len(users))
+	for i := range users {
+		userList = append(userList, toUserProfile(&users[i]))
+	}
+
+	common.SuccessWithPagination(c, userList, req.Page, req.PageSize, int(total))
+}
+
+// AdminUpdateUserRole 修改用户角色
+// @Summary 修改用户角色
+// @Description 修改用户角色，角色必须已存在
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "用户ID"
+// @Param body body models.UserRoleUpdate true "角色"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.UserProfileResponse}
+// @Router /admin/users/{id}/role [put]
+func AdminUpdateUserRole(c *gin.Context) {
+	var req models.UserRoleUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.Role{}).Where("name = ?", req.Role).Count(&count).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+	if count == 0 {
+		common.BadRequest(c, "角色不存在")
+		return
+	}
+
+	if c.Param("id") == middleware.GetUserID(c) {
+		common.BadRequest(c, "不能修改自己的角色")
+		return
+	}
+
+	updateUser(c, map[string]interface{}{"role": req.Role})
+}
+
+// AdminUpdateUserStatus 修改用户状态
+// @Summary 修改用户状态
+// @Description 启用、停用或封禁用户，停用和封禁会同时吊销该用户的全部会话。只有能管理角色的用户才能修改管理员的状态
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "用户ID"
+// @Param body body models.UserStatusUpdate true "状态"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.UserProfileResponse}
+// @Router /admin/users/{id}/status [put]
+func AdminUpdateUserStatus(c *gin.Context) {
+	var req models.UserStatusUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	if c.Param("id") == middleware.GetUserID(c) {
+		common.BadRequest(c, "不能修改自己的状态")
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			common.NotFound(c, "用户不存在")
+		} else {
+			common.InternalServerError(c, "查询失败")
+		}
+		return
+	}
+
+	// 拥有user:manage的审核员不能停用或封禁管理员，否则管理员会因会话被吊销而无法登录
+	targetPrivileged, err := isPrivilegedRole(user.Role)
+	if err != nil {
+		common.InternalServerError(c, "权限校验失败")
+		return
+	}
+	if targetPrivileged {
+		callerPrivileged, err := isPrivilegedRole(c.GetString(middleware.ContextRole))
+		if err != nil {
+			common.InternalServerError(c, "权限校验失败")
+			return
+		}
+		if !callerPrivileged {
+			common.Forbidden(c, "不能修改管理员的状态")
+			return
+		}
+	}
+
+	// 状态和会话吊销在同一事务中，吊销失败时不修改状态
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("status", req.Status).Error; err != nil {
+			return err
+		}
+		if req.Status != "active" {
+			if _, err := revokeUserSessions(tx, user.ID, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		common.InternalServerError(c, "更新失败")
+		return
+	}
+
+	common.Success(c, toUserProfile(&user))
+}
+
+// isPrivilegedRole 角色是否拥有全部权限或角色管理权限
+func isPrivilegedRole(role string) (bool, error) {
+	return middleware.HasPermission(role, middleware.PermRoleManage)
+}
+
+// updateUser 更新用户字段并返回最新用户信息
+func updateUser(c *gin.Context, updates map[string]interface{}) bool {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			common.NotFound(c, "用户不存在")
+		} else {
+			common.InternalServerError(c, "查询失败")
+		}
+		return false
+	}
+
+	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+		common.InternalServerError(c, "更新失败")
+		return false
+	}
+
+	common.Success(c, toUserProfile(&user))
+	return true
+}
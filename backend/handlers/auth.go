@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"errors"
+	"pan-search-api/common"
+	"pan-search-api/database"
+	"pan-search-api/models"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-sql-driver/mysql"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// 手机号格式
+var phoneRegexp = regexp.MustCompile(`^1[3-9]\d{9}$`)
+
+// 用于账号不存在时执行一次等价的哈希比较，避免通过响应时间枚举账号
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("pan-search-dummy-password"), bcrypt.DefaultCost)
+
+// Register 用户注册
+// @Summary 用户注册
+// @Description 注册新用户，用户名、邮箱、手机号不可重复
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.RegisterRequest true "注册信息"
+// @Success 200 {object} common.Response{data=models.LoginResponse}
+// @Router /auth/register [post]
+func Register(c *gin.Context) {
+	var req models.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	req.Username = strings.TrimSpace(req.Username)
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	req.Phone = strings.TrimSpace(req.Phone)
+	if req.Phone != "" && !phoneRegexp.MatchString(req.Phone) {
+		common.BadRequest(c, "手机号格式错误")
+		return
+	}
+	// 用户名不能与邮箱、手机号混淆，否则登录时无法区分账号
+	if strings.Contains(req.Username, "@") || phoneRegexp.MatchString(req.Username) {
+		common.BadRequest(c, "用户名不能是邮箱或手机号")
+		return
+	}
+
+	if msg := checkUserUnique(database.DB, "", req.Username, req.Email, req.Phone); msg != "" {
+		common.Conflict(c, msg)
+		return
+	}
+
+	passwordHash, err := hashPassword(req.Password)
+	if err != nil {
+		common.InternalServerError(c, "注册失败")
+		return
+	}
+
+	now := time.Now()
+	user := models.User{
+		ID:           common.GenerateID(),
+		Username:     req.Username,
+		Email:        req.Email,
+		PasswordHash: passwordHash,
+		Role:         "user",
+		Status:       "active",
+		LastLoginAt:  now,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if req.Phone != "" {
+		user.Phone = &req.Phone
+	}
+
+	if err := database.DB.Create(&user).Error; err != nil {
+		if isDuplicateKeyError(err) {
+			common.Conflict(c, "用户名、邮箱或手机号已被注册")
+		} else {
+			common.InternalServerError(c, "注册失败")
+		}
+		return
+	}
+
+	respondLogin(c, &user)
+}
+
+// Login 用户登录
+// @Summary 用户登录
+// @Description 使用用户名、邮箱或手机号登录
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.LoginRequest true "登录信息"
+// @Success 200 {object} common.Response{data=models.LoginResponse}
+// @Router /auth/login [post]
+func Login(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	account := strings.TrimSpace(req.Account)
+	var user models.User
+	err := database.DB.Where("username = ? OR email = ? OR phone = ?", account, strings.ToLower(account), account).
+		First(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	if err != nil {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(req.Password))
+		common.Unauthorized(c, "用户名或密码错误")
+		return
+	}
+	if !checkPassword(user.PasswordHash, req.Password) {
+		common.Unauthorized(c, "用户名或密码错误")
+		return
+	}
+
+	if msg := userStatusError(user.Status); msg != "" {
+		common.Forbidden(c, msg)
+		return
+	}
+
+	user.LastLoginAt = time.Now()
+	if err := database.DB.Model(&user).Update("last_login_at", user.LastLoginAt).Error; err != nil {
+		common.InternalServerError(c, "登录失败")
+		return
+	}
+
+	respondLogin(c, &user)
+}
+
+// userStatusError 返回用户状态不允许登录的原因
+func userStatusError(status string) string {
+	switch status {
+	case "banned":
+		return "账号已被封禁"
+	case "inactive":
+		return "账号未激活"
+	}
+	return ""
+}
+
+// checkUserUnique 检查用户名、邮箱、手机号是否已被其他用户占用，返回冲突提示
+func checkUserUnique(db *gorm.DB, excludeID, username, email, phone string) string {
+	checks := []struct {
+		column  string
+		value   string
+		message string
+	}{
+		{"username", username, "用户名已被注册"},
+		{"email", email, "邮箱已被注册"},
+		{"phone", phone, "手机号已被注册"},
+	}
+
+	for _, check := range checks {
+		if check.value == "" {
+			continue
+		}
+		query := db.Model(&models.User{}).Where(check.column+" = ?", check.value)
+		if excludeID != "" {
+			query = query.Where("id <> ?", excludeID)
+		}
+		var count int64
+		if err := query.Count(&count).Error; err == nil && count > 0 {
+			return check.message
+		}
+	}
+	return ""
+}
+
+// hashPassword 生成加盐密码哈希
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// checkPassword 校验密码
+func checkPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// isDuplicateKeyError 判断是否为唯一索引冲突
+func isDuplicateKeyError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
+
+// toUserProfile 转换为用户信息响应
+func toUserProfile(user *models.User) models.UserProfileResponse {
+	profile := models.UserProfileResponse{
+		ID:          user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		Avatar:      user.Avatar,
+		Role:        user.Role,
+		Status:      user.Status,
+		LastLoginAt: user.LastLoginAt,
+		CreatedAt:   user.CreatedAt,
+	}
+	if user.Phone != nil {
+		profile.Phone = *user.Phone
+	}
+	return profile
+}
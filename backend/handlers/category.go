@@ -4,13 +4,14 @@ import (
 	"pan-search-api/common"
 	"pan-search-api/database"
 	"pan-search-api/models"
+	"pan-search-api/services"
 
 	"github.com/gin-gonic/gin"
 )
 
 // GetCategories 获取分类列表
 // @Summary 获取分类列表
-// @Description 获取所有资源分类
+// @Description 获取资源分类树，第一项为全部分类，资源数量包含子分类
 // @Tags categories
 // @Accept json
 // @Produce json
@@ -18,30 +19,65 @@ import (
 // @Router /categories [get]
 func GetCategories(c *gin.Context) {
 	var categories []models.Category
-	if err := database.DB.Where("is_active = ?", true).
-		Order("sort_order ASC").
-		Find(&categories).Error; err != nil {
+	if err := database.DB.Order("sort_order ASC").Find(&categories).Error; err != nil {
 		common.InternalServerError(c, "查询失败")
 		return
 	}
 
-	// 获取每个分类的资源数量
-	var categoryList []models.CategoryResponse
-	for _, category := range categories {
-		var count int64
-		database.DB.Model(&models.Resource{}).
-			Where("category_id = ? AND valid = ?", category.ID, true).
-			Count(&count)
-
-		categoryList = append(categoryList, models.CategoryResponse{
-			Value: category.Value,
-			Label: category.Label,
-			Count: int(count),
-			Icon:  category.Icon,
-		})
+	// 一次查询获取每个分类的资源数量
+	var counts []struct {
+		CategoryID uint
+		Count      int
+	}
+	if err := services.WhereVisible(database.DB.Model(&models.Resource{}), false).
+		Select("category_id, COUNT(*) AS count").
+		Group("category_id").
+		Scan(&counts).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+	countMap := make(map[uint]int, len(counts))
+	total := 0
+	for _, row := range counts {
+		countMap[row.CategoryID] = row.Count
+		total += row.Count
 	}
 
+	categoryList := []models.CategoryResponse{{
+		Value: services.CategoryAll,
+		Label: "全部",
+		Count: total,
+		Icon:  "📁",
+	}}
+	categoryList = append(categoryList, toCategoryResponses(services.BuildCategoryTree(categories), countMap)...)
+
 	common.Success(c, map[string]interface{}{
 		"list": categoryList,
 	})
-}
\ No newline at end of file
+}
+
+// toCategoryResponses 转换分类树，跳过停用的分类及其子分类
+func toCategoryResponses(nodes []*services.CategoryNode, countMap map[uint]int) []models.CategoryResponse {
+	var result []models.CategoryResponse
+	for _, node := range nodes {
+		// 旧数据中可能仍存有all分类，由接口统一生成
+		if !node.IsActive || node.Value == services.CategoryAll {
+			continue
+		}
+
+		children := toCategoryResponses(node.Children, countMap)
+		count := countMap[node.ID]
+		for _, child := range children {
+			count += child.Count
+		}
+
+		result = append(result, models.CategoryResponse{
+			Value:    node.Value,
+			Label:    node.Label,
+			Count:    count,
+			Icon:     node.Icon,
+			Children: children,
+		})
+	}
+	return result
+}
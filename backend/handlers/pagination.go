@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"pan-search-api/common"
+	"pan-search-api/database"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 分页默认值
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+// normalizePage 校正分页参数
+func normalizePage(page, pageSize *int) {
+	if *page <= 0 {
+		*page = 1
+	}
+	if *pageSize <= 0 {
+		*pageSize = defaultPageSize
+	}
+	if *pageSize > maxPageSize {
+		*pageSize = maxPageSize
+	}
+}
+
+// 估算总数时最多统计的行数，超过时返回该值并标记为估算
+const maxCountedTotal = 10000
+
+// keysetColumn 游标分页的排序字段
+type keysetColumn struct {
+	Expr string        // SQL表达式
+	Vars []interface{} // 表达式中的参数
+	Desc bool
+	Time bool // 值为时间，游标中保存Unix纳秒
+}
+
+// keysetOrder 游标分页的排序方式，排序字段相同时按IDExpr升序，保证顺序唯一
+type keysetOrder struct {
+	Columns []keysetColumn
+	IDExpr  string
+}
+
+// apply 添加排序子句，页码分页也使用同样的排序，保证两种分页方式结果一致
+func (o keysetOrder) apply(db *gorm.DB) *gorm.DB {
+	parts := make([]string, 0, len(o.Columns)+1)
+	var vars []interface{}
+	for _, col := range o.Columns {
+		if col.Desc {
+			parts = append(parts, col.Expr+" DESC")
+		} else {
+			parts = append(parts, col.Expr)
+		}
+		vars = append(vars, col.Vars...)
+	}
+	parts = append(parts, o.IDExpr)
+
+	return db.Clauses(clause.OrderBy{Expression: clause.Expr{
+		SQL:                strings.Join(parts, ", "),
+		Vars:               vars,
+		WithoutParentheses: true,
+	}})
+}
+
+// after 筛选排在游标之后的记录：(a, b, id) 依次比较，前面的字段相等时比较下一个字段
+func (o keysetOrder) after(db *gorm.DB, cursor *common.Cursor) (*gorm.DB, error) {
+	if len(cursor.Keys) != len(o.Columns) {
+		return nil, common.ErrInvalidCursor
+	}
+
+	var conditions []string
+	var args []interface{}
+	var prefix []string
+	var prefixArgs []interface{}
+	for i, col := range o.Columns {
+		op := " > ?"
+		if col.Desc {
+			op = " < ?"
+		}
+		value := keysetValue(col, cursor.Keys[i])
+		conditions = append(conditions, "("+strings.Join(append(append([]string{}, prefix...), col.Expr+op), " AND ")+")")
+		args = append(append(append(args, prefixArgs...), col.Vars...), value)
+
+		prefix = append(prefix, col.Expr+" = ?")
+		prefixArgs = append(append(prefixArgs, col.Vars...), value)
+	}
+	conditions = append(conditions, "("+strings.Join(append(prefix, o.IDExpr+" > ?"), " AND ")+")")
+	args = append(append(args, prefixArgs...), cursor.ID)
+
+	// 切片参数需要展开为逗号分隔的列表，如FIELD(resources.id, ?)
+	return db.Where(clause.Expr{
+		SQL:                "(" + strings.Join(conditions, " OR ") + ")",
+		Vars:               args,
+		WithoutParentheses: true,
+	}), nil
+}
+
+// keysetValue 将游标中的值转换为查询参数
+func keysetValue(col keysetColumn, key int64) interface{} {
+	if col.Time {
+		return time.Unix(0, key)
+	}
+	return key
+}
+
+// countTotal 统计总数，estimate为true时最多统计maxCountedTotal行，超过时返回的总数为估算值
+func countTotal(db *gorm.DB, estimate bool) (total int64, estimated bool, err error) {
+	if !estimate {
+		err = db.Count(&total).Error
+		return total, false, err
+	}
+
+	sub := db.Session(&gorm.Session{}).Select("1").Limit(maxCountedTotal)
+	if err = database.DB.Table("(?) AS t", sub).Count(&total).Error; err != nil {
+		return 0, false, err
+	}
+	return total, total >= maxCountedTotal, nil
+}
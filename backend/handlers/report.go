@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"pan-search-api/common"
+	"pan-search-api/config"
+	"pan-search-api/database"
+	"pan-search-api/middleware"
+	"pan-search-api/models"
+	"pan-search-api/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportResource 举报资源
+// @Summary 举报资源
+// @Description 举报链接失效、提取码错误、分类错误或违规内容，同一IP或用户的举报次数受限，多个用户举报失效的资源会被自动隐藏
+// @Tags resources
+// @Accept json
+// @Produce json
+// @Param id path string true "资源ID"
+// @Param body body models.ReportCreate true "举报信息，reason为dead_link/wrong_code/wrong_category/illegal/other"
+// @Success 200 {object} common.Response{data=models.ReportResponse}
+// @Failure 429 {object} common.Response "举报过于频繁"
+// @Router /resources/{id}/report [post]
+func ReportResource(c *gin.Context) {
+	var req models.ReportCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	reporter := services.Reporter{UserID: middleware.GetUserID(c), IP: c.ClientIP()}
+	report, err := services.CreateReport(database.DB, config.GlobalConfig.Report, c.Param("id"), reporter, req)
+	switch {
+	case errors.Is(err, services.ErrResourceNotFound):
+		common.NotFound(c, err.Error())
+	case errors.Is(err, services.ErrInvalidReportReason):
+		common.BadRequest(c, err.Error())
+	case errors.Is(err, services.ErrDuplicateReport):
+		common.Conflict(c, err.Error())
+	case errors.Is(err, services.ErrReportRateLimited):
+		common.Error(c, http.StatusTooManyRequests, err.Error())
+	case err != nil:
+		common.InternalServerError(c, "举报失败")
+	default:
+		common.Success(c, models.ReportResponse{ReportID: report.ID, Status: report.Status})
+	}
+}
@@ -1,13 +1,15 @@
 package handlers
 
 import (
+	"errors"
 	"pan-search-api/common"
 	"pan-search-api/database"
 	"pan-search-api/models"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // SubmitHelpRequest 提交资源求助
@@ -26,17 +28,19 @@ func SubmitHelpRequest(c *gin.Context) {
 		return
 	}
 
-	// 创建求助请求
+	// 创建求助请求，返回给提交者的查询凭证只保存哈希
+	accessToken := newSecret()
 	helpRequest := models.HelpRequest{
-		ID:           generateRequestID(),
-		ResourceName: req.ResourceName,
-		ResourceType: req.ResourceType,
-		Description:  req.Description,
-		Contact:      req.Contact,
-		ContactType:  req.ContactType,
-		Status:       "pending",
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		ID:              generateRequestID(),
+		AccessTokenHash: hashToken(accessToken),
+		ResourceName:    req.ResourceName,
+		ResourceType:    req.ResourceType,
+		Description:     req.Description,
+		Contact:         req.Contact,
+		ContactType:     req.ContactType,
+		Status:          "pending",
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 
 	if err := database.DB.Create(&helpRequest).Error; err != nil {
@@ -46,6 +50,7 @@ func SubmitHelpRequest(c *gin.Context) {
 
 	response := models.HelpRequestResponse{
 		RequestID:           helpRequest.ID,
+		AccessToken:         accessToken,
 		Status:              helpRequest.Status,
 		SubmitTime:          helpRequest.CreatedAt,
 		EstimatedProcessTime: "1-3个工作日",
@@ -54,7 +59,79 @@ func SubmitHelpRequest(c *gin.Context) {
 	common.Success(c, response)
 }
 
-// 生成求助请求ID
+// GetHelpRequestStatus 查询求助请求处理状态
+// @Summary 查询求助请求处理状态
+// @Description 凭提交时返回的accessToken或提交时填写的联系方式查询处理状态，不返回联系方式
+// @Tags requests
+// @Accept json
+// @Produce json
+// @Param id path string true "求助请求ID"
+// @Param token query string false "提交时返回的accessToken"
+// @Param contact query string false "提交时填写的联系方式"
+// @Success 200 {object} common.Response{data=models.HelpRequestStatusResponse}
+// @Router /requests/{id} [get]
+func GetHelpRequestStatus(c *gin.Context) {
+	var req models.HelpRequestStatusQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+	if req.Token == "" && req.Contact == "" {
+		common.BadRequest(c, "请提供查询凭证或联系方式")
+		return
+	}
+
+	var helpRequest models.HelpRequest
+	if err := database.DB.First(&helpRequest, "id = ?", c.Param("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			common.NotFound(c, "求助请求不存在或凭证错误")
+		} else {
+			common.InternalServerError(c, "查询失败")
+		}
+		return
+	}
+
+	// 凭证错误与请求不存在返回相同结果，避免探测请求ID
+	if !verifyHelpRequestAccess(&helpRequest, req) {
+		common.NotFound(c, "求助请求不存在或凭证错误")
+		return
+	}
+
+	response := models.HelpRequestStatusResponse{
+		RequestID:    helpRequest.ID,
+		ResourceName: helpRequest.ResourceName,
+		ResourceType: helpRequest.ResourceType,
+		Status:       helpRequest.Status,
+		AdminNotes:   helpRequest.AdminNotes,
+		SubmitTime:   helpRequest.CreatedAt,
+		UpdatedTime:  helpRequest.UpdatedAt,
+	}
+	if !helpRequest.CompletedTime.IsZero() {
+		response.CompletedTime = &helpRequest.CompletedTime
+	}
+
+	common.Success(c, response)
+}
+
+// verifyHelpRequestAccess 校验查询凭证或联系方式
+func verifyHelpRequestAccess(helpRequest *models.HelpRequest, req models.HelpRequestStatusQuery) bool {
+	if req.Token != "" && helpRequest.AccessTokenHash != "" {
+		if secureEqual(hashToken(req.Token), helpRequest.AccessTokenHash) {
+			return true
+		}
+	}
+	if req.Contact != "" {
+		return secureEqual(normalizeContact(req.Contact), normalizeContact(helpRequest.Contact))
+	}
+	return false
+}
+
+// normalizeContact 规范化联系方式，邮箱不区分大小写
+func normalizeContact(contact string) string {
+	return strings.ToLower(strings.TrimSpace(contact))
+}
+
+// 生成求助请求ID，使用随机ID避免被枚举
 func generateRequestID() string {
-	return "req_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	return common.GenerateID()
 }
\ No newline at end of file
@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"pan-search-api/common"
 	"pan-search-api/database"
+	"pan-search-api/middleware"
 	"pan-search-api/models"
+	"pan-search-api/search"
+	"pan-search-api/services"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -18,12 +21,24 @@ import (
 // @Tags resources
 // @Accept json
 // @Produce json
-// @Param q query string true "搜索关键词"
-// @Param category query string false "资源分类 (all/movie/tv/music/software/document/other)"
+// @Param q query string true "搜索关键词，支持 \"短语\"、-排除、a OR b、(a OR b) 和 source:/type:/tag:/size:>10GB/after:2024-01-01/before: 限定"
+// @Param category query string false "资源分类 (all/movie/tv/music/software/document/other)，多个用逗号分隔"
+// @Param source query string false "来源，多个用逗号分隔，如 aliyun,quark"
+// @Param type query string false "文件类型，多个用逗号分隔"
+// @Param tag query string false "标签，多个用逗号分隔"
+// @Param year query string false "上传年份，多个用逗号分隔"
+// @Param facets query string false "返回分面统计 (category,source,type,tag,year 或 all)"
+// @Param highlight query bool false "返回高亮的标题和描述摘要"
 // @Param sort query string false "排序方式 (relevance/time/size)"
+// @Param minSize query string false "最小文件大小，如 700MB、1.5GB，不带单位时按字节"
+// @Param maxSize query string false "最大文件大小，如 10GB"
 // @Param page query int false "页码，默认1"
-// @Param pageSize query int false "每页数量，默认10"
-// @Success 200 {object} common.Response{data=common.PaginatedResponse{list=[]models.ResourceResponse}}
+// @Param pageSize query int false "每页数量，默认10，最大100"
+// @Param cursor query string false "游标分页，第一页传空值，之后传上一页的nextCursor，传入时忽略page"
+// @Param estimateTotal query bool false "估算总数，结果超过10000条时不再精确统计"
+// @Param includeExpired query bool false "包含已过期的资源，需要管理员权限"
+// @Success 200 {object} common.Response{data=models.SearchResponse}
+// @Failure 400 {object} common.Response{data=search.QueryError} "查询语法错误"
 // @Router /resources/search [get]
 func SearchResources(c *gin.Context) {
 	var req models.SearchRequest
@@ -33,64 +48,157 @@ func SearchResources(c *gin.Context) {
 	}
 
 	// 设置默认值
-	if req.Page <= 0 {
-		req.Page = 1
+	normalizePage(&req.Page, &req.PageSize)
+	if req.Sort != "time" && req.Sort != "size" {
+		req.Sort = "relevance"
 	}
-	if req.PageSize <= 0 {
-		req.PageSize = 10
+	minSize, maxSize, err := parseSizeRange(req.MinSize, req.MaxSize)
+	if err != nil {
+		common.BadRequest(c, err.Error())
+		return
 	}
-	if req.Sort == "" {
-		req.Sort = "relevance"
+	facetNames, err := parseFacetNames(req.Facets)
+	if err != nil {
+		common.BadRequest(c, err.Error())
+		return
+	}
+	if req.IncludeExpired {
+		ok, err := middleware.HasPermission(c.GetString(middleware.ContextRole), middleware.PermAdminAccess)
+		if err != nil {
+			common.InternalServerError(c, "权限校验失败")
+			return
+		}
+		if !ok {
+			common.Forbidden(c, "includeExpired需要管理员权限")
+			return
+		}
+	}
+
+	// 传入cursor参数时使用游标分页，第一页传空值，之后传上一页返回的nextCursor
+	_, useCursor := c.GetQuery("cursor")
+	scope := common.CursorScope(req.Q, req.Category, req.Source, req.Type, req.Tag, req.Year,
+		req.Sort, req.MinSize, req.MaxSize, strconv.FormatBool(req.IncludeExpired))
+	var cursor *common.Cursor
+	if req.Cursor != "" {
+		if cursor, err = common.DecodeCursor(req.Cursor, scope); err != nil {
+			common.BadRequest(c, err.Error())
+			return
+		}
 	}
 
-	// 构建查询
-	db := database.DB.Model(&models.Resource{}).
-		Preload("Category").
-		Preload("Tags").
-		Where("valid = ?", true)
+	// 构建查询，默认不返回已过期的资源
+	db := services.WhereVisible(database.DB.Model(&models.Resource{}), req.IncludeExpired)
 
-	// 关键词搜索
-	if req.Q != "" {
-		keywords := strings.Split(req.Q, " ")
-		for _, keyword := range keywords {
-			if keyword != "" {
-				db = db.Where("title LIKE ? OR description LIKE ?", "%"+keyword+"%", "%"+keyword+"%")
-			}
+	// 解析查询语法，关键词由搜索引擎返回按相关度排序的候选资源ID，拼音匹配的结果排在最后
+	query, err := search.ParseQuery(req.Q)
+	if err != nil {
+		var queryErr *search.QueryError
+		if errors.As(err, &queryErr) {
+			common.BadRequestWithData(c, "查询语法错误: "+queryErr.Message, queryErr)
+			return
 		}
+		common.BadRequest(c, "查询语法错误")
+		return
+	}
+	db, hitIDs, truncated, err := applySearchQuery(db, query)
+	if err != nil {
+		common.InternalServerError(c, "搜索失败")
+		return
+	}
+
+	// 文件大小筛选，大小未知的资源不参与筛选
+	if minSize != nil {
+		db = db.Where("resources.size_bytes >= ?", *minSize)
+	}
+	if maxSize != nil {
+		db = db.Where("resources.size_bytes <= ?", *maxSize)
 	}
 
-	// 分类筛选
-	if req.Category != "" && req.Category != "all" {
-		db = db.Joins("JOIN categories ON resources.category_id = categories.id").
-			Where("categories.value = ?", req.Category)
+	// 分类、来源、类型、标签、年份的多选筛选，分面统计时需要分别排除
+	filters, err := buildFacetFilters(req)
+	if err != nil {
+		common.BadRequest(c, err.Error())
+		return
 	}
+	// 以当前条件创建可复用的查询，列表和各分面统计在此基础上分别追加条件
+	base := db.Session(&gorm.Session{})
+	db = applyFacetFilters(base, filters, "")
 
-	// 排序
-	switch req.Sort {
-	case "time":
-		db = db.Order("upload_time DESC")
-	case "size":
-		// 这里需要将size转换为数字进行排序，暂时按字符串排序
-		db = db.Order("size DESC")
-	default: // relevance
-		db = db.Order("view_count DESC, download_count DESC")
+	// 游标分页沿用第一页的候选顺序，快照过期时使用本次的检索结果
+	snapshot := int64(0)
+	if cursor != nil {
+		snapshot = cursor.Snapshot
+		if ids, ok := loadHitSnapshot(hitSnapshotKey(cursor)); ok {
+			hitIDs = ids
+		}
+	} else if useCursor && req.Sort == "relevance" && len(hitIDs) > 0 {
+		snapshot = time.Now().UnixNano()
+		saveHitSnapshot(hitSnapshotKey(&common.Cursor{Scope: scope, Snapshot: snapshot}), hitIDs)
 	}
+	order := searchOrder(req.Sort, hitIDs, useCursor)
 
-	// 获取总数
+	// 获取总数，游标分页只在第一页统计
 	var total int64
-	if err := db.Count(&total).Error; err != nil {
-		common.InternalServerError(c, "查询失败")
-		return
+	var estimated bool
+	if cursor == nil {
+		total, estimated, err = countTotal(db, req.EstimateTotal)
+		if err != nil {
+			common.InternalServerError(c, "查询失败")
+			return
+		}
 	}
 
-	// 分页查询
-	offset := (req.Page - 1) * req.PageSize
+	db = order.apply(db.Preload("Category").Preload("Tags"))
 	var resources []models.Resource
-	if err := db.Offset(offset).Limit(req.PageSize).Find(&resources).Error; err != nil {
+	if useCursor {
+		// 多查询一条判断是否还有下一页
+		if cursor != nil {
+			if db, err = order.after(db, cursor); err != nil {
+				common.BadRequest(c, err.Error())
+				return
+			}
+		}
+		err = db.Limit(req.PageSize + 1).Find(&resources).Error
+	} else {
+		offset := (req.Page - 1) * req.PageSize
+		err = db.Offset(offset).Limit(req.PageSize).Find(&resources).Error
+	}
+	if err != nil {
 		common.InternalServerError(c, "查询失败")
 		return
 	}
 
+	var pagination interface{}
+	if useCursor {
+		cursorPagination := common.CursorPagination{PageSize: req.PageSize}
+		if len(resources) > req.PageSize {
+			resources = resources[:req.PageSize]
+			last := &resources[len(resources)-1]
+			cursorPagination.HasMore = true
+			cursorPagination.NextCursor = common.EncodeCursor(common.Cursor{
+				Scope:    scope,
+				Keys:     searchSortKeys(req.Sort, hitIDs, last),
+				ID:       last.ID,
+				Snapshot: snapshot,
+			})
+		}
+		if cursor == nil {
+			cursorPagination.Total = &total
+			cursorPagination.TotalEstimated = estimated
+		}
+		pagination = cursorPagination
+	} else {
+		pagePagination := common.NewPagination(req.Page, req.PageSize, int(total))
+		pagePagination.TotalEstimated = estimated
+		pagination = pagePagination
+	}
+
+	// 高亮使用未排除的关键词，只有字段条件时不返回高亮
+	var highlighter *search.Highlighter
+	if req.Highlight && query.HasText() {
+		highlighter = search.NewHighlighter(query.Text(), search.DefaultHighlightOptions())
+	}
+
 	// 转换为响应格式
 	var resourceList []models.ResourceResponse
 	for _, resource := range resources {
@@ -99,11 +207,20 @@ func SearchResources(c *gin.Context) {
 			tags[i] = tag.TagName
 		}
 
+		var highlight *models.ResourceHighlight
+		if highlighter != nil {
+			highlight = &models.ResourceHighlight{
+				Title:       highlighter.Highlight(resource.Title),
+				Description: highlighter.Snippet(resource.Description),
+			}
+		}
+
 		resourceList = append(resourceList, models.ResourceResponse{
 			ID:            resource.ID,
 			Title:         resource.Title,
 			Description:   resource.Description,
 			Size:          resource.Size,
+			SizeBytes:     resource.SizeBytes,
 			Type:          resource.Type,
 			Category:      resource.Category.Label,
 			Source:        resource.Source,
@@ -116,23 +233,43 @@ func SearchResources(c *gin.Context) {
 			Tags:          tags,
 			Valid:         resource.Valid,
 			ExpireTime:    resource.ExpireTime,
+			Highlight:     highlight,
 		})
 	}
 
-	// 记录搜索日志（异步）
-	go recordSearchLog(c, req, int(total))
+	// 分面统计基于全部结果而不是当前页
+	var facets map[string][]models.FacetValue
+	if len(facetNames) > 0 {
+		facets, err = computeFacets(base, filters, facetNames)
+		if err != nil {
+			common.InternalServerError(c, "查询失败")
+			return
+		}
+	}
+
+	// 记录搜索日志（异步），游标分页只记录第一页
+	if cursor == nil {
+		go recordSearchLog(c, req, int(total))
+	}
 
-	common.SuccessWithPagination(c, resourceList, req.Page, req.PageSize, int(total))
+	common.Success(c, models.SearchResponse{
+		List:       resourceList,
+		Pagination: pagination,
+		Facets:     facets,
+		Truncated:  truncated,
+	})
 }
 
 // GetHotResources 获取热门推荐
 // @Summary 获取热门推荐
-// @Description 获取热门资源推荐列表
+// @Description 获取热门资源推荐列表，按时间窗口内的搜索和下载热度排序，并与上一个同长度窗口比较排名变化
 // @Tags resources
 // @Accept json
 // @Produce json
 // @Param limit query int false "返回数量，默认10"
-// @Success 200 {object} common.Response{data=[]models.HotResourceResponse}
+// @Param window query string false "时间窗口 (24h/7d)，默认24h"
+// @Param category query string false "分类，包含子分类"
+// @Success 200 {object} common.Response{data=models.HotResourcesResponse}
 // @Router /resources/hot [get]
 func GetHotResources(c *gin.Context) {
 	limitStr := c.DefaultQuery("limit", "10")
@@ -143,31 +280,111 @@ func GetHotResources(c *gin.Context) {
 	if limit > 50 {
 		limit = 50
 	}
+	window := c.DefaultQuery("window", services.DefaultTrendingWindow)
+	if _, ok := services.TrendingWindows[window]; !ok {
+		common.BadRequest(c, services.ErrInvalidTrendingWindow.Error())
+		return
+	}
 
-	var resources []models.Resource
-	if err := database.DB.Model(&models.Resource{}).
-		Preload("Category").
-		Where("valid = ?", true).
-		Order("view_count DESC, download_count DESC").
-		Limit(limit).
-		Find(&resources).Error; err != nil {
+	var categoryIDs []uint
+	if category := c.Query("category"); category != "" && category != services.CategoryAll {
+		if categoryIDs, err = services.CategoryDescendantIDs(database.DB, category); err != nil {
+			common.InternalServerError(c, "查询失败")
+			return
+		}
+	}
+
+	// 读取定时计算的趋势，尚未计算完成或窗口内没有热度时按浏览量排序
+	var items []services.TrendItem
+	var updatedAt *time.Time
+	if trending := services.DefaultTrending(); trending != nil {
+		if items, err = trending.Top(window, categoryIDs, limit); err != nil {
+			common.BadRequest(c, err.Error())
+			return
+		}
+		if len(items) > 0 {
+			computedAt := trending.ComputedAt()
+			updatedAt = &computedAt
+		}
+	}
+
+	var hotResources []models.HotResourceResponse
+	if len(items) > 0 {
+		hotResources, err = trendingResources(items)
+	} else {
+		hotResources, err = popularResources(categoryIDs, limit)
+	}
+	if err != nil {
 		common.InternalServerError(c, "查询失败")
 		return
 	}
 
-	var hotResources []models.HotResourceResponse
-	for i, resource := range resources {
-		// 简单计算趋势（这里使用随机值模拟）
-		trend := "stable"
-		trendValue := 0
-		if i%3 == 0 {
-			trend = "up"
-			trendValue = 15
-		} else if i%3 == 1 {
-			trend = "down"
-			trendValue = -8
+	common.Success(c, models.HotResourcesResponse{
+		List:      hotResources,
+		Window:    window,
+		UpdatedAt: updatedAt,
+	})
+}
+
+// trendingResources 按趋势排名查询资源详情
+func trendingResources(items []services.TrendItem) ([]models.HotResourceResponse, error) {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ResourceID
+	}
+	var resources []models.Resource
+	if err := services.WhereVisible(database.DB.Preload("Category"), false).
+		Where("id IN ?", ids).
+		Find(&resources).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*models.Resource, len(resources))
+	for i := range resources {
+		byID[resources[i].ID] = &resources[i]
+	}
+
+	hotResources := make([]models.HotResourceResponse, 0, len(items))
+	for _, item := range items {
+		// 计算后失效、过期或删除的资源不再返回
+		resource, ok := byID[item.ResourceID]
+		if !ok {
+			continue
 		}
+		hotResources = append(hotResources, models.HotResourceResponse{
+			ID:            resource.ID,
+			Rank:          item.Rank,
+			Title:         resource.Title,
+			Description:   resource.Description,
+			Size:          resource.Size,
+			Type:          resource.Type,
+			Category:      resource.Category.Label,
+			SearchCount:   item.Searches,
+			DownloadCount: item.Downloads,
+			Trend:         item.Trend,
+			PrevRank:      item.PrevRank,
+			RankChange:    item.RankChange,
+			DeltaPercent:  item.DeltaPercent,
+		})
+	}
+	return hotResources, nil
+}
+
+// popularResources 按累计浏览量和下载量排序，没有趋势数据时使用
+func popularResources(categoryIDs []uint, limit int) ([]models.HotResourceResponse, error) {
+	db := services.WhereVisible(database.DB.Model(&models.Resource{}).Preload("Category"), false)
+	if categoryIDs != nil {
+		db = db.Where("category_id IN ?", categoryIDs)
+	}
+
+	var resources []models.Resource
+	if err := db.Order("view_count DESC, download_count DESC").
+		Limit(limit).
+		Find(&resources).Error; err != nil {
+		return nil, err
+	}
 
+	hotResources := make([]models.HotResourceResponse, 0, len(resources))
+	for i, resource := range resources {
 		hotResources = append(hotResources, models.HotResourceResponse{
 			ID:          resource.ID,
 			Rank:        i + 1,
@@ -176,15 +393,10 @@ func GetHotResources(c *gin.Context) {
 			Size:        resource.Size,
 			Type:        resource.Type,
 			Category:    resource.Category.Label,
-			SearchCount: resource.ViewCount,
-			Trend:       trend,
-			TrendValue:  trendValue,
+			Trend:       services.TrendStable,
 		})
 	}
-
-	common.Success(c, map[string]interface{}{
-		"list": hotResources,
-	})
+	return hotResources, nil
 }
 
 // RecordDownload 记录资源下载
@@ -222,11 +434,17 @@ func RecordDownload(c *gin.Context) {
 		return
 	}
 
+	// 已登录时以token中的用户为准
+	userID := req.UserID
+	if uid := middleware.GetUserID(c); uid != "" {
+		userID = uid
+	}
+
 	// 创建下载记录
 	downloadRecord := models.DownloadRecord{
 		ID:           generateID(),
 		ResourceID:   resourceID,
-		UserID:       req.UserID,
+		UserID:       userID,
 		UserAgent:    req.UserAgent,
 		IPAddress:    req.IP,
 		DownloadTime: time.Now(),
@@ -266,8 +484,28 @@ func recordSearchLog(c *gin.Context, req models.SearchRequest, resultCount int)
 	database.DB.Create(&searchRecord)
 }
 
-// 生成ID（简化实现）
+// 生成ID
 func generateID() string {
-	// TODO: 实现更复杂的ID生成逻辑
-	return "dl_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	return common.GenerateID()
+}
+
+// parseSizeRange 解析文件大小范围，参数为空时返回nil
+func parseSizeRange(minSize, maxSize string) (*int64, *int64, error) {
+	var minBytes, maxBytes *int64
+	if minSize != "" {
+		minBytes = services.ParseSizeBytes(minSize)
+		if minBytes == nil {
+			return nil, nil, errors.New("minSize格式错误，应为 700MB、1.5GB 等")
+		}
+	}
+	if maxSize != "" {
+		maxBytes = services.ParseSizeBytes(maxSize)
+		if maxBytes == nil {
+			return nil, nil, errors.New("maxSize格式错误，应为 700MB、10GB 等")
+		}
+	}
+	if minBytes != nil && maxBytes != nil && *minBytes > *maxBytes {
+		return nil, nil, errors.New("minSize不能大于maxSize")
+	}
+	return minBytes, maxBytes, nil
 }
\ No newline at end of file
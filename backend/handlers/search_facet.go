@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"pan-search-api/database"
+	"pan-search-api/models"
+	"pan-search-api/services"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 分面名称
+const (
+	facetCategory = "category"
+	facetSource   = "source"
+	facetType     = "type"
+	facetTag      = "tag"
+	facetYear     = "year"
+)
+
+// facetNames 支持的分面，按响应中的顺序排列
+var facetNames = []string{facetCategory, facetSource, facetType, facetTag, facetYear}
+
+// 标签分面最多返回的值数量，其他分面的取值有限，全部返回
+const facetTagLimit = 20
+
+// facetFilter 分面筛选条件，同一分面的多个值之间为OR
+type facetFilter struct {
+	Name  string
+	Apply func(db *gorm.DB) *gorm.DB
+}
+
+// parseFacetNames 解析需要返回的分面，all或true返回全部分面
+func parseFacetNames(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if value == "all" || value == "true" {
+		return facetNames, nil
+	}
+
+	var names []string
+	for _, name := range splitValues(value) {
+		if !contains(facetNames, name) {
+			return nil, fmt.Errorf("不支持的分面 %s，可用分面：%s", name, strings.Join(facetNames, ","))
+		}
+		if !contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// buildFacetFilters 根据请求参数生成分面筛选条件，参数为逗号分隔的多个值
+func buildFacetFilters(req models.SearchRequest) ([]facetFilter, error) {
+	var filters []facetFilter
+
+	if values := splitValues(req.Category); len(values) > 0 && !contains(values, services.CategoryAll) {
+		// 分类筛选包含子分类
+		var categoryIDs []uint
+		for _, value := range values {
+			ids, err := services.CategoryDescendantIDs(database.DB, value)
+			if err != nil {
+				return nil, err
+			}
+			categoryIDs = append(categoryIDs, ids...)
+		}
+		filters = append(filters, facetFilter{Name: facetCategory, Apply: func(db *gorm.DB) *gorm.DB {
+			return db.Where("resources.category_id IN ?", categoryIDs)
+		}})
+	}
+
+	if values := splitValues(req.Source); len(values) > 0 {
+		filters = append(filters, facetFilter{Name: facetSource, Apply: func(db *gorm.DB) *gorm.DB {
+			return db.Where("resources.source IN ?", values)
+		}})
+	}
+
+	if values := splitValues(req.Type); len(values) > 0 {
+		filters = append(filters, facetFilter{Name: facetType, Apply: func(db *gorm.DB) *gorm.DB {
+			return db.Where("resources.type IN ?", values)
+		}})
+	}
+
+	if values := splitValues(req.Tag); len(values) > 0 {
+		filters = append(filters, facetFilter{Name: facetTag, Apply: func(db *gorm.DB) *gorm.DB {
+			return db.Where("EXISTS (SELECT 1 FROM resource_tags WHERE resource_tags.resource_id = resources.id AND resource_tags.tag_name IN ?)", values)
+		}})
+	}
+
+	if values := splitValues(req.Year); len(values) > 0 {
+		// 按时间范围筛选，可以使用upload_time索引
+		conditions := make([]string, len(values))
+		args := make([]interface{}, 0, len(values)*2)
+		for i, value := range values {
+			year, err := strconv.Atoi(value)
+			if err != nil || year < 1970 || year > 9999 {
+				return nil, errors.New("year格式错误，应为逗号分隔的年份，如 2023,2024")
+			}
+			conditions[i] = "(resources.upload_time >= ? AND resources.upload_time < ?)"
+			args = append(args,
+				time.Date(year, 1, 1, 0, 0, 0, 0, time.Local),
+				time.Date(year+1, 1, 1, 0, 0, 0, 0, time.Local))
+		}
+		expr := "(" + strings.Join(conditions, " OR ") + ")"
+		filters = append(filters, facetFilter{Name: facetYear, Apply: func(db *gorm.DB) *gorm.DB {
+			return db.Where(expr, args...)
+		}})
+	}
+
+	return filters, nil
+}
+
+// applyFacetFilters 应用除except以外的分面筛选条件
+func applyFacetFilters(db *gorm.DB, filters []facetFilter, except string) *gorm.DB {
+	for _, filter := range filters {
+		if filter.Name != except {
+			db = filter.Apply(db)
+		}
+	}
+	return db
+}
+
+// computeFacets 统计各分面的取值数量。每个分面应用其他全部筛选条件，但不应用自身的筛选，
+// 这样已选中某个来源时仍能看到其他来源的数量，便于多选
+func computeFacets(base *gorm.DB, filters []facetFilter, names []string) (map[string][]models.FacetValue, error) {
+	facets := make(map[string][]models.FacetValue, len(names))
+	for _, name := range names {
+		db := applyFacetFilters(base, filters, name)
+
+		switch name {
+		case facetCategory:
+			db = db.Joins("JOIN categories ON categories.id = resources.category_id").
+				Select("categories.value AS value, categories.label AS label, COUNT(*) AS count").
+				Group("categories.id, categories.value, categories.label").
+				Order("count DESC, categories.sort_order")
+		case facetSource:
+			db = db.Select("resources.source AS value, COUNT(*) AS count").
+				Group("resources.source").
+				Order("count DESC")
+		case facetType:
+			db = db.Select("resources.type AS value, COUNT(*) AS count").
+				Group("resources.type").
+				Order("count DESC")
+		case facetTag:
+			db = db.Joins("JOIN resource_tags ON resource_tags.resource_id = resources.id").
+				Select("resource_tags.tag_name AS value, COUNT(DISTINCT resources.id) AS count").
+				Group("resource_tags.tag_name").
+				Order("count DESC").
+				Limit(facetTagLimit)
+		case facetYear:
+			db = db.Select("YEAR(resources.upload_time) AS value, COUNT(*) AS count").
+				Group("YEAR(resources.upload_time)").
+				Order("value DESC")
+		}
+
+		values := []models.FacetValue{}
+		if err := db.Scan(&values).Error; err != nil {
+			return nil, err
+		}
+		facets[name] = values
+	}
+	return facets, nil
+}
+
+// splitValues 拆分逗号分隔的参数，去除空白和空值
+func splitValues(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"pan-search-api/models"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// dryRunDB 只生成SQL、不连接数据库的查询
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "test@tcp(127.0.0.1:1)/test", SkipInitializeWithVersion: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestParseFacetNames(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"all", facetNames, false},
+		{"true", facetNames, false},
+		{"source", []string{"source"}, false},
+		{"tag, source ,tag", []string{"tag", "source"}, false},
+		{" , ", nil, false},
+		{"source,color", nil, true},
+		{"ALL", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseFacetNames(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFacetNames(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFacetNames(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitValues(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", nil},
+		{"aliyun", []string{"aliyun"}},
+		{"aliyun, quark ,,baidu", []string{"aliyun", "quark", "baidu"}},
+		{" , ", nil},
+	}
+
+	for _, tt := range tests {
+		if got := splitValues(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitValues(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestBuildFacetFiltersYear(t *testing.T) {
+	tests := []struct {
+		year    string
+		wantErr bool
+	}{
+		{"2024", false},
+		{"2023,2024", false},
+		{"1970", false},
+		{"1969", true},
+		{"24", true},
+		{"2024年", true},
+		{"10000", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.year, func(t *testing.T) {
+			_, err := buildFacetFilters(models.SearchRequest{Year: tt.year})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("buildFacetFilters(year=%q) error = %v, wantErr %v", tt.year, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// 每个分面统计应用其他分面的筛选，但不应用自身的筛选
+func TestApplyFacetFiltersExcludesOwnFilter(t *testing.T) {
+	filters, err := buildFacetFilters(models.SearchRequest{Source: "aliyun,quark", Type: "mkv", Tag: "4K", Year: "2024"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conditions := map[string]string{
+		facetSource: "resources.source IN",
+		facetType:   "resources.type IN",
+		facetTag:    "resource_tags.tag_name IN",
+		facetYear:   "resources.upload_time >=",
+	}
+	db := dryRunDB(t)
+	for _, except := range []string{"", facetSource, facetType, facetTag, facetYear} {
+		t.Run("except "+except, func(t *testing.T) {
+			sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				var values []models.FacetValue
+				return applyFacetFilters(tx.Model(&models.Resource{}), filters, except).Find(&values)
+			})
+			for name, condition := range conditions {
+				if got, want := strings.Contains(sql, condition), name != except; got != want {
+					t.Errorf("condition %q present = %v, want %v in %s", condition, got, want, sql)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"pan-search-api/common"
+	"pan-search-api/database"
+	"pan-search-api/models"
+	"pan-search-api/search"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// applySearchQuery 将解析后的查询转换为筛选条件，返回按相关度降序排列的候选资源ID，
+// truncated表示有关键词按候选ID筛选且命中超过候选数量上限，结果不完整
+func applySearchQuery(db *gorm.DB, query *search.Query) (_ *gorm.DB, hitIDs []string, truncated bool, err error) {
+	scores := make(map[string]float64)
+	for _, clause := range query.Clauses {
+		conditions := make([]string, 0, len(clause.Terms))
+		var args []interface{}
+		for _, term := range clause.Terms {
+			condition, termArgs, termTruncated, err := termCondition(term, clause.Negate, scores)
+			if err != nil {
+				return nil, nil, false, err
+			}
+			truncated = truncated || termTruncated
+			conditions = append(conditions, condition)
+			args = append(args, termArgs...)
+		}
+
+		expr := "(" + strings.Join(conditions, " OR ") + ")"
+		if clause.Negate {
+			expr = "NOT " + expr
+		}
+		db = db.Where(expr, args...)
+	}
+
+	hitIDs = make([]string, 0, len(scores))
+	for id := range scores {
+		hitIDs = append(hitIDs, id)
+	}
+	sort.Slice(hitIDs, func(i, j int) bool {
+		if scores[hitIDs[i]] != scores[hitIDs[j]] {
+			return scores[hitIDs[i]] > scores[hitIDs[j]]
+		}
+		return hitIDs[i] < hitIDs[j]
+	})
+	return db, hitIDs, truncated, nil
+}
+
+// termCondition 生成单个条件的SQL。关键词由搜索引擎生成筛选条件并累加候选的相关度，
+// 候选只用于排序；排除的关键词直接匹配标题和描述，不受搜索引擎候选数量的限制
+func termCondition(term search.Term, negate bool, scores map[string]float64) (string, []interface{}, bool, error) {
+	switch term.Field {
+	case search.FieldSource:
+		return "resources.source = ?", []interface{}{term.Value}, false, nil
+	case search.FieldType:
+		return "resources.type = ?", []interface{}{term.Value}, false, nil
+	case search.FieldTag:
+		return "EXISTS (SELECT 1 FROM resource_tags WHERE resource_tags.resource_id = resources.id AND resource_tags.tag_name = ?)",
+			[]interface{}{term.Value}, false, nil
+	case search.FieldSize:
+		return "resources.size_bytes " + term.Op + " ?", []interface{}{term.Bytes}, false, nil
+	case search.FieldAfter:
+		return "resources.upload_time >= ?", []interface{}{term.Time}, false, nil
+	case search.FieldBefore:
+		return "resources.upload_time < ?", []interface{}{term.Time}, false, nil
+	}
+
+	like := "%" + term.Value + "%"
+	if negate {
+		return "(resources.title LIKE ? OR resources.description LIKE ?)", []interface{}{like, like}, false, nil
+	}
+
+	filter, err := search.Match(database.DB, term.Value, search.MaxCandidates())
+	if err != nil {
+		return "", nil, false, err
+	}
+	for _, hit := range filter.Hits {
+		scores[hit.ID] += hit.Score
+	}
+
+	if term.Phrase {
+		// 搜索引擎按分词匹配，短语还需要在原文中连续出现
+		return "(" + filter.SQL + " AND (resources.title LIKE ? OR resources.description LIKE ?))",
+			append(filter.Vars, like, like), filter.Truncated, nil
+	}
+	return filter.SQL, filter.Vars, filter.Truncated, nil
+}
+
+// 游标分页复用第一页的候选结果顺序，like引擎按浏览量排序，翻页期间浏览量变化会改变候选顺序。
+// 快照保存在进程内存中，多实例部署时需要将同一客户端的请求路由到同一实例，
+// 否则后续页在其他实例上找不到快照，按该实例当时的检索结果排序，可能重复或遗漏
+const (
+	hitSnapshotTTL        = 15 * time.Minute
+	maxHitSnapshotEntries = 1000
+)
+
+// hitSnapshot 某次查询的候选资源ID顺序
+type hitSnapshot struct {
+	ids     []string
+	expires time.Time
+}
+
+var hitSnapshots = struct {
+	sync.Mutex
+	entries map[string]hitSnapshot
+}{entries: make(map[string]hitSnapshot)}
+
+// saveHitSnapshot 保存候选顺序，缓存已满时先清理过期的条目，仍然满时淘汰最早过期的条目
+func saveHitSnapshot(key string, ids []string) {
+	hitSnapshots.Lock()
+	defer hitSnapshots.Unlock()
+
+	now := time.Now()
+	if len(hitSnapshots.entries) >= maxHitSnapshotEntries {
+		var oldestKey string
+		var oldest time.Time
+		for k, snapshot := range hitSnapshots.entries {
+			if now.After(snapshot.expires) {
+				delete(hitSnapshots.entries, k)
+				continue
+			}
+			if oldestKey == "" || snapshot.expires.Before(oldest) {
+				oldestKey, oldest = k, snapshot.expires
+			}
+		}
+		if len(hitSnapshots.entries) >= maxHitSnapshotEntries {
+			delete(hitSnapshots.entries, oldestKey)
+		}
+	}
+	hitSnapshots.entries[key] = hitSnapshot{ids: ids, expires: now.Add(hitSnapshotTTL)}
+}
+
+// loadHitSnapshot 读取未过期的候选顺序
+func loadHitSnapshot(key string) ([]string, bool) {
+	hitSnapshots.Lock()
+	defer hitSnapshots.Unlock()
+
+	snapshot, ok := hitSnapshots.entries[key]
+	if !ok || time.Now().After(snapshot.expires) {
+		return nil, false
+	}
+	return snapshot.ids, true
+}
+
+// hitSnapshotKey 快照键，同一查询的每次首页请求生成独立的快照
+func hitSnapshotKey(cursor *common.Cursor) string {
+	return cursor.Scope + ":" + strconv.FormatInt(cursor.Snapshot, 10)
+}
+
+// searchOrder 搜索结果的排序方式，keyset为true时用于游标分页
+func searchOrder(sortBy string, hitIDs []string, keyset bool) keysetOrder {
+	order := keysetOrder{IDExpr: "resources.id"}
+	switch sortBy {
+	case "time":
+		order.Columns = []keysetColumn{{Expr: "resources.upload_time", Desc: true, Time: true}}
+	case "size":
+		// 大小未知的资源排在最后
+		order.Columns = []keysetColumn{{Expr: "COALESCE(resources.size_bytes, -1)", Desc: true}}
+	default: // relevance
+		if len(hitIDs) > 0 {
+			// 按搜索引擎返回的顺序排序，不在候选中的资源（FIELD为0）排在最后
+			order.Columns = []keysetColumn{
+				{Expr: "(FIELD(resources.id, ?) = 0)", Vars: []interface{}{hitIDs}},
+				{Expr: "FIELD(resources.id, ?)", Vars: []interface{}{hitIDs}},
+			}
+		} else if keyset {
+			// 浏览量和下载量在翻页期间会变化，排在游标之前的资源计数增加后会被跳过，
+			// 游标分页改按不变的上传时间排序
+			order.Columns = []keysetColumn{{Expr: "resources.upload_time", Desc: true, Time: true}}
+		} else {
+			order.Columns = []keysetColumn{
+				{Expr: "resources.view_count", Desc: true},
+				{Expr: "resources.download_count", Desc: true},
+			}
+		}
+	}
+	return order
+}
+
+// searchSortKeys 资源在游标分页的searchOrder中的排序字段值，用于生成下一页的游标
+func searchSortKeys(sortBy string, hitIDs []string, resource *models.Resource) []int64 {
+	switch sortBy {
+	case "time":
+		return []int64{resource.UploadTime.UnixNano()}
+	case "size":
+		if resource.SizeBytes == nil {
+			return []int64{-1}
+		}
+		return []int64{*resource.SizeBytes}
+	default:
+		if len(hitIDs) > 0 {
+			// 与MySQL的FIELD一致，从1开始，不在候选中时为0
+			for i, id := range hitIDs {
+				if id == resource.ID {
+					return []int64{0, int64(i + 1)}
+				}
+			}
+			return []int64{1, 0}
+		}
+		return []int64{resource.UploadTime.UnixNano()}
+	}
+}
@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"pan-search-api/common"
+	"pan-search-api/config"
+	"pan-search-api/database"
+	"pan-search-api/middleware"
+	"pan-search-api/models"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 默认刷新token有效期
+const defaultRefreshExpire = 30 * 24 * time.Hour
+
+var (
+	errRefreshTokenInvalid = errors.New("无效的刷新token")
+	errRefreshTokenReused  = errors.New("刷新token已被使用，会话已失效，请重新登录")
+)
+
+// RefreshToken 刷新访问token
+// @Summary 刷新访问token
+// @Description 使用刷新token换取新的访问token，刷新token同时轮换，旧token重复使用将吊销整个会话
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.RefreshTokenRequest true "刷新token"
+// @Success 200 {object} common.Response{data=models.LoginResponse}
+// @Router /auth/refresh [post]
+func RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	sessionID, secret, ok := splitRefreshToken(req.RefreshToken)
+	if !ok {
+		common.Unauthorized(c, errRefreshTokenInvalid.Error())
+		return
+	}
+
+	var (
+		user         models.User
+		session      models.UserSession
+		refreshToken string
+		statusMsg    string
+		reused       bool
+	)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 加锁防止同一刷新token被并发轮换
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&session, "id = ?", sessionID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errRefreshTokenInvalid
+			}
+			return err
+		}
+
+		if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+			return errRefreshTokenInvalid
+		}
+
+		hash := hashToken(secret)
+		if !secureEqual(hash, session.RefreshTokenHash) {
+			if session.PreviousTokenHash != "" && secureEqual(hash, session.PreviousTokenHash) {
+				// 已轮换的旧token被再次使用，视为泄露，吊销会话。
+				// 返回错误会回滚事务，因此提交吊销后再返回401
+				reused = true
+				now := time.Now()
+				return tx.Model(&session).Update("revoked_at", &now).Error
+			}
+			return errRefreshTokenInvalid
+		}
+
+		if err := tx.First(&user, "id = ?", session.UserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errRefreshTokenInvalid
+			}
+			return err
+		}
+		if statusMsg = userStatusError(user.Status); statusMsg != "" {
+			now := time.Now()
+			return tx.Model(&session).Update("revoked_at", &now).Error
+		}
+
+		var newHash string
+		refreshToken, newHash = newRefreshToken(session.ID)
+		now := time.Now()
+		session.PreviousTokenHash = session.RefreshTokenHash
+		session.RefreshTokenHash = newHash
+		session.ExpiresAt = now.Add(refreshExpire())
+		session.LastUsedAt = now
+		session.IPAddress = c.ClientIP()
+		session.UserAgent = c.GetHeader("User-Agent")
+		return tx.Save(&session).Error
+	})
+
+	switch {
+	case errors.Is(err, errRefreshTokenInvalid):
+		common.Unauthorized(c, err.Error())
+		return
+	case err != nil:
+		common.InternalServerError(c, "刷新token失败")
+		return
+	case reused:
+		common.Unauthorized(c, errRefreshTokenReused.Error())
+		return
+	case statusMsg != "":
+		common.Forbidden(c, statusMsg)
+		return
+	}
+
+	respondTokens(c, &user, &session, refreshToken)
+}
+
+// Logout 退出登录
+// @Summary 退出登录
+// @Description 吊销当前会话，可携带访问token或在请求体中提供刷新token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body models.LogoutRequest false "刷新token"
+// @Security BearerAuth
+// @Success 200 {object} common.Response
+// @Router /auth/logout [post]
+func Logout(c *gin.Context) {
+	var req models.LogoutRequest
+	// 请求体可选
+	_ = c.ShouldBindJSON(&req)
+
+	query := database.DB.Model(&models.UserSession{}).Where("revoked_at IS NULL")
+	if claims, ok := c.Get(middleware.ContextClaims); ok {
+		claims := claims.(*common.Claims)
+		query = query.Where("id = ? AND user_id = ?", claims.SessionID, claims.UserID)
+	} else if sessionID, secret, ok := splitRefreshToken(req.RefreshToken); ok {
+		query = query.Where("id = ? AND refresh_token_hash = ?", sessionID, hashToken(secret))
+	} else {
+		common.Unauthorized(c, "缺少认证token")
+		return
+	}
+
+	if err := query.Update("revoked_at", time.Now()).Error; err != nil {
+		common.InternalServerError(c, "退出登录失败")
+		return
+	}
+
+	common.Success(c, nil)
+}
+
+// LogoutAll 退出所有设备
+// @Summary 退出所有设备
+// @Description 吊销当前用户的全部会话
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response
+// @Router /auth/logout-all [post]
+func LogoutAll(c *gin.Context) {
+	revoked, err := revokeUserSessions(database.DB, middleware.GetUserID(c), "")
+	if err != nil {
+		common.InternalServerError(c, "退出登录失败")
+		return
+	}
+
+	common.Success(c, map[string]interface{}{
+		"revokedSessions": revoked,
+	})
+}
+
+// respondLogin 创建会话、签发token并返回登录响应
+func respondLogin(c *gin.Context, user *models.User) {
+	now := time.Now()
+	session := models.UserSession{
+		ID:         common.GenerateID(),
+		UserID:     user.ID,
+		UserAgent:  c.GetHeader("User-Agent"),
+		IPAddress:  c.ClientIP(),
+		ExpiresAt:  now.Add(refreshExpire()),
+		LastUsedAt: now,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	refreshToken, hash := newRefreshToken(session.ID)
+	session.RefreshTokenHash = hash
+
+	if err := database.DB.Create(&session).Error; err != nil {
+		common.InternalServerError(c, "创建会话失败")
+		return
+	}
+
+	respondTokens(c, user, &session, refreshToken)
+}
+
+// respondTokens 签发访问token并返回登录响应
+func respondTokens(c *gin.Context, user *models.User, session *models.UserSession, refreshToken string) {
+	token, expiresAt, err := common.GenerateToken(user.ID, user.Role, session.ID)
+	if err != nil {
+		common.InternalServerError(c, "签发token失败")
+		return
+	}
+
+	common.Success(c, models.LoginResponse{
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+		User:             toUserProfile(user),
+	})
+}
+
+// revokeUserSessions 吊销用户的全部有效会话，exceptSessionID不为空时保留该会话
+func revokeUserSessions(db *gorm.DB, userID, exceptSessionID string) (int64, error) {
+	query := db.Model(&models.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID)
+	if exceptSessionID != "" {
+		query = query.Where("id <> ?", exceptSessionID)
+	}
+	result := query.Update("revoked_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+// refreshExpire 刷新token有效期
+func refreshExpire() time.Duration {
+	if d := config.GlobalConfig.JWT.RefreshExpire; d > 0 {
+		return d
+	}
+	return defaultRefreshExpire
+}
+
+// newRefreshToken 生成刷新token，格式为 "{sessionID}.{随机串}"，返回token及随机串的哈希
+func newRefreshToken(sessionID string) (string, string) {
+	secret := newSecret()
+	return sessionID + "." + secret, hashToken(secret)
+}
+
+// newSecret 生成URL安全的随机串，用于刷新token和求助访问token。
+// 随机源不可用时panic，与common.GenerateID一致
+func newSecret() string {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		panic("handlers: failed to read random bytes: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// splitRefreshToken 拆分刷新token
+func splitRefreshToken(token string) (string, string, bool) {
+	sessionID, secret, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok || sessionID == "" || secret == "" {
+		return "", "", false
+	}
+	return sessionID, secret, true
+}
+
+// hashToken 计算刷新token和求助访问token的哈希，数据库中只保存哈希
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// secureEqual 常量时间比较
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"pan-search-api/common"
+	"pan-search-api/database"
+	"pan-search-api/middleware"
+	"pan-search-api/models"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetUserProfile 获取当前用户信息
+// @Summary 获取用户信息
+// @Description 获取当前登录用户的信息
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.UserProfileResponse}
+// @Router /user/profile [get]
+func GetUserProfile(c *gin.Context) {
+	user, ok := loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	common.Success(c, toUserProfile(user))
+}
+
+// UpdateUserProfile 更新当前用户信息
+// @Summary 更新用户信息
+// @Description 更新邮箱、手机号、头像，修改密码时需要提供原密码
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param body body models.UserProfileUpdate true "用户信息"
+// @Security BearerAuth
+// @Success 200 {object} common.Response{data=models.UserProfileResponse}
+// @Router /user/profile [put]
+func UpdateUserProfile(c *gin.Context) {
+	var req models.UserProfileUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BadRequest(c, "请求参数错误")
+		return
+	}
+
+	user, ok := loadCurrentUser(c)
+	if !ok {
+		return
+	}
+
+	updates := map[string]interface{}{}
+	var email, phone string
+	if req.Email != nil {
+		email = strings.ToLower(strings.TrimSpace(*req.Email))
+		if email == "" {
+			common.BadRequest(c, "邮箱不能为空")
+			return
+		}
+		updates["email"] = email
+	}
+	if req.Phone != nil {
+		phone = strings.TrimSpace(*req.Phone)
+		if phone == "" {
+			updates["phone"] = nil
+		} else {
+			if !phoneRegexp.MatchString(phone) {
+				common.BadRequest(c, "手机号格式错误")
+				return
+			}
+			updates["phone"] = phone
+		}
+	}
+	if req.Avatar != nil {
+		updates["avatar"] = strings.TrimSpace(*req.Avatar)
+	}
+	if req.NewPassword != "" {
+		if !checkPassword(user.PasswordHash, req.OldPassword) {
+			common.BadRequest(c, "原密码错误")
+			return
+		}
+		passwordHash, err := hashPassword(req.NewPassword)
+		if err != nil {
+			common.InternalServerError(c, "更新失败")
+			return
+		}
+		updates["password_hash"] = passwordHash
+	}
+
+	if len(updates) == 0 {
+		common.Success(c, toUserProfile(user))
+		return
+	}
+
+	if msg := checkUserUnique(database.DB, user.ID, "", email, phone); msg != "" {
+		common.Conflict(c, msg)
+		return
+	}
+
+	// 修改密码后吊销其他设备上的会话，与更新在同一事务中，吊销失败时不修改密码
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Updates(updates).Error; err != nil {
+			return err
+		}
+		if req.NewPassword == "" {
+			return nil
+		}
+		var currentSessionID string
+		if claims, ok := c.Get(middleware.ContextClaims); ok {
+			currentSessionID = claims.(*common.Claims).SessionID
+		}
+		_, err := revokeUserSessions(tx, user.ID, currentSessionID)
+		return err
+	})
+	if err != nil {
+		if isDuplicateKeyError(err) {
+			common.Conflict(c, "邮箱或手机号已被注册")
+		} else {
+			common.InternalServerError(c, "更新失败")
+		}
+		return
+	}
+
+	if err := database.DB.First(user, "id = ?", user.ID).Error; err != nil {
+		common.InternalServerError(c, "查询失败")
+		return
+	}
+
+	common.Success(c, toUserProfile(user))
+}
+
+// loadCurrentUser 加载当前登录用户，失败时已写入错误响应
+func loadCurrentUser(c *gin.Context) (*models.User, bool) {
+	var user models.User
+	if err := database.DB.First(&user, "id = ?", middleware.GetUserID(c)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			common.NotFound(c, "用户不存在")
+		} else {
+			common.InternalServerError(c, "查询失败")
+		}
+		return nil, false
+	}
+	return &user, true
+}
@@ -0,0 +1,474 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"pan-search-api/models"
+	"pan-search-api/services"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 支持的导入格式
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// 单行JSONL最大长度
+const maxLineSize = 1 << 20
+
+// Fields 可导入的资源字段
+var Fields = []string{
+	"title", "description", "size", "type", "category", "source",
+	"download_url", "extract_code", "file_count", "tags", "valid",
+	"expire_time", "upload_time",
+}
+
+// 字段的默认源列别名，自定义映射优先
+var fieldAliases = map[string][]string{
+	"download_url": {"downloadUrl", "url"},
+	"extract_code": {"extractCode", "code"},
+	"file_count":   {"fileCount"},
+	"expire_time":  {"expireTime"},
+	"upload_time":  {"uploadTime"},
+}
+
+// 支持的时间格式
+var timeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+// Options 导入选项
+type Options struct {
+	Format  string            // csv 或 jsonl
+	Mapping map[string]string // 资源字段 -> 源文件列名
+	DryRun  bool              // 只校验不写入
+}
+
+// RowError 行级错误
+type RowError struct {
+	Row     int    `json:"row"` // 数据行号，从1开始，不含CSV表头
+	Title   string `json:"title"`
+	Reason  string `json:"reason"`
+	Skipped bool   `json:"skipped"` // 为true表示因重复被跳过
+}
+
+// Report 导入报告
+type Report struct {
+	DryRun      bool       `json:"dryRun"`
+	Total       int        `json:"total"`
+	Created     int        `json:"created"` // 演练模式下为校验通过的行数
+	Skipped     int        `json:"skipped"`
+	Failed      int        `json:"failed"`
+	ResourceIDs []string   `json:"resourceIds,omitempty"`
+	Errors      []RowError `json:"errors"`
+}
+
+// record 一行源数据，按源列名索引
+type record map[string]string
+
+// Import 从CSV或JSONL导入资源，单行错误只记入报告，不会中断整批导入
+func Import(db *gorm.DB, r io.Reader, opts Options) (*Report, error) {
+	for field := range opts.Mapping {
+		if !isField(field) {
+			return nil, fmt.Errorf("unknown field %q in mapping", field)
+		}
+	}
+
+	categories, err := loadCategories(db)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &importer{
+		db:         db,
+		opts:       opts,
+		categories: categories,
+		seenURLs:   make(map[string]int),
+		report:     &Report{DryRun: opts.DryRun, Errors: []RowError{}},
+	}
+
+	switch strings.ToLower(opts.Format) {
+	case FormatCSV:
+		err = readCSV(r, imp.handle)
+	case FormatJSONL:
+		err = readJSONL(r, imp.handle)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", opts.Format)
+	}
+	if err != nil {
+		return imp.report, err
+	}
+
+	return imp.report, nil
+}
+
+// DetectFormat 根据文件名推断格式
+func DetectFormat(filename string) string {
+	lower := strings.ToLower(filename)
+	switch {
+	case strings.HasSuffix(lower, ".csv"):
+		return FormatCSV
+	case strings.HasSuffix(lower, ".jsonl"), strings.HasSuffix(lower, ".ndjson"):
+		return FormatJSONL
+	}
+	return ""
+}
+
+// ParseMapping 解析 "title=名称,category=分类" 形式的列映射
+func ParseMapping(value string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		field, column, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(field) == "" || strings.TrimSpace(column) == "" {
+			return nil, fmt.Errorf("invalid mapping %q, expected field=column", pair)
+		}
+		mapping[strings.TrimSpace(field)] = strings.TrimSpace(column)
+	}
+	return mapping, nil
+}
+
+// importer 单次导入的状态
+type importer struct {
+	db         *gorm.DB
+	opts       Options
+	categories map[string]uint
+	seenURLs   map[string]int // 本次文件中已出现的分享去重键或链接 -> 行号
+	report     *Report
+}
+
+// handle 处理一行数据
+func (imp *importer) handle(row int, rec record, readErr error) {
+	imp.report.Total++
+	if readErr != nil {
+		imp.fail(row, "", readErr.Error())
+		return
+	}
+
+	input, err := imp.buildInput(rec)
+	if err != nil {
+		imp.fail(row, input.Title, err.Error())
+		return
+	}
+
+	// 同一网盘分享的不同链接写法视为重复，无法识别的链接按原文比较
+	shareKey := services.NormalizeShare(&input)
+	if shareKey == "" && input.Source == "" {
+		imp.fail(row, input.Title, services.ErrSourceRequired.Error())
+		return
+	}
+	dedupKey := shareKey
+	if dedupKey == "" {
+		dedupKey = input.DownloadURL
+	}
+	if prev, ok := imp.seenURLs[dedupKey]; ok {
+		imp.skip(row, input.Title, fmt.Sprintf("与第%d行的下载链接重复", prev))
+		return
+	}
+	imp.seenURLs[dedupKey] = row
+
+	exists, err := services.ShareExists(imp.db, shareKey, input.DownloadURL)
+	if err != nil {
+		imp.fail(row, input.Title, err.Error())
+		return
+	}
+	if exists {
+		imp.skip(row, input.Title, "下载链接已存在")
+		return
+	}
+
+	if imp.opts.DryRun {
+		imp.report.Created++
+		return
+	}
+
+	resource, err := services.CreateResource(imp.db, input)
+	if err != nil {
+		imp.fail(row, input.Title, err.Error())
+		return
+	}
+	imp.report.Created++
+	imp.report.ResourceIDs = append(imp.report.ResourceIDs, resource.ID)
+}
+
+// buildInput 将源数据转换为资源创建参数
+func (imp *importer) buildInput(rec record) (models.ResourceCreate, error) {
+	get := func(field string) string {
+		return strings.TrimSpace(imp.value(rec, field))
+	}
+
+	input := models.ResourceCreate{
+		Title:       get("title"),
+		Description: get("description"),
+		Size:        get("size"),
+		Type:        get("type"),
+		Source:      get("source"),
+		DownloadURL: get("download_url"),
+		ExtractCode: get("extract_code"),
+		Tags:        splitTags(get("tags")),
+	}
+
+	var missing []string
+	for field, value := range map[string]string{
+		"title": input.Title, "size": input.Size, "type": input.Type,
+		"download_url": input.DownloadURL,
+	} {
+		if value == "" {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return input, fmt.Errorf("缺少必填字段: %s", strings.Join(sortedFields(missing), ", "))
+	}
+
+	category := get("category")
+	if category == "" {
+		return input, errors.New("缺少必填字段: category")
+	}
+	categoryID, ok := imp.categories[strings.ToLower(category)]
+	if !ok {
+		return input, fmt.Errorf("分类不存在: %s", category)
+	}
+	input.CategoryID = categoryID
+
+	if v := get("file_count"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return input, fmt.Errorf("file_count格式错误: %s", v)
+		}
+		input.FileCount = uint(n)
+	}
+	if v := get("valid"); v != "" {
+		valid, err := strconv.ParseBool(v)
+		if err != nil {
+			return input, fmt.Errorf("valid格式错误: %s", v)
+		}
+		input.Valid = &valid
+	}
+	for field, target := range map[string]**time.Time{
+		"expire_time": &input.ExpireTime,
+		"upload_time": &input.UploadTime,
+	} {
+		if v := get(field); v != "" {
+			t, err := parseTime(v)
+			if err != nil {
+				return input, fmt.Errorf("%s格式错误: %s", field, v)
+			}
+			*target = &t
+		}
+	}
+
+	if err := checkLengths(input); err != nil {
+		return input, err
+	}
+	return input, nil
+}
+
+// value 按映射读取字段值
+func (imp *importer) value(rec record, field string) string {
+	if column, ok := imp.opts.Mapping[field]; ok {
+		return rec[column]
+	}
+	if v, ok := rec[field]; ok {
+		return v
+	}
+	for _, alias := range fieldAliases[field] {
+		if v, ok := rec[alias]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+func (imp *importer) fail(row int, title, reason string) {
+	imp.report.Failed++
+	imp.report.Errors = append(imp.report.Errors, RowError{Row: row, Title: title, Reason: reason})
+}
+
+func (imp *importer) skip(row int, title, reason string) {
+	imp.report.Skipped++
+	imp.report.Errors = append(imp.report.Errors, RowError{Row: row, Title: title, Reason: reason, Skipped: true})
+}
+
+// readCSV 逐行读取CSV，首行为表头
+func readCSV(r io.Reader, handle func(int, record, error)) error {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return errors.New("empty csv file")
+	}
+	if err != nil {
+		return fmt.Errorf("read csv header: %v", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+
+	for row := 1; ; row++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				handle(row, nil, err)
+				continue
+			}
+			return err
+		}
+
+		rec := make(record, len(header))
+		for i, column := range header {
+			if i < len(fields) {
+				rec[column] = fields[i]
+			}
+		}
+		handle(row, rec, nil)
+	}
+}
+
+// readJSONL 逐行读取JSONL，每行一个JSON对象
+func readJSONL(r io.Reader, handle func(int, record, error)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	row := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		row++
+
+		var obj map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &obj); err != nil {
+			handle(row, nil, fmt.Errorf("JSON格式错误: %v", err))
+			continue
+		}
+
+		rec := make(record, len(obj))
+		for key, value := range obj {
+			rec[key] = stringify(value)
+		}
+		handle(row, rec, nil)
+	}
+	return scanner.Err()
+}
+
+// stringify 将JSON值转换为字符串，数组以逗号拼接
+func stringify(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, stringify(item))
+		}
+		return strings.Join(parts, ",")
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// splitTags 拆分标签，支持逗号、分号、竖线和中文逗号
+func splitTags(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';' || r == '|' || r == '，' || r == '；'
+	})
+}
+
+// parseTime 解析时间
+func parseTime(value string) (time.Time, error) {
+	var err error
+	for _, layout := range timeLayouts {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// checkLengths 校验字段长度，与数据库列宽一致
+func checkLengths(input models.ResourceCreate) error {
+	limits := []struct {
+		field string
+		value string
+		max   int
+	}{
+		{"title", input.Title, 255},
+		{"size", input.Size, 20},
+		{"type", input.Type, 50},
+		{"source", input.Source, 100},
+		{"download_url", input.DownloadURL, 500},
+		{"extract_code", input.ExtractCode, 20},
+	}
+	for _, limit := range limits {
+		if len([]rune(limit.value)) > limit.max {
+			return fmt.Errorf("%s超过最大长度%d", limit.field, limit.max)
+		}
+	}
+	return nil
+}
+
+// loadCategories 加载分类 value -> ID
+func loadCategories(db *gorm.DB) (map[string]uint, error) {
+	var categories []models.Category
+	if err := db.Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]uint, len(categories))
+	for _, category := range categories {
+		result[strings.ToLower(category.Value)] = category.ID
+	}
+	return result, nil
+}
+
+// isField 是否为可导入字段
+func isField(name string) bool {
+	for _, field := range Fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedFields 按Fields中的顺序排列字段名，保证错误信息稳定
+func sortedFields(names []string) []string {
+	result := make([]string, 0, len(names))
+	for _, field := range Fields {
+		for _, name := range names {
+			if name == field {
+				result = append(result, field)
+			}
+		}
+	}
+	return result
+}
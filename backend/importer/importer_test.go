@@ -0,0 +1,351 @@
+package importer
+
+import (
+	"fmt"
+	"pan-search-api/models"
+	"pan-search-api/services"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testCategories 测试用分类 value -> ID
+var testCategories = map[string]uint{"movie": 1, "music": 2}
+
+// readRow readCSV/readJSONL 回调收到的一行
+type readRow struct {
+	row int
+	rec record
+	err bool
+}
+
+// collect 收集读取到的全部行
+func collect(read func(func(int, record, error)) error) ([]readRow, error) {
+	var rows []readRow
+	err := read(func(row int, rec record, err error) {
+		rows = append(rows, readRow{row: row, rec: rec, err: err != nil})
+	})
+	return rows, err
+}
+
+func TestParseMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"empty", "", map[string]string{}, false},
+		{"single", "title=名称", map[string]string{"title": "名称"}, false},
+		{"spaces and empty pairs", " title = 名称 ,, category=分类 ,", map[string]string{"title": "名称", "category": "分类"}, false},
+		{"missing column", "title=", nil, true},
+		{"missing field", "=名称", nil, true},
+		{"no separator", "title", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMapping(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMapping(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMapping(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []readRow
+		wantErr bool
+	}{
+		{
+			name:  "header with bom and spaces",
+			input: "\ufefftitle, size\n电影A,1GB\n",
+			want:  []readRow{{row: 1, rec: record{"title": "电影A", "size": "1GB"}}},
+		},
+		{
+			// 列数不足的行缺少的列不出现在记录中
+			name:  "short row",
+			input: "title,size\n电影A\n",
+			want:  []readRow{{row: 1, rec: record{"title": "电影A"}}},
+		},
+		{
+			// 引号错误只影响该行，后续行继续读取
+			name:  "parse error continues",
+			input: "title,size\n\"电影A,1GB\n",
+			want:  []readRow{{row: 1, err: true}},
+		},
+		{
+			name:  "quoted comma",
+			input: "title,tags\n电影A,\"动作,科幻\"\n电影B,\n",
+			want: []readRow{
+				{row: 1, rec: record{"title": "电影A", "tags": "动作,科幻"}},
+				{row: 2, rec: record{"title": "电影B", "tags": ""}},
+			},
+		},
+		{name: "empty file", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := collect(func(handle func(int, record, error)) error {
+				return readCSV(strings.NewReader(tt.input), handle)
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readCSV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readCSV() rows = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadJSONL(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []readRow
+	}{
+		{
+			name:  "value types",
+			input: `{"title":"电影A","file_count":3,"valid":false,"tags":["动作","科幻"],"source":null}`,
+			want: []readRow{{row: 1, rec: record{
+				"title": "电影A", "file_count": "3", "valid": "false", "tags": "动作,科幻", "source": "",
+			}}},
+		},
+		{
+			// 空行不计入行号
+			name:  "blank lines",
+			input: "\n{\"title\":\"电影A\"}\n  \n{\"title\":\"电影B\"}\n",
+			want: []readRow{
+				{row: 1, rec: record{"title": "电影A"}},
+				{row: 2, rec: record{"title": "电影B"}},
+			},
+		},
+		{
+			name:  "invalid json continues",
+			input: "{\"title\":\n{\"title\":\"电影B\"}\n",
+			want: []readRow{
+				{row: 1, err: true},
+				{row: 2, rec: record{"title": "电影B"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := collect(func(handle func(int, record, error)) error {
+				return readJSONL(strings.NewReader(tt.input), handle)
+			})
+			if err != nil {
+				t.Fatalf("readJSONL() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readJSONL() rows = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildInput(t *testing.T) {
+	valid := record{
+		"title": "电影A", "size": "1GB", "type": "video", "category": "Movie",
+		"download_url": "https://pan.baidu.com/s/1abc",
+	}
+	with := func(changes record) record {
+		rec := make(record, len(valid)+len(changes))
+		for k, v := range valid {
+			rec[k] = v
+		}
+		for k, v := range changes {
+			rec[k] = v
+		}
+		return rec
+	}
+	expire := time.Date(2026, 1, 2, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		mapping map[string]string
+		rec     record
+		check   func(models.ResourceCreate) bool
+		wantErr string
+	}{
+		{
+			name:  "required fields",
+			rec:   valid,
+			check: func(in models.ResourceCreate) bool { return in.Title == "电影A" && in.CategoryID == 1 },
+		},
+		{
+			name: "aliases",
+			rec: record{
+				"title": "电影A", "size": "1GB", "type": "video", "category": "music",
+				"url": "https://pan.baidu.com/s/1abc", "code": "abcd", "fileCount": "3", "expireTime": "2026-01-02",
+			},
+			check: func(in models.ResourceCreate) bool {
+				return in.DownloadURL == "https://pan.baidu.com/s/1abc" && in.ExtractCode == "abcd" &&
+					in.FileCount == 3 && in.CategoryID == 2 && in.ExpireTime != nil && in.ExpireTime.Equal(expire)
+			},
+		},
+		{
+			// 自定义映射优先于同名列
+			name:    "mapping",
+			mapping: map[string]string{"title": "名称"},
+			rec:     with(record{"名称": "电影B"}),
+			check:   func(in models.ResourceCreate) bool { return in.Title == "电影B" },
+		},
+		{
+			name: "optional fields",
+			rec:  with(record{"valid": "false", "tags": "动作；科幻|悬疑", "upload_time": "2026-01-02 00:00:00"}),
+			check: func(in models.ResourceCreate) bool {
+				return in.Valid != nil && !*in.Valid && reflect.DeepEqual(in.Tags, []string{"动作", "科幻", "悬疑"}) &&
+					in.UploadTime != nil && in.UploadTime.Equal(expire)
+			},
+		},
+		{name: "missing fields", rec: record{"title": " ", "category": "movie"}, wantErr: "缺少必填字段: title, size, type, download_url"},
+		{name: "missing category", rec: with(record{"category": ""}), wantErr: "缺少必填字段: category"},
+		{name: "unknown category", rec: with(record{"category": "game"}), wantErr: "分类不存在: game"},
+		{name: "bad file count", rec: with(record{"file_count": "-1"}), wantErr: "file_count格式错误: -1"},
+		{name: "bad valid", rec: with(record{"valid": "maybe"}), wantErr: "valid格式错误: maybe"},
+		{name: "bad time", rec: with(record{"expire_time": "2026/01/02"}), wantErr: "expire_time格式错误: 2026/01/02"},
+		{name: "too long", rec: with(record{"size": strings.Repeat("1", 21)}), wantErr: "size超过最大长度20"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			imp := &importer{opts: Options{Mapping: tt.mapping}, categories: testCategories}
+			got, err := imp.buildInput(tt.rec)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("buildInput() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildInput() error = %v", err)
+			}
+			if !tt.check(got) {
+				t.Errorf("buildInput() = %+v", got)
+			}
+		})
+	}
+}
+
+// testDB 每个测试使用独立的内存SQLite数据库，包含testCategories中的分类
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Category{}, &models.Resource{}, &models.ResourceTag{}); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	for value, id := range testCategories {
+		if err := db.Create(&models.Category{ID: id, Value: value, Label: value, Icon: "🎬"}).Error; err != nil {
+			t.Fatalf("create category %s: %v", value, err)
+		}
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func TestImportDedup(t *testing.T) {
+	tests := []struct {
+		name   string
+		dryRun bool
+	}{
+		{"dry run", true},
+		{"import", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			if _, err := services.CreateResource(db, models.ResourceCreate{
+				Title: "已收录", Size: "1GB", Type: "video", CategoryID: 1,
+				DownloadURL: "https://pan.baidu.com/s/1exists",
+			}); err != nil {
+				t.Fatalf("create resource: %v", err)
+			}
+
+			input := strings.Join([]string{
+				"title,size,type,category,download_url,source",
+				"新资源,1GB,video,movie,https://pan.baidu.com/s/1new,",
+				// 同一分享的另一种写法，与第1行重复
+				"新资源副本,1GB,video,movie,https://pan.baidu.com/s/1new?pwd=abcd,",
+				// 数据库中已有该分享
+				"已收录副本,1GB,video,movie,pan.baidu.com/s/1exists,",
+				"无分类,1GB,video,game,https://pan.baidu.com/s/1other,",
+				// 无法识别的链接按原文去重，需要来源
+				"外部资源,1GB,video,music,https://example.com/file,example",
+				"外部资源副本,1GB,video,music,https://example.com/file,example",
+				"无来源,1GB,video,music,https://example.com/other,",
+			}, "\n")
+
+			report, err := Import(db, strings.NewReader(input), Options{Format: FormatCSV, DryRun: tt.dryRun})
+			if err != nil {
+				t.Fatalf("Import() error = %v", err)
+			}
+			if report.DryRun != tt.dryRun || report.Total != 7 || report.Created != 2 || report.Skipped != 3 || report.Failed != 2 {
+				t.Errorf("Import() report = %+v, want total 7, created 2, skipped 3, failed 2", report)
+			}
+
+			wantErrors := []RowError{
+				{Row: 2, Title: "新资源副本", Reason: "与第1行的下载链接重复", Skipped: true},
+				{Row: 3, Title: "已收录副本", Reason: "下载链接已存在", Skipped: true},
+				{Row: 4, Title: "无分类", Reason: "分类不存在: game"},
+				{Row: 6, Title: "外部资源副本", Reason: "与第5行的下载链接重复", Skipped: true},
+				{Row: 7, Title: "无来源", Reason: services.ErrSourceRequired.Error()},
+			}
+			if !reflect.DeepEqual(report.Errors, wantErrors) {
+				t.Errorf("Import() errors = %+v, want %+v", report.Errors, wantErrors)
+			}
+
+			// 演练模式不写入数据库
+			wantCount, wantIDs := int64(3), 2
+			if tt.dryRun {
+				wantCount, wantIDs = 1, 0
+			}
+			var count int64
+			if err := db.Model(&models.Resource{}).Count(&count).Error; err != nil {
+				t.Fatalf("count resources: %v", err)
+			}
+			if count != wantCount || len(report.ResourceIDs) != wantIDs {
+				t.Errorf("resources = %d, resourceIds = %v, want %d resources and %d ids", count, report.ResourceIDs, wantCount, wantIDs)
+			}
+		})
+	}
+}
+
+func TestImportOptions(t *testing.T) {
+	db := testDB(t)
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"unknown mapping field", Options{Format: FormatCSV, Mapping: map[string]string{"name": "名称"}}},
+		{"unknown format", Options{Format: "xlsx"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Import(db, strings.NewReader(""), tt.opts); err == nil {
+				t.Errorf("Import(%+v) error = nil, want error", tt.opts)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"pan-search-api/common"
+	"pan-search-api/config"
+	"pan-search-api/models"
+	"pan-search-api/services"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 链接检测默认配置
+const (
+	defaultCheckInterval    = time.Minute
+	defaultBatchSize        = 50
+	defaultConcurrency      = 4
+	defaultTimeout          = 10 * time.Second
+	defaultFailureThreshold = 3
+	defaultUserAgent        = "Mozilla/5.0 (compatible; pan-search-linkcheck/1.0)"
+)
+
+// 检测周期，热门和新上传的资源检测得更频繁
+const (
+	hotCheckPeriod    = 6 * time.Hour
+	normalCheckPeriod = 24 * time.Hour
+	coldCheckPeriod   = 72 * time.Hour
+	// 检测到失效后尽快复查，避免偶发错误导致资源被误标记失效
+	deadRetryPeriod = time.Hour
+	// 无法判断时稍后重试
+	unknownRetryPeriod = 2 * time.Hour
+)
+
+// 资源热度和上传时间的分档
+const (
+	hotPopularity  = 1000 // 浏览量加下载量
+	coldPopularity = 10
+	newResourceAge = 7 * 24 * time.Hour
+	oldResourceAge = 180 * 24 * time.Hour
+)
+
+// 每个资源保留的检测记录数
+const maxHistory = 50
+
+// 立即检测队列的长度
+const queueSize = 100
+
+// checkColumns 检测时需要的资源字段
+var checkColumns = []string{
+	"id", "download_url", "extract_code", "view_count", "download_count",
+	"valid", "upload_time", "link_status", "check_failures",
+}
+
+// Checker 定时检测到期资源的分享链接，连续失效达到阈值后标记资源失效
+type Checker struct {
+	db        *gorm.DB
+	cfg       config.LinkCheckConfig
+	client    *http.Client
+	public    *http.Client // 只能访问公网地址，用于没有专用检测器的链接
+	probes    []Probe
+	queue     chan string
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup // 定时检测的goroutine
+}
+
+var (
+	checkerMu      sync.RWMutex
+	defaultChecker *Checker
+)
+
+// Start 创建检测器并设为默认实例，配置启用时在后台定时检测。
+// 未启用时仍可以通过CheckResource手动检测
+func Start(db *gorm.DB, cfg config.LinkCheckConfig) *Checker {
+	c := NewChecker(db, cfg)
+	if cfg.Enabled {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.loop()
+		}()
+	}
+
+	checkerMu.Lock()
+	defaultChecker = c
+	checkerMu.Unlock()
+	return c
+}
+
+// Default 返回默认实例，未启动时返回nil
+func Default() *Checker {
+	checkerMu.RLock()
+	defer checkerMu.RUnlock()
+	return defaultChecker
+}
+
+// NewChecker 创建检测器，不启动定时检测
+func NewChecker(db *gorm.DB, cfg config.LinkCheckConfig) *Checker {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultCheckInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defaultConcurrency
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaultFailureThreshold
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = defaultUserAgent
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Checker{
+		db:  db,
+		cfg: cfg,
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: &common.UserAgentTransport{UserAgent: cfg.UserAgent},
+		},
+		public: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: &common.UserAgentTransport{UserAgent: cfg.UserAgent, Base: publicTransport()},
+		},
+		probes: newProbes(cfg.Hosts),
+		queue:  make(chan string, queueSize),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+}
+
+// Stop 停止定时检测并取消正在进行的检测，等待检测结果写入完成后返回
+func (c *Checker) Stop() {
+	c.closeOnce.Do(func() {
+		c.cancel()
+		close(c.done)
+	})
+	c.wg.Wait()
+}
+
+// Enqueue 将资源加入立即检测队列，未启用定时检测或队列已满时返回false
+func (c *Checker) Enqueue(id string) bool {
+	if !c.cfg.Enabled {
+		return false
+	}
+	select {
+	case c.queue <- id:
+		return true
+	default:
+		return false
+	}
+}
+
+// loop 立即检测一轮，之后按间隔检测到期的资源，其间处理立即检测队列
+func (c *Checker) loop() {
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+
+	c.runDue()
+	for {
+		select {
+		case <-ticker.C:
+			c.runDue()
+		case id := <-c.queue:
+			if _, err := c.CheckResource(c.ctx, id); err != nil && !errors.Is(err, services.ErrResourceNotFound) {
+				log.Printf("Failed to check resource %s: %v", id, err)
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// runDue 检测一批到期的有效资源，从未检测过的资源优先，其中热门的在前
+func (c *Checker) runDue() {
+	var resources []models.Resource
+	if err := c.db.Select(checkColumns).
+		Where("valid = ? AND (next_check_at IS NULL OR next_check_at <= ?)", true, time.Now()).
+		Order("next_check_at IS NULL DESC, next_check_at ASC, view_count + download_count DESC").
+		Limit(c.cfg.BatchSize).
+		Find(&resources).Error; err != nil {
+		log.Printf("Failed to load resources due for link check: %v", err)
+		return
+	}
+
+	jobs := make(chan *models.Resource)
+	var wg sync.WaitGroup
+	for i := 0; i < c.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for resource := range jobs {
+				if _, err := c.check(c.ctx, resource); err != nil {
+					log.Printf("Failed to check resource %s: %v", resource.ID, err)
+				}
+			}
+		}()
+	}
+	for i := range resources {
+		if c.ctx.Err() != nil {
+			break
+		}
+		jobs <- &resources[i]
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// CheckResource 立即检测资源的分享链接并记录结果，已失效的资源同样可以检测
+func (c *Checker) CheckResource(ctx context.Context, id string) (*models.LinkCheck, error) {
+	var resource models.Resource
+	if err := c.db.Select(checkColumns).First(&resource, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, services.ErrResourceNotFound
+		}
+		return nil, err
+	}
+	return c.check(ctx, &resource)
+}
+
+// check 检测链接，保存检测记录和资源的检测状态，连续失效达到阈值时标记资源失效
+func (c *Checker) check(ctx context.Context, resource *models.Resource) (*models.LinkCheck, error) {
+	start := time.Now()
+	provider, result := c.probe(ctx, resource)
+	// 检测被取消时不记录结果，下次启动后重新检测
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	now := time.Now()
+	record := models.LinkCheck{
+		ResourceID: resource.ID,
+		Provider:   provider,
+		Status:     result.Status,
+		HTTPStatus: result.HTTPStatus,
+		Detail:     common.Truncate(result.Detail, 255),
+		DurationMs: now.Sub(start).Milliseconds(),
+		CheckedAt:  now,
+	}
+	disable := applyResult(resource, result, now, c.cfg.FailureThreshold)
+
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&record).Error; err != nil {
+			return err
+		}
+		// 检测状态不属于资源内容，不更新updated_at
+		if err := tx.Model(&models.Resource{}).Where("id = ?", resource.ID).UpdateColumns(map[string]interface{}{
+			"link_status":     resource.LinkStatus,
+			"check_failures":  resource.CheckFailures,
+			"last_checked_at": resource.LastCheckedAt,
+			"next_check_at":   resource.NextCheckAt,
+		}).Error; err != nil {
+			return err
+		}
+		return pruneHistory(tx, resource.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if disable {
+		log.Printf("Resource %s marked invalid after %d failed link checks", resource.ID, resource.CheckFailures)
+		if _, err := services.SetResourceValid(c.db, resource.ID, false); err != nil {
+			return &record, err
+		}
+	}
+	return &record, nil
+}
+
+// probe 选择检测器检测链接，链接格式错误时直接判定失效
+func (c *Checker) probe(ctx context.Context, resource *models.Resource) (string, Result) {
+	link, err := url.Parse(resource.DownloadURL)
+	if err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		return genericProbe{}.Name(), Result{Status: StatusDead, Detail: "链接格式错误"}
+	}
+
+	probe := findProbe(c.probes, link)
+	// 专用检测器只访问网盘或配置的检测地址，其他链接可能指向任意地址，只允许访问公网
+	client := c.client
+	if _, ok := probe.(genericProbe); ok {
+		client = c.public
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+	return probe.Name(), probe.Check(ctx, client, Link{URL: link, ExtractCode: resource.ExtractCode})
+}
+
+// applyResult 根据检测结果更新资源的检测状态和下次检测时间，
+// 返回是否因连续失效达到阈值需要标记资源失效
+func applyResult(resource *models.Resource, result Result, now time.Time, threshold int) bool {
+	resource.LinkStatus = result.Status
+	resource.LastCheckedAt = &now
+
+	period := checkPeriod(resource, now)
+	switch result.Status {
+	case StatusAlive:
+		resource.CheckFailures = 0
+	case StatusDead:
+		resource.CheckFailures++
+		period = deadRetryPeriod
+	default:
+		// 无法判断时不改变连续失效次数
+		period = min(period, unknownRetryPeriod)
+	}
+	next := now.Add(period)
+	resource.NextCheckAt = &next
+
+	return resource.Valid && result.Status == StatusDead && int(resource.CheckFailures) >= threshold
+}
+
+// checkPeriod 按资源的热度和上传时间计算检测周期
+func checkPeriod(resource *models.Resource, now time.Time) time.Duration {
+	popularity := resource.ViewCount + resource.DownloadCount
+	age := now.Sub(resource.UploadTime)
+	switch {
+	case popularity >= hotPopularity || age < newResourceAge:
+		return hotCheckPeriod
+	case popularity < coldPopularity && age > oldResourceAge:
+		return coldCheckPeriod
+	default:
+		return normalCheckPeriod
+	}
+}
+
+// History 查询资源最近的检测记录，按检测时间倒序
+func History(db *gorm.DB, resourceID string, limit int) ([]models.LinkCheck, error) {
+	var checks []models.LinkCheck
+	err := db.Where("resource_id = ?", resourceID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&checks).Error
+	return checks, err
+}
+
+// pruneHistory 只保留资源最近的maxHistory条检测记录
+func pruneHistory(tx *gorm.DB, resourceID string) error {
+	var ids []uint
+	if err := tx.Model(&models.LinkCheck{}).
+		Where("resource_id = ?", resourceID).
+		Order("id DESC").
+		Offset(maxHistory).
+		Limit(1).
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return tx.Where("resource_id = ? AND id <= ?", resourceID, ids[0]).Delete(&models.LinkCheck{}).Error
+}
@@ -0,0 +1,273 @@
+package linkcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"pan-search-api/config"
+	"pan-search-api/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubServer 模拟各网盘的分享页面和接口
+func stubServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/s/1alive", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html><title>百度网盘 请输入提取码</title></html>"))
+	})
+	mux.HandleFunc("/s/1deleted", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>啊哦，你来晚了，分享的文件已经被删除了，下次要早点哟。</html>"))
+	})
+	mux.HandleFunc("/s/1busy", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	mux.HandleFunc("/adrive/v3/share_link/get_share_by_anonymous", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ShareID string `json:"share_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		switch body.ShareID {
+		case "alive":
+			w.Write([]byte(`{"share_name":"test","file_count":1}`))
+		case "cancelled":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"ShareLink.Cancelled","message":"share link cancelled"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"code":"InternalError","message":"internal error"}`))
+		}
+	})
+
+	mux.HandleFunc("/1/clouddrive/share/sharepage/token", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			PwdID    string `json:"pwd_id"`
+			Passcode string `json:"passcode"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		switch {
+		case body.PwdID == "expired":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":404,"code":41006,"message":"分享地址已失效"}`))
+		case body.Passcode != "abcd":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":400,"code":41008,"message":"提取码错误"}`))
+		default:
+			w.Write([]byte(`{"status":200,"code":0,"message":"ok"}`))
+		}
+	})
+
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProbes(t *testing.T) {
+	server := stubServer(t)
+	probes := newProbes(map[string]string{
+		"baidu":  server.URL,
+		"aliyun": server.URL,
+		"quark":  server.URL,
+	})
+
+	tests := []struct {
+		name     string
+		link     string
+		code     string
+		provider string
+		status   string
+	}{
+		{"baidu alive", "https://pan.baidu.com/s/1alive", "", "baidu", StatusAlive},
+		{"baidu deleted", "https://pan.baidu.com/s/1deleted?pwd=abcd", "", "baidu", StatusDead},
+		{"baidu not found", "https://pan.baidu.com/s/1missing", "", "baidu", StatusDead},
+		{"baidu rate limited", "https://pan.baidu.com/s/1busy", "", "baidu", StatusUnknown},
+		{"aliyun alive", "https://www.aliyundrive.com/s/alive", "", "aliyun", StatusAlive},
+		{"alipan cancelled", "https://www.alipan.com/s/cancelled", "", "aliyun", StatusDead},
+		{"aliyun server error", "https://www.aliyundrive.com/s/other", "", "aliyun", StatusUnknown},
+		{"aliyun without share id", "https://www.aliyundrive.com/drive", "", "aliyun", StatusDead},
+		{"quark alive", "https://pan.quark.cn/s/alive", "abcd", "quark", StatusAlive},
+		{"quark expired", "https://pan.quark.cn/s/expired", "abcd", "quark", StatusDead},
+		{"quark wrong code", "https://pan.quark.cn/s/alive", "zzzz", "quark", StatusUnknown},
+		{"generic gone", server.URL + "/gone", "", "http", StatusDead},
+		{"generic ok", server.URL + "/ok", "", "http", StatusAlive},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link, err := url.Parse(tt.link)
+			if err != nil {
+				t.Fatal(err)
+			}
+			probe := findProbe(probes, link)
+			if probe.Name() != tt.provider {
+				t.Fatalf("provider = %s, want %s", probe.Name(), tt.provider)
+			}
+			result := probe.Check(context.Background(), server.Client(), Link{URL: link, ExtractCode: tt.code})
+			if result.Status != tt.status {
+				t.Errorf("status = %s (%d %s), want %s", result.Status, result.HTTPStatus, result.Detail, tt.status)
+			}
+		})
+	}
+}
+
+func TestProbeUnreachable(t *testing.T) {
+	server := stubServer(t)
+	probes := newProbes(map[string]string{"baidu": server.URL})
+	server.Close()
+
+	link, _ := url.Parse("https://pan.baidu.com/s/1alive")
+	result := findProbe(probes, link).Check(context.Background(), http.DefaultClient, Link{URL: link})
+	if result.Status != StatusUnknown {
+		t.Errorf("status = %s, want %s", result.Status, StatusUnknown)
+	}
+}
+
+func TestApplyResultFailureThreshold(t *testing.T) {
+	now := time.Now()
+	resource := &models.Resource{Valid: true, UploadTime: now.Add(-30 * 24 * time.Hour), ViewCount: 100}
+
+	// 无法判断的结果不计入连续失效次数
+	steps := []struct {
+		status   string
+		failures uint
+		disable  bool
+	}{
+		{StatusDead, 1, false},
+		{StatusUnknown, 1, false},
+		{StatusDead, 2, false},
+		{StatusAlive, 0, false},
+		{StatusDead, 1, false},
+		{StatusDead, 2, false},
+		{StatusDead, 3, true},
+	}
+	for i, step := range steps {
+		disable := applyResult(resource, Result{Status: step.status}, now, 3)
+		if resource.CheckFailures != step.failures || disable != step.disable {
+			t.Fatalf("step %d: failures = %d, disable = %v, want %d, %v",
+				i, resource.CheckFailures, disable, step.failures, step.disable)
+		}
+		if resource.LinkStatus != step.status {
+			t.Fatalf("step %d: link status = %s, want %s", i, resource.LinkStatus, step.status)
+		}
+	}
+
+	// 已失效的资源不再重复标记
+	resource.Valid = false
+	if applyResult(resource, Result{Status: StatusDead}, now, 3) {
+		t.Error("invalid resource should not be disabled again")
+	}
+}
+
+func TestApplyResultSchedule(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		resource models.Resource
+		status   string
+		want     time.Duration
+	}{
+		{"new resource", models.Resource{UploadTime: now.Add(-24 * time.Hour)}, StatusAlive, hotCheckPeriod},
+		{"popular resource", models.Resource{UploadTime: now.Add(-365 * 24 * time.Hour), ViewCount: 900, DownloadCount: 200}, StatusAlive, hotCheckPeriod},
+		{"normal resource", models.Resource{UploadTime: now.Add(-30 * 24 * time.Hour), ViewCount: 50}, StatusAlive, normalCheckPeriod},
+		{"old unpopular resource", models.Resource{UploadTime: now.Add(-365 * 24 * time.Hour), ViewCount: 3}, StatusAlive, coldCheckPeriod},
+		{"dead retries soon", models.Resource{UploadTime: now.Add(-365 * 24 * time.Hour)}, StatusDead, deadRetryPeriod},
+		{"unknown retries later", models.Resource{UploadTime: now.Add(-365 * 24 * time.Hour)}, StatusUnknown, unknownRetryPeriod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource := tt.resource
+			applyResult(&resource, Result{Status: tt.status}, now, 3)
+			if got := resource.NextCheckAt.Sub(now); got != tt.want {
+				t.Errorf("next check in %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.UserAgent()
+	}))
+	defer server.Close()
+
+	c := NewChecker(nil, config.LinkCheckConfig{UserAgent: "test-agent"})
+	link, _ := url.Parse(server.URL)
+	genericProbe{}.Check(context.Background(), c.client, Link{URL: link})
+	if got != "test-agent" {
+		t.Errorf("user agent = %q, want %q", got, "test-agent")
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"8.8.8.8", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::ffff:127.0.0.1", false},
+		{"224.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := isPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestGenericProbePrivateAddress(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer server.Close()
+
+	c := NewChecker(nil, config.LinkCheckConfig{})
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"loopback", server.URL + "/ok"},
+		{"metadata", "http://169.254.169.254/latest/meta-data/"},
+		{"private", "http://10.0.0.1/s/1abc"},
+		{"localhost", strings.Replace(server.URL, "127.0.0.1", "localhost", 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, result := c.probe(context.Background(), &models.Resource{DownloadURL: tt.url})
+			if provider != "http" || result.Status != StatusUnknown || !strings.Contains(result.Detail, errPrivateAddress.Error()) {
+				t.Errorf("probe(%s) = %s, %+v, want unknown with %q", tt.url, provider, result, errPrivateAddress)
+			}
+		})
+	}
+	if requested {
+		t.Errorf("request reached the loopback server")
+	}
+}
@@ -0,0 +1,131 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// 检测结果
+const (
+	StatusAlive   = "alive"
+	StatusDead    = "dead"
+	StatusUnknown = "unknown" // 网络错误、被限流或页面无法识别，不计入连续失效次数
+)
+
+// 读取响应体的上限，判断分享状态只需要页面开头的部分
+const maxBodySize = 512 * 1024
+
+// errPrivateAddress 链接指向本机、内网或链路本地地址
+var errPrivateAddress = errors.New("拒绝访问本机或内网地址")
+
+// Link 待检测的分享链接
+type Link struct {
+	URL         *url.URL
+	ExtractCode string
+}
+
+// Result 单次检测的结果
+type Result struct {
+	Status     string
+	HTTPStatus int
+	Detail     string
+}
+
+// Probe 网盘检测器，每个网盘根据自己的页面或接口判断分享是否有效
+type Probe interface {
+	// Name 网盘名，也是配置中hosts的键
+	Name() string
+	// Match 判断链接是否属于该网盘
+	Match(link *url.URL) bool
+	// Check 检测分享链接，网络错误等无法判断的情况返回StatusUnknown
+	Check(ctx context.Context, client *http.Client, link Link) Result
+}
+
+// hostMatcher 按分享链接的域名匹配网盘
+type hostMatcher []string
+
+// Match 域名相同或为其子域名时匹配
+func (m hostMatcher) Match(link *url.URL) bool {
+	host := strings.ToLower(link.Hostname())
+	for _, domain := range m {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// rebase 将分享链接的路径和参数拼接到配置的检测地址上，base为空时使用原链接
+func rebase(base string, link *url.URL) string {
+	if base == "" {
+		return link.String()
+	}
+	u := *link
+	target, err := url.Parse(base)
+	if err != nil {
+		return link.String()
+	}
+	u.Scheme = target.Scheme
+	u.Host = target.Host
+	u.Path = strings.TrimSuffix(target.Path, "/") + link.Path
+	return u.String()
+}
+
+// readBody 读取响应体，超过上限的部分丢弃
+func readBody(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	return string(body)
+}
+
+// unknown 无法判断时的结果
+func unknown(httpStatus int, detail string) Result {
+	return Result{Status: StatusUnknown, HTTPStatus: httpStatus, Detail: detail}
+}
+
+// containsAny 判断页面中是否出现任一关键字，返回出现的关键字
+func containsAny(body string, markers []string) (string, bool) {
+	for _, marker := range markers {
+		if strings.Contains(body, marker) {
+			return marker, true
+		}
+	}
+	return "", false
+}
+
+// publicTransport 只允许连接公网地址的Transport，用于检测没有专用检测器的链接，
+// 防止通过资源链接访问本机、内网服务或云服务器的元数据接口。
+// 在建立连接时检查解析后的地址，重定向和域名解析到内网地址同样被拒绝；不使用环境变量中的代理
+func publicTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return errPrivateAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// isPublicIP 是否为公网地址
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast()
+}
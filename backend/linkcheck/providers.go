@@ -0,0 +1,239 @@
+package linkcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// 各网盘检测接口的默认地址，可以通过配置的hosts覆盖
+var defaultHosts = map[string]string{
+	"baidu":  "https://pan.baidu.com",
+	"aliyun": "https://api.aliyundrive.com",
+	"quark":  "https://drive-h.quark.cn",
+}
+
+// newProbes 按配置创建检测器，没有专用检测器的链接由通用检测器处理
+func newProbes(hosts map[string]string) []Probe {
+	host := func(name string) string {
+		if h, ok := hosts[name]; ok && h != "" {
+			return h
+		}
+		return defaultHosts[name]
+	}
+	return []Probe{
+		&baiduProbe{base: host("baidu")},
+		&aliyunProbe{base: host("aliyun")},
+		&quarkProbe{base: host("quark")},
+	}
+}
+
+// findProbe 查找链接对应的检测器
+func findProbe(probes []Probe, link *url.URL) Probe {
+	for _, probe := range probes {
+		if probe.Match(link) {
+			return probe
+		}
+	}
+	return genericProbe{}
+}
+
+// shareID 取出/s/{id}形式链接中的分享ID
+func shareID(link *url.URL) string {
+	id, ok := strings.CutPrefix(link.Path, "/s/")
+	if !ok {
+		return ""
+	}
+	id, _, _ = strings.Cut(id, "/")
+	return id
+}
+
+// newRequest 创建检测请求，body不为nil时以JSON发送
+func newRequest(ctx context.Context, method, target string, body interface{}) (*http.Request, error) {
+	if body == nil {
+		return http.NewRequestWithContext(ctx, method, target, nil)
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// baiduProbe 百度网盘，分享页面返回200，通过页面内容判断分享是否失效
+type baiduProbe struct {
+	base string
+}
+
+// 百度网盘分享失效时页面中出现的提示
+var baiduDeadMarkers = []string{
+	"链接不存在",
+	"分享的文件已经被删除",
+	"分享的文件已经被取消",
+	"此链接分享内容可能因为涉及侵权",
+	"分享已过期",
+}
+
+func (p *baiduProbe) Name() string { return "baidu" }
+
+func (p *baiduProbe) Match(link *url.URL) bool {
+	return hostMatcher{"pan.baidu.com", "yun.baidu.com"}.Match(link)
+}
+
+func (p *baiduProbe) Check(ctx context.Context, client *http.Client, link Link) Result {
+	req, err := newRequest(ctx, http.MethodGet, rebase(p.base, link.URL), nil)
+	if err != nil {
+		return unknown(0, err.Error())
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return unknown(0, err.Error())
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return Result{Status: StatusDead, HTTPStatus: resp.StatusCode, Detail: "分享页面不存在"}
+	case resp.StatusCode != http.StatusOK:
+		return unknown(resp.StatusCode, resp.Status)
+	}
+	if marker, ok := containsAny(readBody(resp), baiduDeadMarkers); ok {
+		return Result{Status: StatusDead, HTTPStatus: resp.StatusCode, Detail: marker}
+	}
+	return Result{Status: StatusAlive, HTTPStatus: resp.StatusCode}
+}
+
+// aliyunProbe 阿里云盘，通过匿名获取分享信息的接口判断
+type aliyunProbe struct {
+	base string
+}
+
+// 阿里云盘分享失效时接口返回的错误码
+var aliyunDeadCodes = map[string]bool{
+	"ShareLink.Cancelled": true,
+	"ShareLink.Expired":   true,
+	"ShareLink.Forbidden": true,
+	"NotFound.ShareLink":  true,
+}
+
+func (p *aliyunProbe) Name() string { return "aliyun" }
+
+func (p *aliyunProbe) Match(link *url.URL) bool {
+	return hostMatcher{"aliyundrive.com", "alipan.com"}.Match(link)
+}
+
+func (p *aliyunProbe) Check(ctx context.Context, client *http.Client, link Link) Result {
+	id := shareID(link.URL)
+	if id == "" {
+		return Result{Status: StatusDead, Detail: "链接中没有分享ID"}
+	}
+
+	target := strings.TrimSuffix(p.base, "/") + "/adrive/v3/share_link/get_share_by_anonymous?share_id=" + url.QueryEscape(id)
+	req, err := newRequest(ctx, http.MethodPost, target, map[string]string{"share_id": id})
+	if err != nil {
+		return unknown(0, err.Error())
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return unknown(0, err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return Result{Status: StatusAlive, HTTPStatus: resp.StatusCode}
+	}
+	var body struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(readBody(resp)), &body); err == nil && aliyunDeadCodes[body.Code] {
+		return Result{Status: StatusDead, HTTPStatus: resp.StatusCode, Detail: body.Code}
+	}
+	return unknown(resp.StatusCode, strings.TrimSpace(body.Code+" "+body.Message))
+}
+
+// quarkProbe 夸克网盘，通过获取分享token的接口判断，需要提交提取码
+type quarkProbe struct {
+	base string
+}
+
+// 夸克网盘分享失效时接口返回的提示
+var quarkDeadMarkers = []string{"失效", "取消", "删除", "不存在", "违规", "过期"}
+
+func (p *quarkProbe) Name() string { return "quark" }
+
+func (p *quarkProbe) Match(link *url.URL) bool {
+	return hostMatcher{"pan.quark.cn"}.Match(link)
+}
+
+func (p *quarkProbe) Check(ctx context.Context, client *http.Client, link Link) Result {
+	id := shareID(link.URL)
+	if id == "" {
+		return Result{Status: StatusDead, Detail: "链接中没有分享ID"}
+	}
+
+	target := strings.TrimSuffix(p.base, "/") + "/1/clouddrive/share/sharepage/token?pr=ucpro&fr=pc"
+	req, err := newRequest(ctx, http.MethodPost, target, map[string]string{"pwd_id": id, "passcode": link.ExtractCode})
+	if err != nil {
+		return unknown(0, err.Error())
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return unknown(0, err.Error())
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Status  int    `json:"status"`
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(readBody(resp)), &body); err != nil {
+		return unknown(resp.StatusCode, resp.Status)
+	}
+	if resp.StatusCode == http.StatusOK && body.Code == 0 {
+		return Result{Status: StatusAlive, HTTPStatus: resp.StatusCode}
+	}
+	// 提取码错误等情况无法说明分享本身失效
+	if _, ok := containsAny(body.Message, quarkDeadMarkers); ok {
+		return Result{Status: StatusDead, HTTPStatus: resp.StatusCode, Detail: body.Message}
+	}
+	return unknown(resp.StatusCode, fmt.Sprintf("%d %s", body.Code, body.Message))
+}
+
+// genericProbe 没有专用检测器的网盘，只根据HTTP状态码判断
+type genericProbe struct{}
+
+func (genericProbe) Name() string { return "http" }
+
+func (genericProbe) Match(link *url.URL) bool { return true }
+
+func (genericProbe) Check(ctx context.Context, client *http.Client, link Link) Result {
+	req, err := newRequest(ctx, http.MethodGet, link.URL.String(), nil)
+	if err != nil {
+		return unknown(0, err.Error())
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return unknown(0, err.Error())
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return Result{Status: StatusDead, HTTPStatus: resp.StatusCode, Detail: resp.Status}
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return Result{Status: StatusAlive, HTTPStatus: resp.StatusCode}
+	default:
+		return unknown(resp.StatusCode, resp.Status)
+	}
+}
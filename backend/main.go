@@ -1,11 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
+	"time"
 	"pan-search-api/config"
+	"pan-search-api/connector"
 	"pan-search-api/database"
+	"pan-search-api/linkcheck"
 	"pan-search-api/routes"
+	"pan-search-api/search"
+	"pan-search-api/services"
 
 	"github.com/gin-gonic/gin"
 )
@@ -34,26 +45,97 @@ func main() {
 	// 初始化配置
 	config.Init()
 
+	// 服务异常退出时等其余的defer执行完成后再以非零状态码退出，需要最先注册
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			os.Exit(exitCode)
+		}
+	}()
+
 	// 初始化数据库
 	if err := database.Init(); err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 	defer database.Close()
 
+	// 执行命令行子命令
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:]); err != nil {
+			log.Fatalf("Command failed: %v", err)
+		}
+		return
+	}
+
+	// 初始化搜索引擎
+	if err := search.Init(database.DB); err != nil {
+		log.Fatalf("Failed to initialize search engine: %v", err)
+	}
+	defer search.Close()
+
+	// 定时重建搜索建议索引
+	suggester := search.StartSuggester(database.DB, config.GlobalConfig.Search.Suggest)
+	defer suggester.Stop()
+
+	// 定时计算热门趋势
+	trending := services.StartTrending(database.DB, config.GlobalConfig.Trending)
+	defer trending.Stop()
+
+	// 定时将已过期的资源标记失效
+	sweeper := services.StartExpirySweeper(database.DB, config.GlobalConfig.Expiry)
+	defer sweeper.Stop()
+
+	// 定时检测分享链接
+	checker := linkcheck.Start(database.DB, config.GlobalConfig.LinkCheck)
+	defer checker.Stop()
+
+	// 定时从外部来源采集资源
+	runner, err := connector.Start(database.DB, config.GlobalConfig.Connector)
+	if err != nil {
+		log.Fatalf("Failed to start connectors: %v", err)
+	}
+	defer runner.Stop()
+
 	// 设置Gin模式
 	if config.GlobalConfig.App.Mode == "release" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	// 设置路由
-	router := routes.SetupRouter()
+	router, err := routes.SetupRouter()
+	if err != nil {
+		log.Fatalf("Failed to set up router: %v", err)
+	}
 
 	// 启动服务器
 	port := ":" + strconv.Itoa(config.GlobalConfig.App.Port)
 	log.Printf("Server starting on port %s", port)
 	log.Printf("Swagger documentation available at http://localhost%s/swagger/index.html", port)
 
-	if err := router.Run(port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	// 启动失败时将错误交给主goroutine，保证后台任务、搜索引擎和数据库依次关闭
+	server := &http.Server{Addr: port, Handler: router}
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	// 收到退出信号后等待请求处理完成，再关闭搜索引擎和数据库
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case <-quit:
+	case err := <-serverErr:
+		log.Printf("Failed to start server: %v", err)
+		exitCode = 1
+		return
+	}
+	log.Println("Shutting down server...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Server forced to shutdown: %v", err)
 	}
 }
\ No newline at end of file
@@ -1,97 +1,59 @@
 package middleware
 
 import (
+	"errors"
 	"pan-search-api/common"
+	"pan-search-api/database"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware 认证中间件
-func AuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// 获取Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			common.Unauthorized(c, "缺少认证token")
-			c.Abort()
-			return
-		}
+// 上下文中存储认证信息的键
+const (
+	ContextUserID = "userID"
+	ContextRole   = "role"
+	ContextClaims = "claims"
+)
 
-		// 检查Bearer token格式
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			common.Unauthorized(c, "token格式错误")
-			c.Abort()
-			return
-		}
+var (
+	errMissingToken   = errors.New("缺少认证token")
+	errMalformedToken = errors.New("token格式错误")
+	errEmptyToken     = errors.New("token不能为空")
+	errSessionRevoked = errors.New("登录已失效，请重新登录")
+	errUserDisabled   = errors.New("账号已被封禁或停用")
+)
 
-		token := parts[1]
-		if token == "" {
-			common.Unauthorized(c, "token不能为空")
-			c.Abort()
-			return
-		}
+// sessionState 会话及其所属用户的当前状态
+type sessionState struct {
+	Role      string
+	Status    string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
 
-		// TODO: 验证token有效性
-		// 这里可以集成JWT验证或其他认证方式
-		// 暂时简单验证token是否存在
-		if !isValidToken(token) {
-			common.Unauthorized(c, "无效的token")
+// AuthMiddleware 认证中间件
+func AuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := verifyRequest(c)
+		if err != nil {
+			common.Unauthorized(c, err.Error())
 			c.Abort()
 			return
 		}
 
-		// 将用户信息存储到上下文中
-		// 这里可以解析token获取用户信息
-		userID := extractUserIDFromToken(token)
-		c.Set("userID", userID)
-
+		setClaims(c, claims)
 		c.Next()
 	}
 }
 
-// isValidToken 验证token有效性
-func isValidToken(token string) bool {
-	// TODO: 实现实际的token验证逻辑
-	// 这里简单返回true用于演示
-	return token != "invalid"
-}
-
-// extractUserIDFromToken 从token中提取用户ID
-func extractUserIDFromToken(token string) string {
-	// TODO: 实现实际的token解析逻辑
-	// 这里返回一个模拟的用户ID
-	return "user_001"
-}
-
 // OptionalAuthMiddleware 可选认证中间件
 func OptionalAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 获取Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.Next()
-			return
-		}
-
-		// 检查Bearer token格式
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.Next()
-			return
-		}
-
-		token := parts[1]
-		if token == "" {
-			c.Next()
-			return
-		}
-
-		// 验证token有效性
-		if isValidToken(token) {
-			userID := extractUserIDFromToken(token)
-			c.Set("userID", userID)
+		// token缺失或无效时按匿名用户处理
+		if claims, err := verifyRequest(c); err == nil {
+			setClaims(c, claims)
 		}
 
 		c.Next()
@@ -101,53 +63,92 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 // AdminAuthMiddleware 管理员认证中间件
 func AdminAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 先进行普通认证
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			common.Unauthorized(c, "缺少认证token")
-			c.Abort()
-			return
-		}
-
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			common.Unauthorized(c, "token格式错误")
-			c.Abort()
-			return
-		}
-
-		token := parts[1]
-		if token == "" {
-			common.Unauthorized(c, "token不能为空")
+		claims, err := verifyRequest(c)
+		if err != nil {
+			common.Unauthorized(c, err.Error())
 			c.Abort()
 			return
 		}
 
-		if !isValidToken(token) {
-			common.Unauthorized(c, "无效的token")
+		ok, err := HasPermission(claims.Role, PermAdminAccess)
+		if err != nil {
+			common.InternalServerError(c, "权限校验失败")
 			c.Abort()
 			return
 		}
-
-		// TODO: 检查用户是否为管理员
-		// 这里简单验证，实际应该检查用户角色
-		userID := extractUserIDFromToken(token)
-		if !isAdmin(userID) {
+		if !ok {
 			common.Forbidden(c, "需要管理员权限")
 			c.Abort()
 			return
 		}
 
-		c.Set("userID", userID)
+		setClaims(c, claims)
 		c.Set("isAdmin", true)
 
 		c.Next()
 	}
 }
 
-// isAdmin 检查用户是否为管理员
-func isAdmin(userID string) bool {
-	// TODO: 实现实际的管理员检查逻辑
-	// 这里简单返回true用于演示
-	return userID == "admin_001"
-}
\ No newline at end of file
+// verifyRequest 从Authorization头中提取Bearer token并校验，三个认证中间件共用
+func verifyRequest(c *gin.Context) (*common.Claims, error) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return nil, errMissingToken
+	}
+
+	// 检查Bearer token格式
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, errMalformedToken
+	}
+
+	token := strings.TrimSpace(parts[1])
+	if token == "" {
+		return nil, errEmptyToken
+	}
+
+	claims, err := common.ParseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkSession(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// checkSession 校验token关联的会话未被吊销且用户未被封禁，并以数据库中的角色为准
+func checkSession(claims *common.Claims) error {
+	var state sessionState
+	err := database.DB.Table("user_sessions").
+		Select("users.role, users.status, user_sessions.expires_at, user_sessions.revoked_at").
+		Joins("JOIN users ON users.id = user_sessions.user_id").
+		Where("user_sessions.id = ? AND user_sessions.user_id = ?", claims.SessionID, claims.UserID).
+		Take(&state).Error
+	if err != nil {
+		return errSessionRevoked
+	}
+
+	if state.RevokedAt != nil || time.Now().After(state.ExpiresAt) {
+		return errSessionRevoked
+	}
+	if state.Status != "active" {
+		return errUserDisabled
+	}
+
+	claims.Role = state.Role
+	return nil
+}
+
+// setClaims 将用户信息存储到上下文中
+func setClaims(c *gin.Context, claims *common.Claims) {
+	c.Set(ContextUserID, claims.UserID)
+	c.Set(ContextRole, claims.Role)
+	c.Set(ContextClaims, claims)
+}
+
+// GetUserID 获取当前登录用户ID，未登录时返回空字符串
+func GetUserID(c *gin.Context) string {
+	return c.GetString(ContextUserID)
+}
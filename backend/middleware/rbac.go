@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"pan-search-api/common"
+	"pan-search-api/database"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 权限编码
+const (
+	PermAll                = "*"
+	PermAdminAccess        = "admin:access"
+	PermResourceWrite      = "resource:write"
+	PermCategoryWrite      = "category:write"
+	PermHelpRequestRead    = "help_request:read"
+	PermHelpRequestProcess = "help_request:process"
+	PermUserManage         = "user:manage"
+	PermRoleManage         = "role:manage"
+	PermConfigRead         = "config:read"
+	PermConfigWrite        = "config:write"
+	PermDataExport         = "data:export"
+)
+
+// 角色权限缓存有效期，角色权限修改后调用InvalidatePermissionCache立即生效
+const permissionCacheTTL = time.Minute
+
+// permissionCache 角色权限缓存
+type permissionCache struct {
+	mu       sync.RWMutex
+	perms    map[string]map[string]struct{}
+	loadedAt time.Time
+}
+
+var rolePermissions = &permissionCache{}
+
+// RequirePermission 权限校验中间件，需要在认证中间件之后使用，要求同时拥有全部权限
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString(ContextRole)
+		if role == "" {
+			common.Unauthorized(c, "缺少认证token")
+			c.Abort()
+			return
+		}
+
+		for _, permission := range permissions {
+			ok, err := HasPermission(role, permission)
+			if err != nil {
+				common.InternalServerError(c, "权限校验失败")
+				c.Abort()
+				return
+			}
+			if !ok {
+				common.Forbidden(c, "缺少权限: "+permission)
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// HasPermission 检查角色是否拥有指定权限
+func HasPermission(role, permission string) (bool, error) {
+	perms, err := rolePermissions.get(role)
+	if err != nil {
+		return false, err
+	}
+
+	if _, ok := perms[PermAll]; ok {
+		return true, nil
+	}
+	_, ok := perms[permission]
+	return ok, nil
+}
+
+// InvalidatePermissionCache 清空角色权限缓存
+func InvalidatePermissionCache() {
+	rolePermissions.mu.Lock()
+	rolePermissions.perms = nil
+	rolePermissions.mu.Unlock()
+}
+
+// get 获取角色的权限集合，缓存过期时从数据库重新加载
+func (pc *permissionCache) get(role string) (map[string]struct{}, error) {
+	pc.mu.RLock()
+	if pc.perms != nil && time.Since(pc.loadedAt) < permissionCacheTTL {
+		perms := pc.perms[role]
+		pc.mu.RUnlock()
+		return perms, nil
+	}
+	pc.mu.RUnlock()
+
+	var rows []struct {
+		Name string
+		Code string
+	}
+	if err := database.DB.Table("role_permissions").
+		Select("roles.name, permissions.code").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	perms := make(map[string]map[string]struct{})
+	for _, row := range rows {
+		if perms[row.Name] == nil {
+			perms[row.Name] = make(map[string]struct{})
+		}
+		perms[row.Name][row.Code] = struct{}{}
+	}
+
+	pc.mu.Lock()
+	pc.perms = perms
+	pc.loadedAt = time.Now()
+	pc.mu.Unlock()
+
+	return perms[role], nil
+}
@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Category 分类模型
@@ -10,6 +12,7 @@ type Category struct {
 	Value     string    `gorm:"size:50;uniqueIndex;not null" json:"value"`
 	Label     string    `gorm:"size:50;not null" json:"label"`
 	Icon      string    `gorm:"size:20;not null" json:"icon"`
+	ParentID  *uint     `gorm:"index" json:"parent_id"`
 	SortOrder int       `gorm:"default:0" json:"sort_order"`
 	IsActive  bool      `gorm:"default:true" json:"is_active"`
 	CreatedAt time.Time `json:"created_at"`
@@ -20,22 +23,32 @@ type Category struct {
 type Resource struct {
 	ID            string    `gorm:"primaryKey;size:32" json:"id"`
 	Title         string    `gorm:"size:255;not null" json:"title"`
+	TitlePinyin   string    `gorm:"size:1600" json:"-"` // 标题全拼，写入时计算
+	TitleInitials string    `gorm:"size:255" json:"-"`  // 标题拼音首字母，写入时计算
 	Description   string    `gorm:"type:text" json:"description"`
 	Size          string    `gorm:"size:20;not null" json:"size"`
+	SizeBytes     *int64    `gorm:"index" json:"size_bytes"` // 由Size解析的字节数，无法解析时为空
 	Type          string    `gorm:"size:50;not null" json:"type"`
 	CategoryID    uint      `gorm:"not null" json:"category_id"`
 	Category      Category  `gorm:"foreignKey:CategoryID" json:"category"`
 	Source        string    `gorm:"size:100;not null" json:"source"`
 	DownloadURL   string    `gorm:"size:500;not null" json:"download_url"`
+	ShareKey      string    `gorm:"size:100;index" json:"-"` // 网盘分享去重键provider:shareId，无法识别的链接为空
 	ExtractCode   string    `gorm:"size:20" json:"extract_code"`
 	FileCount     uint      `gorm:"default:1" json:"file_count"`
 	ViewCount     uint      `gorm:"default:0" json:"view_count"`
 	DownloadCount uint      `gorm:"default:0" json:"download_count"`
 	Valid         bool      `gorm:"default:true" json:"valid"`
-	ExpireTime    time.Time `json:"expire_time"`
+	InvalidReason string    `gorm:"size:20" json:"invalid_reason"` // 失效原因，过期后被标记失效时为expired，其他情况为空
+	LinkStatus    string     `gorm:"size:20" json:"link_status"`   // 最近一次检测结果alive/dead/unknown，未检测时为空
+	CheckFailures uint       `gorm:"default:0" json:"check_failures"` // 连续检测到链接失效的次数
+	LastCheckedAt *time.Time `json:"last_checked_at"`
+	NextCheckAt   *time.Time `gorm:"index" json:"-"`
+	ExpireTime    *time.Time `json:"expire_time"`
 	UploadTime    time.Time `json:"upload_time"`
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 	Tags          []ResourceTag `gorm:"foreignKey:ResourceID" json:"tags"`
 }
 
@@ -44,9 +57,57 @@ type ResourceTag struct {
 	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
 	ResourceID string    `gorm:"size:32;not null" json:"resource_id"`
 	TagName    string    `gorm:"size:50;not null" json:"tag_name"`
+	Pinyin     string    `gorm:"size:300" json:"-"`
+	Initials   string    `gorm:"size:50" json:"-"`
 	CreatedAt  time.Time `json:"created_at"`
 }
 
+// LinkCheck 分享链接检测记录
+type LinkCheck struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ResourceID string    `gorm:"size:32;not null;index" json:"resource_id"`
+	Provider   string    `gorm:"size:20;not null" json:"provider"`
+	Status     string    `gorm:"size:20;not null" json:"status"` // alive/dead/unknown
+	HTTPStatus int       `json:"http_status"`
+	Detail     string    `gorm:"size:255" json:"detail"`
+	DurationMs int64     `json:"duration_ms"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// ResourceReport 用户举报模型
+type ResourceReport struct {
+	ID          string     `gorm:"primaryKey;size:32" json:"id"`
+	ResourceID  string     `gorm:"size:32;not null;index" json:"resource_id"`
+	Reason      string     `gorm:"size:20;not null" json:"reason"` // dead_link/wrong_code/wrong_category/illegal/other
+	Description string     `gorm:"size:500" json:"description"`
+	UserID      string     `gorm:"size:32" json:"user_id"`
+	IPAddress   string     `gorm:"size:45" json:"ip_address"`
+	Status      string     `gorm:"size:20;default:'pending'" json:"status"` // pending/resolved/dismissed
+	AdminNotes  string     `gorm:"size:500" json:"admin_notes"`
+	ResolvedBy  string     `gorm:"size:32" json:"resolved_by"`
+	ResolvedAt  *time.Time `json:"resolved_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ConnectorState 采集来源的游标和统计
+type ConnectorState struct {
+	Name          string     `gorm:"primaryKey;size:100" json:"name"`
+	Cursor        string     `gorm:"size:255" json:"cursor"` // 已采集条目中最新的发布时间，之前的条目不再处理
+	LastRunAt     *time.Time `json:"last_run_at"`
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	LastError     string     `gorm:"size:500" json:"last_error"`
+	LastFetched   int        `json:"last_fetched"` // 上次采集处理的游标之后的条目数
+	LastCreated   int        `json:"last_created"`
+	LastUpdated   int        `json:"last_updated"`
+	LastSkipped   int        `json:"last_skipped"`
+	LastFailed    int        `json:"last_failed"`
+	Runs          int        `json:"runs"`
+	FailedRuns    int        `json:"failed_runs"`
+	TotalCreated  int        `json:"total_created"`
+	TotalUpdated  int        `json:"total_updated"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
 // HelpRequest 求助请求模型
 type HelpRequest struct {
 	ID              string    `gorm:"primaryKey;size:32" json:"id"`
@@ -57,6 +118,7 @@ type HelpRequest struct {
 	ContactType     string    `gorm:"type:ENUM('email','phone');not null" json:"contact_type"`
 	Status          string    `gorm:"type:ENUM('pending','processing','completed','rejected');default:'pending'" json:"status"`
 	AdminNotes      string    `gorm:"type:text" json:"admin_notes"`
+	AccessTokenHash string    `gorm:"size:64" json:"-"`
 	CompletedTime   time.Time `json:"completed_time"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
@@ -92,15 +154,57 @@ type User struct {
 	ID           string    `gorm:"primaryKey;size:32" json:"id"`
 	Username     string    `gorm:"size:50;uniqueIndex;not null" json:"username"`
 	Email        string    `gorm:"size:100;uniqueIndex;not null" json:"email"`
-	Phone        string    `gorm:"size:20;uniqueIndex" json:"phone"`
+	Phone        *string   `gorm:"size:20;uniqueIndex" json:"phone"`
 	PasswordHash string    `gorm:"size:255;not null" json:"-"`
 	Avatar       string    `gorm:"size:500" json:"avatar"`
+	Role         string    `gorm:"size:20;default:'user'" json:"role"`
 	Status       string    `gorm:"type:ENUM('active','inactive','banned');default:'active'" json:"status"`
 	LastLoginAt  time.Time `json:"last_login_at"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
+// UserSession 用户登录会话，用于刷新token轮换和服务端吊销
+type UserSession struct {
+	ID                string     `gorm:"primaryKey;size:32" json:"id"`
+	UserID            string     `gorm:"size:32;not null;index" json:"user_id"`
+	RefreshTokenHash  string     `gorm:"size:64;not null" json:"-"`
+	PreviousTokenHash string     `gorm:"size:64" json:"-"`
+	UserAgent         string     `gorm:"type:text" json:"user_agent"`
+	IPAddress         string     `gorm:"size:45" json:"ip_address"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+	RevokedAt         *time.Time `json:"revoked_at"`
+	LastUsedAt        time.Time  `json:"last_used_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// Role 角色模型
+type Role struct {
+	ID          uint         `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string       `gorm:"size:20;uniqueIndex;not null" json:"name"`
+	Label       string       `gorm:"size:50;not null" json:"label"`
+	Description string       `gorm:"size:255" json:"description"`
+	IsSystem    bool         `gorm:"default:false" json:"is_system"`
+	Permissions []Permission `gorm:"many2many:role_permissions" json:"permissions"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// Permission 权限模型
+type Permission struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code        string    `gorm:"size:50;uniqueIndex;not null" json:"code"`
+	Description string    `gorm:"size:255" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RolePermission 角色权限关联
+type RolePermission struct {
+	RoleID       uint `gorm:"primaryKey" json:"role_id"`
+	PermissionID uint `gorm:"primaryKey" json:"permission_id"`
+}
+
 // SystemConfig 系统配置模型
 type SystemConfig struct {
 	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
@@ -117,11 +221,165 @@ type SystemConfig struct {
 
 // SearchRequest 搜索请求
 type SearchRequest struct {
-	Q        string `form:"q" binding:"required" json:"q"`
-	Category string `form:"category" json:"category"`
-	Sort     string `form:"sort" json:"sort"`
-	Page     int    `form:"page" json:"page"`
-	PageSize int    `form:"pageSize" json:"pageSize"`
+	Q              string `form:"q" binding:"required" json:"q"`
+	Category       string `form:"category" json:"category"`   // 多个分类用逗号分隔
+	Source         string `form:"source" json:"source"`       // 多个来源用逗号分隔
+	Type           string `form:"type" json:"type"`           // 多个类型用逗号分隔
+	Tag            string `form:"tag" json:"tag"`             // 多个标签用逗号分隔
+	Year           string `form:"year" json:"year"`           // 上传年份，多个用逗号分隔
+	Facets         string `form:"facets" json:"facets"`       // 返回的分面，逗号分隔或all
+	Highlight      bool   `form:"highlight" json:"highlight"` // 返回标题和描述摘要的高亮片段
+	Sort           string `form:"sort" json:"sort"`
+	MinSize        string `form:"minSize" json:"minSize"` // 最小文件大小，如 700MB，不带单位时按字节
+	MaxSize        string `form:"maxSize" json:"maxSize"` // 最大文件大小，如 10GB
+	Page           int    `form:"page" json:"page"`
+	PageSize       int    `form:"pageSize" json:"pageSize"`
+	Cursor         string `form:"cursor" json:"cursor"`                 // 游标分页，第一页传空值
+	EstimateTotal  bool   `form:"estimateTotal" json:"estimateTotal"`   // 结果较多时估算总数
+	IncludeExpired bool   `form:"includeExpired" json:"includeExpired"` // 包含已过期的资源，仅管理员可用
+}
+
+// ResourceCreate 管理端创建资源
+type ResourceCreate struct {
+	Title       string     `json:"title" binding:"required,max=255"`
+	Description string     `json:"description"`
+	Size        string     `json:"size" binding:"required,max=20"`
+	Type        string     `json:"type" binding:"required,max=50"`
+	CategoryID  uint       `json:"categoryId" binding:"required"`
+	Source      string     `json:"source" binding:"max=100"` // 可识别的网盘链接会按链接自动填写
+	DownloadURL string     `json:"downloadUrl" binding:"required,max=500"`
+	ExtractCode string     `json:"extractCode" binding:"max=20"`
+	FileCount   uint       `json:"fileCount"`
+	Valid       *bool      `json:"valid"`
+	ExpireTime  *time.Time `json:"expireTime"`
+	UploadTime  *time.Time `json:"uploadTime"`
+	Tags        []string   `json:"tags"`
+}
+
+// ResourceUpdate 管理端更新资源，字段为空表示不修改
+type ResourceUpdate struct {
+	Title       *string    `json:"title" binding:"omitempty,min=1,max=255"`
+	Description *string    `json:"description"`
+	Size        *string    `json:"size" binding:"omitempty,min=1,max=20"`
+	Type        *string    `json:"type" binding:"omitempty,min=1,max=50"`
+	CategoryID  *uint      `json:"categoryId"`
+	Source      *string    `json:"source" binding:"omitempty,min=1,max=100"`
+	DownloadURL *string    `json:"downloadUrl" binding:"omitempty,min=1,max=500"`
+	ExtractCode *string    `json:"extractCode" binding:"omitempty,max=20"`
+	FileCount   *uint      `json:"fileCount"`
+	Valid       *bool      `json:"valid"`
+	ExpireTime  *time.Time `json:"expireTime"`
+	// 为true时清除过期时间，资源不再过期，不能与expireTime同时传入
+	ClearExpireTime bool       `json:"clearExpireTime" binding:"excluded_with=ExpireTime"`
+	UploadTime      *time.Time `json:"uploadTime"`
+	Tags            *[]string  `json:"tags"` // 传入时整体替换标签
+}
+
+// ResourceValidityUpdate 修改资源有效状态
+type ResourceValidityUpdate struct {
+	Valid *bool `json:"valid" binding:"required"`
+}
+
+// AdminResourceQuery 管理端资源查询
+type AdminResourceQuery struct {
+	Keyword       string `form:"keyword"`
+	CategoryID    uint   `form:"categoryId"`
+	Source        string `form:"source"`
+	Valid         *bool  `form:"valid"`
+	Deleted       bool   `form:"deleted"` // 为true时只查询已删除的资源
+	Page          int    `form:"page"`
+	PageSize      int    `form:"pageSize"`
+	Cursor        string `form:"cursor"`        // 游标分页，第一页传空值
+	EstimateTotal bool   `form:"estimateTotal"` // 结果较多时估算总数
+}
+
+// ReportCreate 用户举报资源
+type ReportCreate struct {
+	Reason      string `json:"reason" binding:"required"` // dead_link/wrong_code/wrong_category/illegal/other
+	Description string `json:"description" binding:"max=500"`
+}
+
+// ReportResponse 举报结果
+type ReportResponse struct {
+	ReportID string `json:"reportId"`
+	Status   string `json:"status"`
+}
+
+// AdminReportQuery 管理端举报队列查询
+type AdminReportQuery struct {
+	Status   string `form:"status"` // pending/resolved/dismissed，默认pending
+	Reason   string `form:"reason"`
+	Page     int    `form:"page"`
+	PageSize int    `form:"pageSize"`
+}
+
+// ReportedResource 举报队列中按资源汇总的一项
+type ReportedResource struct {
+	ResourceID    string         `json:"resourceId"`
+	Title         string         `json:"title"`
+	Source        string         `json:"source"`
+	Valid         bool           `json:"valid"`
+	LinkStatus    string         `json:"linkStatus"`
+	ReportCount   int64          `json:"reportCount"`
+	ReporterCount int64          `json:"reporterCount"`    // 不同用户或IP的数量
+	Reasons       map[string]int `gorm:"-" json:"reasons"` // 各原因的举报数
+	FirstReportAt time.Time      `json:"firstReportAt"`
+	LastReportAt  time.Time      `json:"lastReportAt"`
+}
+
+// ReportResolve 管理端处理资源的举报
+type ReportResolve struct {
+	Status     string `json:"status" binding:"required,oneof=resolved dismissed"`
+	AdminNotes string `json:"adminNotes" binding:"max=500"`
+	Valid      *bool  `json:"valid"` // 同时修改资源有效状态，不传时不修改
+}
+
+// ReportResolveResponse 处理举报的结果
+type ReportResolveResponse struct {
+	Resolved      int64 `json:"resolved"`      // 处理的举报数
+	RecheckQueued bool  `json:"recheckQueued"` // 是否已加入链接检测队列
+}
+
+// ExpiringResourceQuery 管理端即将过期资源查询
+type ExpiringResourceQuery struct {
+	Within   string `form:"within"` // 时间范围，如 24h、72h，默认使用配置的expiry.soonWindow
+	Page     int    `form:"page"`
+	PageSize int    `form:"pageSize"`
+}
+
+// CategoryCreate 管理端创建分类
+type CategoryCreate struct {
+	Value     string `json:"value" binding:"required,max=50"`
+	Label     string `json:"label" binding:"required,max=50"`
+	Icon      string `json:"icon" binding:"required,max=20"`
+	ParentID  *uint  `json:"parentId"`
+	SortOrder int    `json:"sortOrder"`
+	IsActive  *bool  `json:"isActive"`
+}
+
+// CategoryUpdate 管理端更新分类，parentId为0表示移动到顶级
+type CategoryUpdate struct {
+	Value     *string `json:"value" binding:"omitempty,min=1,max=50"`
+	Label     *string `json:"label" binding:"omitempty,min=1,max=50"`
+	Icon      *string `json:"icon" binding:"omitempty,min=1,max=20"`
+	ParentID  *uint   `json:"parentId"`
+	SortOrder *int    `json:"sortOrder"`
+}
+
+// CategorySortItem 分类排序项
+type CategorySortItem struct {
+	ID        uint `json:"id" binding:"required"`
+	SortOrder int  `json:"sortOrder"`
+}
+
+// CategorySortUpdate 批量调整分类排序
+type CategorySortUpdate struct {
+	Items []CategorySortItem `json:"items" binding:"required,dive"`
+}
+
+// CategoryStatusUpdate 启用或停用分类
+type CategoryStatusUpdate struct {
+	IsActive *bool `json:"isActive" binding:"required"`
 }
 
 // HelpRequestCreate 创建求助请求
@@ -133,6 +391,29 @@ type HelpRequestCreate struct {
 	ContactType  string `json:"contactType" binding:"required,oneof=email phone"`
 }
 
+// AdminHelpRequestQuery 管理端求助请求查询
+type AdminHelpRequestQuery struct {
+	Status       string `form:"status" binding:"omitempty,oneof=pending processing completed rejected"`
+	ResourceType string `form:"resourceType"`
+	Keyword      string `form:"keyword"`
+	StartDate    string `form:"startDate"` // 格式 2006-01-02
+	EndDate      string `form:"endDate"`   // 格式 2006-01-02，包含当天
+	Page         int    `form:"page"`
+	PageSize     int    `form:"pageSize"`
+}
+
+// HelpRequestUpdate 管理端更新求助请求
+type HelpRequestUpdate struct {
+	Status     string  `json:"status" binding:"omitempty,oneof=pending processing completed rejected"`
+	AdminNotes *string `json:"adminNotes"`
+}
+
+// HelpRequestStatusQuery 求助请求状态查询，token与contact二选一
+type HelpRequestStatusQuery struct {
+	Token   string `form:"token"`
+	Contact string `form:"contact"`
+}
+
 // DownloadRecordCreate 创建下载记录
 type DownloadRecordCreate struct {
 	UserID    string `json:"userId"`
@@ -140,6 +421,68 @@ type DownloadRecordCreate struct {
 	IP        string `json:"ip"`
 }
 
+// RegisterRequest 用户注册请求
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Email    string `json:"email" binding:"required,email,max=100"`
+	Phone    string `json:"phone"`
+	Password string `json:"password" binding:"required,min=6,max=64"`
+}
+
+// LoginRequest 用户登录请求
+type LoginRequest struct {
+	Account  string `json:"account" binding:"required"` // 用户名/邮箱/手机号
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshTokenRequest 刷新token请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// LogoutRequest 退出登录请求
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// UserProfileUpdate 更新用户信息
+type UserProfileUpdate struct {
+	Email       *string `json:"email" binding:"omitempty,email,max=100"`
+	Phone       *string `json:"phone"`
+	Avatar      *string `json:"avatar" binding:"omitempty,max=500"`
+	OldPassword string  `json:"oldPassword"`
+	NewPassword string  `json:"newPassword" binding:"omitempty,min=6,max=64"`
+}
+
+// AdminUserQuery 管理端用户查询
+type AdminUserQuery struct {
+	Keyword  string `form:"keyword"`
+	Role     string `form:"role"`
+	Status   string `form:"status"`
+	Page     int    `form:"page"`
+	PageSize int    `form:"pageSize"`
+}
+
+// UserRoleUpdate 修改用户角色
+type UserRoleUpdate struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// UserStatusUpdate 修改用户状态
+type UserStatusUpdate struct {
+	Status string `json:"status" binding:"required,oneof=active inactive banned"`
+}
+
+// RolePermissionsUpdate 修改角色权限
+type RolePermissionsUpdate struct {
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// SystemConfigUpdate 修改系统配置
+type SystemConfigUpdate struct {
+	Value string `json:"value" binding:"required"`
+}
+
 // SearchSuggestionRequest 搜索建议请求
 type SearchSuggestionRequest struct {
 	Q string `form:"q" binding:"required" json:"q"`
@@ -153,6 +496,7 @@ type ResourceResponse struct {
 	Title         string    `json:"title"`
 	Description   string    `json:"description"`
 	Size          string    `json:"size"`
+	SizeBytes     *int64    `json:"sizeBytes"`
 	Type          string    `json:"type"`
 	Category      string    `json:"category"`
 	Source        string    `json:"source"`
@@ -164,39 +508,116 @@ type ResourceResponse struct {
 	DownloadCount uint      `json:"downloadCount"`
 	Tags          []string  `json:"tags"`
 	Valid         bool      `json:"valid"`
-	ExpireTime    time.Time `json:"expireTime"`
+	ExpireTime    *time.Time `json:"expireTime"`
+	Highlight     *ResourceHighlight `json:"highlight,omitempty"` // 请求highlight=true时返回
+}
+
+// ResourceHighlight 搜索结果高亮片段，命中词已用标记包围，其余文本已做HTML转义
+type ResourceHighlight struct {
+	Title       string `json:"title"`
+	Description string `json:"description"` // 命中最密集处的摘要
+}
+
+// FacetValue 分面取值及命中数量
+type FacetValue struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int64  `json:"count"`
+}
+
+// SearchResponse 搜索响应，在分页响应的基础上附带分面统计
+type SearchResponse struct {
+	List       []ResourceResponse      `json:"list"`
+	Pagination interface{}             `json:"pagination"` // common.Pagination，游标分页时为common.CursorPagination
+	Facets     map[string][]FacetValue `json:"facets,omitempty"`
+	Truncated  bool                    `json:"truncated,omitempty"` // 关键词命中超过搜索引擎的候选数量上限，只返回了部分结果
 }
 
 // HotResourceResponse 热门资源响应
 type HotResourceResponse struct {
-	ID          string `json:"id"`
-	Rank        int    `json:"rank"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Size        string `json:"size"`
-	Type        string `json:"type"`
-	Category    string `json:"category"`
-	SearchCount uint   `json:"searchCount"`
-	Trend       string `json:"trend"`
-	TrendValue  int    `json:"trendValue"`
+	ID            string   `json:"id"`
+	Rank          int      `json:"rank"`
+	Title         string   `json:"title"`
+	Description   string   `json:"description"`
+	Size          string   `json:"size"`
+	Type          string   `json:"type"`
+	Category      string   `json:"category"`
+	SearchCount   int64    `json:"searchCount"`   // 时间窗口内命中该资源的搜索次数
+	DownloadCount int64    `json:"downloadCount"` // 时间窗口内的下载次数
+	Trend         string   `json:"trend"`         // up/down/stable/new
+	PrevRank      int      `json:"prevRank"`      // 上一窗口的排名，未上榜时为0
+	RankChange    int      `json:"rankChange"`    // 排名变化，上升为正
+	DeltaPercent  *float64 `json:"deltaPercent"`  // 热度相对上一窗口的变化百分比，上一窗口没有热度时为null
+}
+
+// HotResourcesResponse 热门推荐响应
+type HotResourcesResponse struct {
+	List      []HotResourceResponse `json:"list"`
+	Window    string                `json:"window"`
+	UpdatedAt *time.Time            `json:"updatedAt"` // 趋势的计算时间，没有趋势数据时按浏览量排序并返回null
 }
 
 // CategoryResponse 分类响应
 type CategoryResponse struct {
-	Value string `json:"value"`
-	Label string `json:"label"`
-	Count int    `json:"count"`
-	Icon  string `json:"icon"`
+	Value    string             `json:"value"`
+	Label    string             `json:"label"`
+	Count    int                `json:"count"` // 包含子分类的资源数量
+	Icon     string             `json:"icon"`
+	Children []CategoryResponse `json:"children,omitempty"`
 }
 
 // HelpRequestResponse 求助请求响应
 type HelpRequestResponse struct {
 	RequestID           string `json:"requestId"`
+	AccessToken         string `json:"accessToken"` // 查询处理状态的凭证，仅在提交时返回一次
 	Status              string `json:"status"`
 	SubmitTime          time.Time `json:"submitTime"`
 	EstimatedProcessTime string `json:"estimatedProcessTime"`
 }
 
+// HelpRequestStatusResponse 求助请求状态响应
+type HelpRequestStatusResponse struct {
+	RequestID     string     `json:"requestId"`
+	ResourceName  string     `json:"resourceName"`
+	ResourceType  string     `json:"resourceType"`
+	Status        string     `json:"status"`
+	AdminNotes    string     `json:"adminNotes"`
+	SubmitTime    time.Time  `json:"submitTime"`
+	UpdatedTime   time.Time  `json:"updatedTime"`
+	CompletedTime *time.Time `json:"completedTime"`
+}
+
+// UserProfileResponse 用户信息响应
+type UserProfileResponse struct {
+	ID          string    `json:"id"`
+	Username    string    `json:"username"`
+	Email       string    `json:"email"`
+	Phone       string    `json:"phone"`
+	Avatar      string    `json:"avatar"`
+	Role        string    `json:"role"`
+	Status      string    `json:"status"`
+	LastLoginAt time.Time `json:"lastLoginAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// RoleResponse 角色响应
+type RoleResponse struct {
+	Name        string   `json:"name"`
+	Label       string   `json:"label"`
+	Description string   `json:"description"`
+	IsSystem    bool     `json:"isSystem"`
+	Permissions []string `json:"permissions"`
+}
+
+// LoginResponse 登录响应
+type LoginResponse struct {
+	Token            string              `json:"token"`
+	ExpiresAt        time.Time           `json:"expiresAt"`
+	RefreshToken     string              `json:"refreshToken"`
+	RefreshExpiresAt time.Time           `json:"refreshExpiresAt"`
+	User             UserProfileResponse `json:"user"`
+}
+
 // DownloadResponse 下载响应
 type DownloadResponse struct {
 	DownloadID  string    `json:"downloadId"`
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"pan-search-api/config"
 	"pan-search-api/handlers"
 	"pan-search-api/middleware"
 	"time"
@@ -11,9 +12,14 @@ import (
 )
 
 // SetupRouter 设置路由
-func SetupRouter() *gin.Engine {
+func SetupRouter() (*gin.Engine, error) {
 	router := gin.Default()
 
+	// 只采信可信代理设置的X-Forwarded-For，否则客户端可以伪造IP绕过举报频率限制
+	if err := router.SetTrustedProxies(config.GlobalConfig.App.TrustedProxies); err != nil {
+		return nil, err
+	}
+
 	// 添加中间件
 	router.Use(middleware.CORSMiddleware())
 
@@ -32,9 +38,10 @@ func SetupRouter() *gin.Engine {
 		// 资源相关接口
 		resources := api.Group("/resources")
 		{
-			resources.GET("/search", handlers.SearchResources)
+			resources.GET("/search", middleware.OptionalAuthMiddleware(), handlers.SearchResources)
 			resources.GET("/hot", handlers.GetHotResources)
 			resources.POST("/:id/download", middleware.AuthMiddleware(), handlers.RecordDownload)
+			resources.POST("/:id/report", middleware.OptionalAuthMiddleware(), handlers.ReportResource)
 		}
 
 		// 分类相关接口（无需认证）
@@ -47,15 +54,102 @@ func SetupRouter() *gin.Engine {
 		requests := api.Group("/requests")
 		{
 			requests.POST("", handlers.SubmitHelpRequest)
+			requests.GET("/:id", handlers.GetHelpRequestStatus)
+		}
+
+		// 认证接口（无需认证）
+		auth := api.Group("/auth")
+		{
+			auth.POST("/register", handlers.Register)
+			auth.POST("/login", handlers.Login)
+			auth.POST("/refresh", handlers.RefreshToken)
+			auth.POST("/logout", middleware.OptionalAuthMiddleware(), handlers.Logout)
+			auth.POST("/logout-all", middleware.AuthMiddleware(), handlers.LogoutAll)
+		}
+
+		// 用户接口（需要认证）
+		user := api.Group("/user")
+		user.Use(middleware.AuthMiddleware())
+		{
+			user.GET("/profile", handlers.GetUserProfile)
+			user.PUT("/profile", handlers.UpdateUserProfile)
 		}
 
 		// 管理接口（需要管理员认证）
 		admin := api.Group("/admin")
 		admin.Use(middleware.AdminAuthMiddleware())
 		{
-			// 这里可以添加管理相关的接口
-			// admin.GET("/help-requests", handlers.AdminGetHelpRequests)
-			// admin.PUT("/help-requests/:id", handlers.AdminUpdateHelpRequest)
+			// 求助请求管理
+			adminRequests := admin.Group("/help-requests")
+			{
+				adminRequests.GET("", middleware.RequirePermission(middleware.PermHelpRequestRead), handlers.AdminListHelpRequests)
+				adminRequests.GET("/:id", middleware.RequirePermission(middleware.PermHelpRequestRead), handlers.AdminGetHelpRequest)
+				adminRequests.PUT("/:id", middleware.RequirePermission(middleware.PermHelpRequestProcess), handlers.AdminUpdateHelpRequest)
+			}
+
+			// 资源管理
+			adminResources := admin.Group("/resources", middleware.RequirePermission(middleware.PermResourceWrite))
+			{
+				adminResources.GET("", handlers.AdminListResources)
+				adminResources.POST("", handlers.AdminCreateResource)
+				adminResources.POST("/import", handlers.AdminImportResources)
+				adminResources.GET("/expiring", handlers.AdminListExpiringResources)
+				adminResources.GET("/:id", handlers.AdminGetResource)
+				adminResources.PUT("/:id", handlers.AdminUpdateResource)
+				adminResources.DELETE("/:id", handlers.AdminDeleteResource)
+				adminResources.PUT("/:id/validity", handlers.AdminUpdateResourceValidity)
+				adminResources.POST("/:id/restore", handlers.AdminRestoreResource)
+				adminResources.GET("/:id/checks", handlers.AdminListLinkChecks)
+				adminResources.POST("/:id/check", handlers.AdminCheckResource)
+				adminResources.GET("/:id/reports", handlers.AdminListResourceReports)
+				adminResources.POST("/:id/reports/resolve", handlers.AdminResolveReports)
+			}
+
+			// 举报队列
+			admin.GET("/reports", middleware.RequirePermission(middleware.PermResourceWrite), handlers.AdminListReports)
+
+			// 资源采集
+			adminConnectors := admin.Group("/connectors", middleware.RequirePermission(middleware.PermResourceWrite))
+			{
+				adminConnectors.GET("", handlers.AdminListConnectors)
+				adminConnectors.POST("/:name/run", handlers.AdminRunConnector)
+			}
+
+			// 分类管理
+			adminCategories := admin.Group("/categories", middleware.RequirePermission(middleware.PermCategoryWrite))
+			{
+				adminCategories.GET("", handlers.AdminListCategories)
+				adminCategories.POST("", handlers.AdminCreateCategory)
+				adminCategories.PUT("/sort", handlers.AdminSortCategories)
+				adminCategories.PUT("/:id", handlers.AdminUpdateCategory)
+				adminCategories.PUT("/:id/status", handlers.AdminUpdateCategoryStatus)
+				adminCategories.DELETE("/:id", handlers.AdminDeleteCategory)
+			}
+
+			// 数据导出
+			admin.GET("/export/:dataset", middleware.RequirePermission(middleware.PermDataExport), handlers.AdminExportData)
+
+			// 用户管理
+			adminUsers := admin.Group("/users", middleware.RequirePermission(middleware.PermUserManage))
+			{
+				adminUsers.GET("", handlers.AdminListUsers)
+				adminUsers.PUT("/:id/role", middleware.RequirePermission(middleware.PermRoleManage), handlers.AdminUpdateUserRole)
+				adminUsers.PUT("/:id/status", handlers.AdminUpdateUserStatus)
+			}
+
+			// 角色权限管理
+			adminRoles := admin.Group("/roles", middleware.RequirePermission(middleware.PermRoleManage))
+			{
+				adminRoles.GET("", handlers.AdminListRoles)
+				adminRoles.PUT("/:name/permissions", handlers.AdminUpdateRolePermissions)
+			}
+
+			// 系统配置
+			adminConfigs := admin.Group("/configs")
+			{
+				adminConfigs.GET("", middleware.RequirePermission(middleware.PermConfigRead), handlers.AdminListConfigs)
+				adminConfigs.PUT("/:key", middleware.RequirePermission(middleware.PermConfigWrite), handlers.AdminUpdateConfig)
+			}
 		}
 	}
 
@@ -67,5 +161,5 @@ func SetupRouter() *gin.Engine {
 		})
 	})
 
-	return router
+	return router, nil
 }
\ No newline at end of file
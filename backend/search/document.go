@@ -0,0 +1,29 @@
+package search
+
+import (
+	"pan-search-api/models"
+)
+
+// DocumentFromResource 将资源转换为索引文档，需要预加载标签
+func DocumentFromResource(resource *models.Resource) Document {
+	tags := make([]string, len(resource.Tags))
+	for i, tag := range resource.Tags {
+		tags[i] = tag.TagName
+	}
+
+	return Document{
+		ID:          resource.ID,
+		Title:       resource.Title,
+		Description: resource.Description,
+		Tags:        tags,
+	}
+}
+
+// SyncResource 同步资源到默认搜索引擎，有效且未删除的资源写入索引，否则从索引移除
+func SyncResource(resource *models.Resource) error {
+	engine := Default()
+	if !resource.Valid || resource.DeletedAt.Valid {
+		return engine.Remove(resource.ID)
+	}
+	return engine.Index(DocumentFromResource(resource))
+}
@@ -0,0 +1,458 @@
+package search
+
+import (
+	"encoding/gob"
+	"errors"
+	"log"
+	"math"
+	"os"
+	"pan-search-api/config"
+	"pan-search-api/models"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BM25 参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// 字段权重，标题命中比描述更重要
+const (
+	titleWeight       = 3.0
+	tagWeight         = 2.0
+	descriptionWeight = 1.0
+)
+
+// 索引文件格式版本，格式变化时旧文件会被丢弃并重建
+const indexVersion = 2
+
+// 重建索引时每批读取的资源数
+const rebuildBatchSize = 500
+
+// indexedDoc 文档在索引中的信息
+type indexedDoc struct {
+	Length float64            // 加权后的词数
+	Terms  map[string]float64 // 词 -> 加权词频
+}
+
+// indexSnapshot 索引文件内容，倒排表在加载时由文档重新生成
+type indexSnapshot struct {
+	Version   int
+	Tokenizer string // 分词规则指纹，词典变化后需要重建
+	Docs      map[string]*indexedDoc
+}
+
+// EmbeddedEngine 内嵌倒排索引，使用BM25计算相关度，定期持久化到磁盘
+type EmbeddedEngine struct {
+	mu        sync.RWMutex
+	path      string
+	tokenizer Tokenizer
+	docs      map[string]*indexedDoc
+	postings  map[string]map[string]float64 // 词 -> 文档ID -> 加权词频
+	totalLen  float64
+	version   uint64 // 每次修改加1
+	saved     uint64 // 已写入磁盘的版本
+
+	saveMu sync.Mutex // 同一时间只有一次落盘
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// OpenEmbeddedEngine 加载索引文件，文件不存在、损坏或配置要求时从数据库重建
+func OpenEmbeddedEngine(cfg config.SearchConfig, db *gorm.DB) (*EmbeddedEngine, error) {
+	e := newEmbeddedEngine(cfg.IndexPath)
+
+	rebuild := cfg.RebuildOnStart
+	if isIndexStale(e.path) {
+		log.Printf("Search index %s was marked stale by a command line write, rebuilding", e.path)
+		rebuild = true
+	}
+	if !rebuild {
+		if err := e.load(); err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				log.Printf("Failed to load search index %s, rebuilding: %v", e.path, err)
+			}
+			rebuild = true
+		}
+	}
+	if rebuild {
+		if err := e.Rebuild(db); err != nil {
+			return nil, err
+		}
+		if err := e.Save(); err != nil {
+			return nil, err
+		}
+		if err := os.Remove(staleMarker(e.path)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	interval := cfg.SaveInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	go e.saveLoop(interval)
+
+	return e, nil
+}
+
+// MarkIndexStale 标记embedded索引需要重建。命令行直接写入数据库时无法同步运行中服务的索引，
+// 标记后下次打开索引（服务启动或reindex）时从数据库重建
+func MarkIndexStale(cfg config.SearchConfig) error {
+	if cfg.Engine != EngineEmbedded {
+		return nil
+	}
+	path := staleMarker(cfg.IndexPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(time.Now().Format(time.RFC3339)+"\n"), 0644)
+}
+
+// staleMarker 索引需要重建的标记文件
+func staleMarker(indexPath string) string {
+	return indexPath + ".stale"
+}
+
+// isIndexStale 索引是否被标记为需要重建
+func isIndexStale(indexPath string) bool {
+	_, err := os.Stat(staleMarker(indexPath))
+	return err == nil
+}
+
+// newEmbeddedEngine 创建空索引
+func newEmbeddedEngine(path string) *EmbeddedEngine {
+	return &EmbeddedEngine{
+		path:      path,
+		tokenizer: DefaultTokenizer(),
+		docs:      make(map[string]*indexedDoc),
+		postings:  make(map[string]map[string]float64),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Name 引擎名称
+func (e *EmbeddedEngine) Name() string {
+	return EngineEmbedded
+}
+
+// Search 优先返回包含全部查询词的文档，没有时退化为包含任一查询词
+func (e *EmbeddedEngine) Search(query string, limit int) ([]Hit, error) {
+	return e.search(query, limit, true)
+}
+
+// SearchAll 只返回包含全部查询词的文档，用于筛选结果
+func (e *EmbeddedEngine) SearchAll(query string, limit int) ([]Hit, error) {
+	return e.search(query, limit, false)
+}
+
+// search 按BM25计算相关度，fallback为true且没有文档包含全部查询词时返回包含任一查询词的文档
+func (e *EmbeddedEngine) search(query string, limit int, fallback bool) ([]Hit, error) {
+	terms := uniqueTerms(e.tokenizer.Tokenize(query))
+	if len(terms) == 0 || limit <= 0 {
+		return []Hit{}, nil
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	n := float64(len(e.docs))
+	if n == 0 {
+		return []Hit{}, nil
+	}
+	avgLen := e.totalLen / n
+
+	scores := make(map[string]float64)
+	matched := make(map[string]int)
+	for _, term := range terms {
+		posting := e.postings[term]
+		if len(posting) == 0 {
+			continue
+		}
+		df := float64(len(posting))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range posting {
+			norm := bm25K1 * (1 - bm25B + bm25B*e.docs[id].Length/avgLen)
+			scores[id] += idf * tf * (bm25K1 + 1) / (tf + norm)
+			matched[id]++
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		if matched[id] == len(terms) {
+			hits = append(hits, Hit{ID: id, Score: score})
+		}
+	}
+	if len(hits) == 0 && fallback {
+		for id, score := range scores {
+			hits = append(hits, Hit{ID: id, Score: score})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// Index 新增或更新文档
+func (e *EmbeddedEngine) Index(doc Document) error {
+	indexed := e.analyze(doc)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.remove(doc.ID)
+	e.add(doc.ID, indexed)
+	e.version++
+	return nil
+}
+
+// Remove 删除文档
+func (e *EmbeddedEngine) Remove(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.remove(id) {
+		e.version++
+	}
+	return nil
+}
+
+// Close 停止定期落盘并保存索引
+func (e *EmbeddedEngine) Close() error {
+	var err error
+	e.closeOnce.Do(func() {
+		close(e.stop)
+		<-e.done
+		err = e.Save()
+	})
+	return err
+}
+
+// Rebuild 从数据库重建全部索引，重建完成后整体替换
+func (e *EmbeddedEngine) Rebuild(db *gorm.DB) error {
+	fresh := newEmbeddedEngine(e.path)
+
+	var lastID string
+	for {
+		var resources []models.Resource
+		if err := db.Preload("Tags").
+			Where("valid = ? AND id > ?", true, lastID).
+			Order("id").
+			Limit(rebuildBatchSize).
+			Find(&resources).Error; err != nil {
+			return err
+		}
+
+		for i := range resources {
+			doc := DocumentFromResource(&resources[i])
+			fresh.add(doc.ID, e.analyze(doc))
+		}
+		if len(resources) < rebuildBatchSize {
+			break
+		}
+		lastID = resources[len(resources)-1].ID
+	}
+
+	e.mu.Lock()
+	e.docs = fresh.docs
+	e.postings = fresh.postings
+	e.totalLen = fresh.totalLen
+	e.version++
+	e.mu.Unlock()
+
+	log.Printf("Search index rebuilt: %d documents", len(fresh.docs))
+	return nil
+}
+
+// Save 将索引写入磁盘，先写临时文件再重命名，避免写入中断导致文件损坏。
+// 只在复制文档表时持有读锁，编码和写文件期间不阻塞搜索和索引更新
+func (e *EmbeddedEngine) Save() error {
+	e.saveMu.Lock()
+	defer e.saveMu.Unlock()
+
+	// 文档写入后不再修改，更新时整体替换，复制文档表即可得到一致的快照
+	e.mu.RLock()
+	version := e.version
+	if version == e.saved {
+		e.mu.RUnlock()
+		return nil
+	}
+	docs := make(map[string]*indexedDoc, len(e.docs))
+	for id, doc := range e.docs {
+		docs[id] = doc
+	}
+	e.mu.RUnlock()
+
+	if err := os.MkdirAll(filepath.Dir(e.path), 0755); err != nil {
+		return err
+	}
+
+	tmp := e.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	snapshot := indexSnapshot{
+		Version:   indexVersion,
+		Tokenizer: tokenizerFingerprint(e.tokenizer),
+		Docs:      docs,
+	}
+	if err := gob.NewEncoder(file).Encode(&snapshot); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, e.path); err != nil {
+		return err
+	}
+
+	// 写入期间的修改留到下次落盘
+	e.mu.Lock()
+	e.saved = version
+	e.mu.Unlock()
+	return nil
+}
+
+// load 从磁盘加载索引
+func (e *EmbeddedEngine) load() error {
+	file, err := os.Open(e.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var snapshot indexSnapshot
+	if err := gob.NewDecoder(file).Decode(&snapshot); err != nil {
+		return err
+	}
+	if snapshot.Version != indexVersion {
+		return errors.New("index version mismatch")
+	}
+	if snapshot.Tokenizer != tokenizerFingerprint(e.tokenizer) {
+		return errors.New("dictionary changed")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for id, doc := range snapshot.Docs {
+		e.add(id, doc)
+	}
+	return nil
+}
+
+// saveLoop 定期将有变更的索引落盘
+func (e *EmbeddedEngine) saveLoop(interval time.Duration) {
+	defer close(e.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := e.Save(); err != nil {
+				log.Printf("Failed to save search index: %v", err)
+			}
+		case <-e.stop:
+			return
+		}
+	}
+}
+
+// analyze 对文档各字段分词并按字段权重累加词频
+func (e *EmbeddedEngine) analyze(doc Document) *indexedDoc {
+	indexed := &indexedDoc{Terms: make(map[string]float64)}
+	addField := func(text string, weight float64) {
+		for _, term := range e.tokenizer.Tokenize(text) {
+			indexed.Terms[term] += weight
+			indexed.Length += weight
+		}
+	}
+
+	addField(doc.Title, titleWeight)
+	addField(doc.Description, descriptionWeight)
+	for _, tag := range doc.Tags {
+		addField(tag, tagWeight)
+	}
+	return indexed
+}
+
+// add 将文档加入倒排表，调用方需持有写锁
+func (e *EmbeddedEngine) add(id string, doc *indexedDoc) {
+	if len(doc.Terms) == 0 {
+		return
+	}
+
+	e.docs[id] = doc
+	e.totalLen += doc.Length
+	for term, tf := range doc.Terms {
+		posting := e.postings[term]
+		if posting == nil {
+			posting = make(map[string]float64)
+			e.postings[term] = posting
+		}
+		posting[id] = tf
+	}
+}
+
+// remove 将文档从倒排表移除，调用方需持有写锁
+func (e *EmbeddedEngine) remove(id string) bool {
+	doc, ok := e.docs[id]
+	if !ok {
+		return false
+	}
+
+	for term := range doc.Terms {
+		posting := e.postings[term]
+		delete(posting, id)
+		if len(posting) == 0 {
+			delete(e.postings, term)
+		}
+	}
+	e.totalLen -= doc.Length
+	delete(e.docs, id)
+	return true
+}
+
+// tokenizerFingerprint 分词器指纹，分词器不支持时返回空字符串
+func tokenizerFingerprint(tokenizer Tokenizer) string {
+	if f, ok := tokenizer.(fingerprinter); ok {
+		return f.Fingerprint()
+	}
+	return ""
+}
+
+// uniqueTerms 去除重复的词，保持原有顺序
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]struct{}, len(terms))
+	result := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if _, ok := seen[term]; ok {
+			continue
+		}
+		seen[term] = struct{}{}
+		result = append(result, term)
+	}
+	return result
+}
@@ -0,0 +1,226 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testEngine 使用测试词典的空索引，索引文件在临时目录中
+func testEngine(t *testing.T, docs ...Document) *EmbeddedEngine {
+	t.Helper()
+	e := newEmbeddedEngine(filepath.Join(t.TempDir(), "search.idx"))
+	e.tokenizer = NewSegmenter(testDictionary(t))
+	for _, doc := range docs {
+		if err := e.Index(doc); err != nil {
+			t.Fatalf("Index(%s) error = %v", doc.ID, err)
+		}
+	}
+	return e
+}
+
+func hitIDs(hits []Hit) []string {
+	ids := []string{}
+	for _, hit := range hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids
+}
+
+func TestEmbeddedAnalyze(t *testing.T) {
+	e := testEngine(t)
+	doc := e.analyze(Document{
+		ID:          "a",
+		Title:       "流浪地球 高清",
+		Description: "蓝光原盘",
+		Tags:        []string{"科幻", "高清"},
+	})
+
+	// 标题权重3，标签2，描述1；长词同时计入其中的词典词语
+	want := map[string]float64{
+		"流浪地球": 3, "流浪": 3, "地球": 3,
+		"高清": 5,
+		"蓝光": 1, "原盘": 1,
+		"科": 2, "幻": 2,
+	}
+	if !reflect.DeepEqual(doc.Terms, want) {
+		t.Errorf("analyze().Terms = %v, want %v", doc.Terms, want)
+	}
+	if doc.Length != 20 {
+		t.Errorf("analyze().Length = %v, want 20", doc.Length)
+	}
+}
+
+func TestEmbeddedSearch(t *testing.T) {
+	e := testEngine(t,
+		Document{ID: "title", Title: "流浪地球", Description: "电影"},
+		Document{ID: "desc", Title: "电影", Description: "流浪地球"},
+		Document{ID: "tag", Title: "电影", Tags: []string{"流浪地球"}},
+		Document{ID: "other", Title: "权力的游戏"},
+	)
+
+	tests := []struct {
+		name    string
+		query   string
+		want    []string
+		wantAll []string
+	}{
+		// 标题命中排在标签和描述之前
+		{"field weights", "流浪地球", []string{"title", "tag", "desc"}, []string{"title", "tag", "desc"}},
+		{"sub word", "地球", []string{"title", "tag", "desc"}, []string{"title", "tag", "desc"}},
+		// 没有文档包含全部查询词时Search退化为包含任一查询词，SearchAll不退化
+		{"fallback to any term", "流浪地球 游戏", []string{"other", "title", "tag", "desc"}, []string{}},
+		{"partial terms", "高清 地球", []string{"title", "tag", "desc"}, []string{}},
+		{"no match", "蓝光", []string{}, []string{}},
+		{"empty query", "", []string{}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := e.Search(tt.query, 10)
+			if err != nil {
+				t.Fatalf("Search(%q) error = %v", tt.query, err)
+			}
+			if got := hitIDs(hits); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			all, err := e.SearchAll(tt.query, 10)
+			if err != nil {
+				t.Fatalf("SearchAll(%q) error = %v", tt.query, err)
+			}
+			if got := hitIDs(all); !reflect.DeepEqual(got, tt.wantAll) {
+				t.Errorf("SearchAll(%q) = %v, want %v", tt.query, got, tt.wantAll)
+			}
+		})
+	}
+
+	hits, _ := e.Search("流浪地球", 2)
+	if got := hitIDs(hits); !reflect.DeepEqual(got, []string{"title", "tag"}) {
+		t.Errorf("Search(limit 2) = %v, want [title tag]", got)
+	}
+}
+
+func TestEmbeddedIndexUpdateRemove(t *testing.T) {
+	e := testEngine(t, Document{ID: "a", Title: "流浪地球"}, Document{ID: "b", Title: "权力的游戏"})
+
+	// 更新后旧标题的词不再命中
+	if err := e.Index(Document{ID: "a", Title: "高清蓝光"}); err != nil {
+		t.Fatalf("Index() error = %v", err)
+	}
+	steps := []struct {
+		query string
+		want  []string
+	}{
+		{"流浪地球", []string{}},
+		{"蓝光", []string{"a"}},
+		{"游戏", []string{"b"}},
+	}
+	for _, step := range steps {
+		hits, _ := e.Search(step.query, 10)
+		if got := hitIDs(hits); !reflect.DeepEqual(got, step.want) {
+			t.Errorf("Search(%q) after update = %v, want %v", step.query, got, step.want)
+		}
+	}
+
+	for _, id := range []string{"a", "b", "missing"} {
+		if err := e.Remove(id); err != nil {
+			t.Fatalf("Remove(%s) error = %v", id, err)
+		}
+	}
+	if len(e.docs) != 0 || len(e.postings) != 0 || e.totalLen != 0 {
+		t.Errorf("after Remove: %d docs, %d postings, totalLen %v, want empty", len(e.docs), len(e.postings), e.totalLen)
+	}
+	if hits, _ := e.Search("蓝光", 10); len(hits) != 0 {
+		t.Errorf("Search() after Remove = %v, want empty", hitIDs(hits))
+	}
+}
+
+func TestMatchTruncated(t *testing.T) {
+	e := testEngine(t,
+		Document{ID: "a", Title: "流浪地球"},
+		Document{ID: "b", Title: "流浪地球 高清"},
+		Document{ID: "c", Title: "流浪地球 蓝光"},
+	)
+	old := Default()
+	SetDefault(e)
+	t.Cleanup(func() { SetDefault(old) })
+
+	tests := []struct {
+		name          string
+		query         string
+		limit         int
+		wantHits      int
+		wantTruncated bool
+	}{
+		{"over limit", "流浪地球", 2, 2, true},
+		{"at limit", "流浪地球", 3, 3, false},
+		// 按候选ID筛选时只取包含全部查询词的文档
+		{"strict", "流浪地球 高清", 1, 1, false},
+		{"no match", "游戏", 10, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := Match(nil, tt.query, tt.limit)
+			if err != nil {
+				t.Fatalf("Match(%q) error = %v", tt.query, err)
+			}
+			ids := filter.Vars[0].([]string)
+			if len(filter.Hits) != tt.wantHits || len(ids) != tt.wantHits || filter.Truncated != tt.wantTruncated {
+				t.Errorf("Match(%q, %d) = %d hits, truncated %v, want %d, %v",
+					tt.query, tt.limit, len(filter.Hits), filter.Truncated, tt.wantHits, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestEmbeddedSaveLoad(t *testing.T) {
+	e := testEngine(t,
+		Document{ID: "a", Title: "流浪地球", Tags: []string{"高清"}},
+		Document{ID: "b", Title: "权力的游戏", Description: "蓝光原盘"},
+	)
+	if err := e.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(e.path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left after Save: %v", err)
+	}
+
+	loaded := newEmbeddedEngine(e.path)
+	loaded.tokenizer = e.tokenizer
+	if err := loaded.load(); err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+	if !reflect.DeepEqual(loaded.docs, e.docs) || loaded.totalLen != e.totalLen {
+		t.Errorf("loaded index differs from saved index")
+	}
+	for _, query := range []string{"地球", "蓝光", "高清"} {
+		want, _ := e.Search(query, 10)
+		got, _ := loaded.Search(query, 10)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Search(%q) after load = %v, want %v", query, got, want)
+		}
+	}
+
+	// 没有修改时不重写文件
+	info, _ := os.Stat(e.path)
+	if err := os.Chtimes(e.path, info.ModTime().Add(-time.Second), info.ModTime().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.Stat(e.path)
+	if err := e.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if after, _ := os.Stat(e.path); !after.ModTime().Equal(before.ModTime()) {
+		t.Errorf("Save() without changes rewrote the index file")
+	}
+
+	// 词典变化后拒绝加载旧索引
+	other := newEmbeddedEngine(e.path)
+	other.tokenizer = NewSegmenter(NewDictionary())
+	if err := other.load(); err == nil {
+		t.Errorf("load() with a different dictionary error = nil, want error")
+	}
+}
@@ -0,0 +1,180 @@
+package search
+
+import (
+	"fmt"
+	"log"
+	"pan-search-api/config"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// 引擎名称
+const (
+	EngineLike     = "like"
+	EngineMySQL    = "mysql"
+	EngineEmbedded = "embedded"
+)
+
+// 默认最多返回的候选结果数
+const defaultMaxCandidates = 1000
+
+// Hit 命中结果
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// Document 待索引的资源文档
+type Document struct {
+	ID          string
+	Title       string
+	Description string
+	Tags        []string
+}
+
+// SearchEngine 搜索引擎接口，只检索有效且未删除的资源
+type SearchEngine interface {
+	// Name 引擎名称
+	Name() string
+	// Search 返回按相关度降序排列的命中结果，最多limit条
+	Search(query string, limit int) ([]Hit, error)
+	// Index 新增或更新文档
+	Index(doc Document) error
+	// Remove 删除文档
+	Remove(id string) error
+	// Close 释放资源，持久化索引
+	Close() error
+}
+
+// Matcher 可以直接生成SQL筛选条件的搜索引擎，结果数量不受候选数量限制
+type Matcher interface {
+	// Condition 返回与Search命中范围一致的SQL条件，列名带resources表名
+	Condition(query string) (string, []interface{})
+}
+
+// StrictSearcher 在没有文档包含全部关键词时会退化匹配的搜索引擎，SearchAll只返回包含全部关键词的文档
+type StrictSearcher interface {
+	SearchAll(query string, limit int) ([]Hit, error)
+}
+
+// Filter 关键词在搜索结果中的筛选条件
+type Filter struct {
+	SQL       string
+	Vars      []interface{}
+	Hits      []Hit // 按相关度降序排列的候选，只用于排序，最多limit条
+	Truncated bool  // 按候选ID筛选且命中超过limit条，结果不完整
+}
+
+var (
+	mu            sync.RWMutex
+	defaultEngine SearchEngine = &LikeEngine{}
+)
+
+// Init 按配置初始化默认搜索引擎
+func Init(db *gorm.DB) error {
+	cfg := config.GlobalConfig.Search
+
+	if cfg.UserDict != "" {
+		if err := LoadUserDictionary(cfg.UserDict); err != nil {
+			return err
+		}
+	}
+	log.Printf("Search dictionary loaded: %d words", BuiltinDictionary().Size())
+
+	engine, err := NewEngine(cfg, db)
+	if err != nil {
+		return err
+	}
+
+	SetDefault(engine)
+	log.Printf("Search engine initialized: %s", engine.Name())
+	return nil
+}
+
+// NewEngine 根据配置创建搜索引擎
+func NewEngine(cfg config.SearchConfig, db *gorm.DB) (SearchEngine, error) {
+	switch cfg.Engine {
+	case "", EngineLike:
+		return &LikeEngine{DB: db}, nil
+	case EngineMySQL:
+		return &MySQLEngine{DB: db}, nil
+	case EngineEmbedded:
+		return OpenEmbeddedEngine(cfg, db)
+	default:
+		return nil, fmt.Errorf("unknown search engine %q", cfg.Engine)
+	}
+}
+
+// Default 获取默认搜索引擎
+func Default() SearchEngine {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultEngine
+}
+
+// SetDefault 设置默认搜索引擎
+func SetDefault(engine SearchEngine) {
+	mu.Lock()
+	defaultEngine = engine
+	mu.Unlock()
+}
+
+// Close 关闭默认搜索引擎
+func Close() error {
+	return Default().Close()
+}
+
+// MaxCandidates 单次搜索最多返回的候选结果数
+func MaxCandidates() int {
+	if config.GlobalConfig != nil && config.GlobalConfig.Search.MaxCandidates > 0 {
+		return config.GlobalConfig.Search.MaxCandidates
+	}
+	return defaultMaxCandidates
+}
+
+// Match 生成关键词的筛选条件。引擎实现Matcher时直接在SQL中匹配，候选只用于相关度排序；
+// 否则按候选ID筛选，最多limit条。查询为拼音时同样匹配标题和标签的拼音
+func Match(db *gorm.DB, query string, limit int) (*Filter, error) {
+	engine := Default()
+	if matcher, ok := engine.(Matcher); ok {
+		hits, err := Search(db, query, limit)
+		if err != nil {
+			return nil, err
+		}
+		sql, vars := matcher.Condition(query)
+		if normalized, ok := PinyinQuery(query); ok {
+			like := "%" + normalized + "%"
+			sql = "(" + sql + " OR " + pinyinCondition + ")"
+			vars = append(vars, like, like, like, like)
+		}
+		return &Filter{SQL: sql, Vars: vars, Hits: hits}, nil
+	}
+
+	// 多取一条判断是否超过上限
+	var hits []Hit
+	var err error
+	if strict, ok := engine.(StrictSearcher); ok {
+		hits, err = strict.SearchAll(query, limit+1)
+	} else {
+		hits, err = engine.Search(query, limit+1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if hits, err = appendPinyinHits(db, query, hits, limit+1); err != nil {
+		return nil, err
+	}
+
+	filter := &Filter{SQL: "resources.id IN ?", Hits: hits}
+	if len(hits) > limit {
+		filter.Hits = hits[:limit]
+		filter.Truncated = true
+	}
+	ids := make([]string, len(filter.Hits))
+	for i, hit := range filter.Hits {
+		ids[i] = hit.ID
+	}
+	filter.Vars = []interface{}{ids}
+	return filter, nil
+}
@@ -0,0 +1,253 @@
+package search
+
+import (
+	"html"
+	"pan-search-api/config"
+	"sort"
+	"strings"
+)
+
+// 高亮默认配置
+const (
+	defaultPreTag        = "<em>"
+	defaultPostTag       = "</em>"
+	defaultSnippetLength = 120
+)
+
+// 摘要被截断时使用的省略号
+const snippetEllipsis = "…"
+
+// HighlightOptions 高亮选项
+type HighlightOptions struct {
+	PreTag        string // 命中词前的标记
+	PostTag       string // 命中词后的标记
+	SnippetLength int    // 摘要最大字符数，不含标记和省略号
+	NoEscape      bool   // 为true时不对文本做HTML转义，标记不是HTML时使用
+}
+
+// DefaultHighlightOptions 返回配置文件中的高亮选项
+func DefaultHighlightOptions() HighlightOptions {
+	if config.GlobalConfig == nil {
+		return HighlightOptions{}
+	}
+	cfg := config.GlobalConfig.Search.Highlight
+	return HighlightOptions{
+		PreTag:        cfg.PreTag,
+		PostTag:       cfg.PostTag,
+		SnippetLength: cfg.SnippetLength,
+		NoEscape:      cfg.NoEscape,
+	}
+}
+
+// Highlighter 按查询词标记文本中的命中位置
+type Highlighter struct {
+	opts  HighlightOptions
+	terms [][]rune // 小写的查询词，按长度降序，优先匹配长词
+}
+
+// matchRange 命中区间，按字符下标，左闭右开
+type matchRange struct {
+	start, end int
+}
+
+// NewHighlighter 使用与索引相同的分词规则切分查询，查询词包括整词和其中的词典词语，
+// 例如"流浪地球2高清"会高亮"流浪地球2"，也会高亮只出现"地球"的片段
+func NewHighlighter(query string, opts HighlightOptions) *Highlighter {
+	if opts.PreTag == "" && opts.PostTag == "" {
+		opts.PreTag, opts.PostTag = defaultPreTag, defaultPostTag
+	}
+	if opts.SnippetLength <= 0 {
+		opts.SnippetLength = defaultSnippetLength
+	}
+
+	seen := make(map[string]struct{})
+	var terms [][]rune
+	for _, term := range DefaultTokenizer().Tokenize(query) {
+		if _, ok := seen[term]; ok {
+			continue
+		}
+		seen[term] = struct{}{}
+		terms = append(terms, []rune(term))
+	}
+	sort.SliceStable(terms, func(i, j int) bool {
+		return len(terms[i]) > len(terms[j])
+	})
+
+	// 有多字词时不再单独高亮单字，避免"的"、"之"等散落在全文各处
+	if len(terms) > 0 && len(terms[0]) > 1 {
+		for i, term := range terms {
+			if len(term) == 1 {
+				terms = terms[:i]
+				break
+			}
+		}
+	}
+
+	return &Highlighter{opts: opts, terms: terms}
+}
+
+// Highlight 标记全文中的命中词，用于标题
+func (h *Highlighter) Highlight(text string) string {
+	runes := []rune(text)
+	return h.render(runes, h.matches(runes), 0, len(runes))
+}
+
+// Snippet 截取命中最密集的片段并标记命中词，用于描述；没有命中时返回开头的片段
+func (h *Highlighter) Snippet(text string) string {
+	runes := []rune(text)
+	matches := h.matches(runes)
+
+	start, end := h.snippetWindow(len(runes), matches)
+	snippet := h.render(runes, matches, start, end)
+	if start > 0 {
+		snippet = snippetEllipsis + snippet
+	}
+	if end < len(runes) {
+		snippet += snippetEllipsis
+	}
+	return snippet
+}
+
+// matches 查找全部命中区间，已被长词覆盖的位置不再匹配短词
+func (h *Highlighter) matches(text []rune) []matchRange {
+	if len(h.terms) == 0 || len(text) == 0 {
+		return nil
+	}
+
+	lower := []rune(strings.ToLower(string(text)))
+	if len(lower) != len(text) {
+		// 个别字符转小写后长度变化时无法对应下标，放弃高亮
+		return nil
+	}
+
+	covered := make([]bool, len(lower))
+	var ranges []matchRange
+	for _, term := range h.terms {
+		for i := 0; i+len(term) <= len(lower); i++ {
+			if covered[i] || !hasPrefixRunes(lower[i:], term) {
+				continue
+			}
+			end := i + len(term)
+			if anyCovered(covered[i:end]) {
+				continue
+			}
+			for j := i; j < end; j++ {
+				covered[j] = true
+			}
+			ranges = append(ranges, matchRange{i, end})
+			i = end - 1
+		}
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].start < ranges[j].start
+	})
+	return mergeRanges(ranges)
+}
+
+// snippetWindow 选择包含命中字符最多的窗口，并让命中部分尽量居中
+func (h *Highlighter) snippetWindow(length int, matches []matchRange) (int, int) {
+	size := h.opts.SnippetLength
+	if length <= size {
+		return 0, length
+	}
+	if len(matches) == 0 {
+		return 0, size
+	}
+
+	bestStart, bestEnd, bestScore := 0, 0, -1
+	for i, first := range matches {
+		windowEnd := first.start + size
+		score, lastEnd := 0, first.end
+		for _, m := range matches[i:] {
+			if m.start >= windowEnd {
+				break
+			}
+			if m.end > windowEnd {
+				score += windowEnd - m.start
+				lastEnd = windowEnd
+				break
+			}
+			score += m.end - m.start
+			lastEnd = m.end
+		}
+		if score > bestScore {
+			bestStart, bestEnd, bestScore = first.start, lastEnd, score
+		}
+	}
+
+	// 命中区域两侧平均补充上下文
+	start := bestStart - (size-(bestEnd-bestStart))/2
+	if start < 0 {
+		start = 0
+	}
+	if start+size > length {
+		start = length - size
+	}
+	return start, start + size
+}
+
+// render 输出text[start:end]，命中部分加上标记
+func (h *Highlighter) render(text []rune, matches []matchRange, start, end int) string {
+	var b strings.Builder
+	pos := start
+	for _, m := range matches {
+		if m.end <= start || m.start >= end {
+			continue
+		}
+		ms, me := max(m.start, start), min(m.end, end)
+		b.WriteString(h.escape(text[pos:ms]))
+		b.WriteString(h.opts.PreTag)
+		b.WriteString(h.escape(text[ms:me]))
+		b.WriteString(h.opts.PostTag)
+		pos = me
+	}
+	b.WriteString(h.escape(text[pos:end]))
+	return b.String()
+}
+
+// escape 按配置转义HTML
+func (h *Highlighter) escape(text []rune) string {
+	if h.opts.NoEscape {
+		return string(text)
+	}
+	return html.EscapeString(string(text))
+}
+
+// mergeRanges 合并相邻或重叠的区间，输入需按start排序
+func mergeRanges(ranges []matchRange) []matchRange {
+	var merged []matchRange
+	for _, r := range ranges {
+		if n := len(merged); n > 0 && r.start <= merged[n-1].end {
+			if r.end > merged[n-1].end {
+				merged[n-1].end = r.end
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// hasPrefixRunes 判断text是否以prefix开头
+func hasPrefixRunes(text, prefix []rune) bool {
+	if len(text) < len(prefix) {
+		return false
+	}
+	for i, r := range prefix {
+		if text[i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+// anyCovered 判断区间内是否有已命中的字符
+func anyCovered(covered []bool) bool {
+	for _, c := range covered {
+		if c {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		text  string
+		want  string
+	}{
+		{"longest term first", "流浪地球", "流浪地球2 高清版", "<em>流浪地球</em>2 高清版"},
+		{"case insensitive", "python", "Python3入门教程", "<em>Python</em>3入门教程"},
+		{"html escaped", "4K", "<b>4K</b> & HDR", "&lt;b&gt;<em>4K</em>&lt;/b&gt; &amp; HDR"},
+		{"whole text", "地球", "地球", "<em>地球</em>"},
+		{"single chars skipped with words", "的 电影", "我的电影", "我的<em>电影</em>"},
+		{"adjacent matches merged", "ab", "abab", "<em>abab</em>"},
+		{"no match", "xyz", "没有命中", "没有命中"},
+		{"empty query", "", "空查询", "空查询"},
+		{"empty text", "电影", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewHighlighter(tt.query, HighlightOptions{}).Highlight(tt.text); got != tt.want {
+				t.Errorf("Highlight(%q, %q) = %q, want %q", tt.query, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHighlightCustomTags(t *testing.T) {
+	h := NewHighlighter("高清", HighlightOptions{PreTag: "[", PostTag: "]", NoEscape: true})
+	if got, want := h.Highlight("<高清>"), "<[高清]>"; got != want {
+		t.Errorf("Highlight = %q, want %q", got, want)
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	before, after := strings.Repeat("前", 30), strings.Repeat("后", 30)
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"match in middle", before + "高清" + after, "…前前前前<em>高清</em>后后后后…"},
+		{"match at start", "高清" + after, "<em>高清</em>后后后后后后后后…"},
+		{"match at end", before + "高清", "…前前前前前前前前<em>高清</em>"},
+		{"no match", strings.Repeat("无", 30), "无无无无无无无无无无…"},
+		{"short text", "短高清", "短<em>高清</em>"},
+		{"densest window", "高清" + before + "高清高清" + after, "…前前前<em>高清高清</em>后后后…"},
+	}
+
+	h := NewHighlighter("高清", HighlightOptions{SnippetLength: 10})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.Snippet(tt.text); got != tt.want {
+				t.Errorf("Snippet = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeRanges(t *testing.T) {
+	got := mergeRanges([]matchRange{{0, 2}, {2, 4}, {3, 5}, {7, 8}})
+	want := []matchRange{{0, 5}, {7, 8}}
+	if len(got) != len(want) {
+		t.Fatalf("mergeRanges = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("mergeRanges = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package search
+
+import (
+	"pan-search-api/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// LikeEngine 基于 LIKE 的搜索，按分词结果拆分关键词，每个关键词都需要出现在标题或描述中
+type LikeEngine struct {
+	DB *gorm.DB
+}
+
+// Name 引擎名称
+func (e *LikeEngine) Name() string {
+	return EngineLike
+}
+
+// Search LIKE无法计算相关度，按热度给出递减的分数
+func (e *LikeEngine) Search(query string, limit int) ([]Hit, error) {
+	db := e.DB.Model(&models.Resource{}).
+		Select("id").
+		Where("valid = ?", true)
+	terms := QueryTerms(query)
+	if len(terms) == 0 {
+		return []Hit{}, nil
+	}
+	for _, keyword := range terms {
+		like := "%" + keyword + "%"
+		db = db.Where("title LIKE ? OR description LIKE ?", like, like)
+	}
+
+	var ids []string
+	if err := db.Order("view_count DESC, download_count DESC").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	hits := make([]Hit, len(ids))
+	for i, id := range ids {
+		hits[i] = Hit{ID: id, Score: float64(len(ids) - i)}
+	}
+	return hits, nil
+}
+
+// Index 数据库实时查询，无需维护索引
+func (e *LikeEngine) Index(doc Document) error {
+	return nil
+}
+
+// Remove 数据库实时查询，无需维护索引
+func (e *LikeEngine) Remove(id string) error {
+	return nil
+}
+
+// Close 无需释放资源
+func (e *LikeEngine) Close() error {
+	return nil
+}
+
+// Condition 每个关键词都需要出现在标题或描述中，没有可检索的关键词时不匹配任何资源
+func (e *LikeEngine) Condition(query string) (string, []interface{}) {
+	terms := QueryTerms(query)
+	if len(terms) == 0 {
+		return "1 = 0", nil
+	}
+	conditions := make([]string, len(terms))
+	args := make([]interface{}, 0, len(terms)*2)
+	for i, keyword := range terms {
+		like := "%" + keyword + "%"
+		conditions[i] = "(resources.title LIKE ? OR resources.description LIKE ?)"
+		args = append(args, like, like)
+	}
+	return "(" + strings.Join(conditions, " AND ") + ")", args
+}
@@ -0,0 +1,97 @@
+package search
+
+import (
+	"pan-search-api/models"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// 相关度表达式，列需要与 ft_title_description 索引一致
+const matchExpr = "MATCH(resources.title, resources.description) AGAINST (? IN NATURAL LANGUAGE MODE)"
+
+// 筛选表达式，要求包含全部关键词
+const matchBooleanExpr = "MATCH(resources.title, resources.description) AGAINST (? IN BOOLEAN MODE)"
+
+// MySQLEngine 基于 MySQL FULLTEXT 索引的搜索，索引由 MySQL 自动维护。
+// ft_title_description 需使用 ngram 解析器，否则无法检索中文
+type MySQLEngine struct {
+	DB *gorm.DB
+}
+
+// Name 引擎名称
+func (e *MySQLEngine) Name() string {
+	return EngineMySQL
+}
+
+// Search 按分词结果检索，每个关键词都需命中，按 MySQL 计算的相关度排序
+func (e *MySQLEngine) Search(query string, limit int) ([]Hit, error) {
+	terms := QueryTerms(query)
+	if len(terms) == 0 {
+		return []Hit{}, nil
+	}
+	natural := strings.Join(terms, " ")
+
+	db := e.DB.Model(&models.Resource{}).
+		Select("id, "+matchExpr+" AS score", natural).
+		Where("valid = ?", true)
+	if boolean := requiredTerms(terms); boolean != "" {
+		db = db.Where(matchBooleanExpr, boolean)
+	} else {
+		db = db.Where(matchExpr, natural)
+	}
+
+	var rows []struct {
+		ID    string
+		Score float64
+	}
+	if err := db.Order("score DESC").Limit(limit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	hits := make([]Hit, len(rows))
+	for i, row := range rows {
+		hits[i] = Hit{ID: row.ID, Score: row.Score}
+	}
+	return hits, nil
+}
+
+// Condition 与Search相同的FULLTEXT条件，没有可检索的关键词时不匹配任何资源
+func (e *MySQLEngine) Condition(query string) (string, []interface{}) {
+	terms := QueryTerms(query)
+	if len(terms) == 0 {
+		return "1 = 0", nil
+	}
+	if boolean := requiredTerms(terms); boolean != "" {
+		return matchBooleanExpr, []interface{}{boolean}
+	}
+	return matchExpr, []interface{}{strings.Join(terms, " ")}
+}
+
+// Index FULLTEXT索引由MySQL维护
+func (e *MySQLEngine) Index(doc Document) error {
+	return nil
+}
+
+// Remove FULLTEXT索引由MySQL维护
+func (e *MySQLEngine) Remove(id string) error {
+	return nil
+}
+
+// Close 无需释放资源
+func (e *MySQLEngine) Close() error {
+	return nil
+}
+
+// requiredTerms 生成布尔模式的查询串，每个关键词作为必须命中的短语；
+// 单字短于ngram分词长度，无法作为短语检索，不加入必须条件
+func requiredTerms(terms []string) string {
+	var parts []string
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) >= 2 {
+			parts = append(parts, `+"`+term+`"`)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,112 @@
+package search
+
+import (
+	"pan-search-api/models"
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+	"gorm.io/gorm"
+)
+
+// 拼音查询的最短长度，过短的字母组合会匹配大量无关资源
+const minPinyinQueryLength = 2
+
+var pinyinArgs = pinyin.NewArgs()
+
+// Pinyin 返回文本的全拼和首字母，汉字转为不带声调的拼音，英文数字原样保留，其他字符丢弃。
+// 例如"流浪地球2"返回"liulangdiqiu2"和"lldq2"
+func Pinyin(text string) (full, initials string) {
+	var fb, ib strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			if py := pinyin.SinglePinyin(r, pinyinArgs); len(py) > 0 && py[0] != "" {
+				fb.WriteString(py[0])
+				ib.WriteByte(py[0][0])
+			}
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			fb.WriteRune(r)
+			ib.WriteRune(r)
+		}
+	}
+	return fb.String(), ib.String()
+}
+
+// PinyinQuery 判断查询是否可能是拼音，返回去除空格、转为小写后的拼音串
+func PinyinQuery(query string) (string, bool) {
+	var b strings.Builder
+	hasLetter := false
+	for _, r := range strings.ToLower(query) {
+		switch {
+		case r >= 'a' && r <= 'z':
+			hasLetter = true
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '\'':
+			// 允许 "liu lang" 和 "xi'an" 的写法
+		default:
+			return "", false
+		}
+	}
+
+	normalized := b.String()
+	return normalized, hasLetter && len(normalized) >= minPinyinQueryLength
+}
+
+// Search 使用默认搜索引擎检索，查询为拼音时补充标题和标签的拼音匹配结果。
+// 拼音匹配的结果排在搜索引擎的结果之后，Score为0
+func Search(db *gorm.DB, query string, limit int) ([]Hit, error) {
+	hits, err := Default().Search(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	return appendPinyinHits(db, query, hits, limit)
+}
+
+// appendPinyinHits 查询为拼音且结果不足limit条时，在hits之后补充拼音匹配的结果
+func appendPinyinHits(db *gorm.DB, query string, hits []Hit, limit int) ([]Hit, error) {
+	normalized, ok := PinyinQuery(query)
+	if !ok || len(hits) >= limit {
+		return hits, nil
+	}
+
+	ids, err := matchPinyin(db, normalized, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(hits))
+	for _, hit := range hits {
+		seen[hit.ID] = struct{}{}
+	}
+	for _, id := range ids {
+		if len(hits) >= limit {
+			break
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		hits = append(hits, Hit{ID: id})
+	}
+	return hits, nil
+}
+
+// 标题或标签的全拼、首字母包含拼音串，参数为四个相同的LIKE模式
+const pinyinCondition = "(resources.title_initials LIKE ? OR resources.title_pinyin LIKE ? OR EXISTS (" +
+	"SELECT 1 FROM resource_tags WHERE resource_tags.resource_id = resources.id " +
+	"AND (resource_tags.initials LIKE ? OR resource_tags.pinyin LIKE ?)))"
+
+// matchPinyin 查询标题或标签的全拼、首字母包含拼音串的资源ID
+func matchPinyin(db *gorm.DB, normalized string, limit int) ([]string, error) {
+	like := "%" + normalized + "%"
+	var ids []string
+	err := db.Model(&models.Resource{}).
+		Where("valid = ?", true).
+		Where(pinyinCondition, like, like, like, like).
+		Order("view_count DESC, download_count DESC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,58 @@
+package search
+
+import "testing"
+
+func TestPinyin(t *testing.T) {
+	tests := []struct {
+		text     string
+		full     string
+		initials string
+	}{
+		{"流浪地球2", "liulangdiqiu2", "lldq2"},
+		{"流浪 地球", "liulangdiqiu", "lldq"},
+		{"Python入门", "pythonrumen", "pythonrm"},
+		{"蓝光·原盘！", "languangyuanpan", "lgyp"},
+		{"4K", "4k", "4k"},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			full, initials := Pinyin(tt.text)
+			if full != tt.full || initials != tt.initials {
+				t.Errorf("Pinyin(%q) = %q, %q, want %q, %q", tt.text, full, initials, tt.full, tt.initials)
+			}
+		})
+	}
+}
+
+func TestPinyinQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+		ok    bool
+	}{
+		{"full pinyin", "liulangdiqiu", "liulangdiqiu", true},
+		{"initials", "lldq", "lldq", true},
+		{"uppercase", "LLDQ", "lldq", true},
+		{"spaces", "liu lang di qiu", "liulangdiqiu", true},
+		{"apostrophe", "xi'an", "xian", true},
+		{"with digits", "lldq2", "lldq2", true},
+		{"too short", "l", "", false},
+		{"digits only", "2024", "", false},
+		{"han", "流浪地球", "", false},
+		{"mixed han", "流浪diqiu", "", false},
+		{"punctuation", "lldq!", "", false},
+		{"empty", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := PinyinQuery(tt.query)
+			if ok != tt.ok || (ok && got != tt.want) {
+				t.Errorf("PinyinQuery(%q) = %q, %v, want %q, %v", tt.query, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,397 @@
+package search
+
+import (
+	"fmt"
+	"pan-search-api/common"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// 字段限定符
+const (
+	FieldText   = ""       // 普通关键词，匹配标题和描述
+	FieldSource = "source" // 来源
+	FieldType   = "type"   // 文件类型
+	FieldTag    = "tag"    // 标签
+	FieldSize   = "size"   // 文件大小，需要比较符，如 size:>10GB
+	FieldAfter  = "after"  // 上传时间不早于该日期
+	FieldBefore = "before" // 上传时间早于该日期
+)
+
+// 查询中日期的格式
+const queryDateLayout = "2006-01-02"
+
+// queryFields 支持的字段限定符
+var queryFields = map[string]bool{
+	FieldSource: true,
+	FieldType:   true,
+	FieldTag:    true,
+	FieldSize:   true,
+	FieldAfter:  true,
+	FieldBefore: true,
+}
+
+// sizeOperators 大小比较符，长的在前以便优先匹配
+var sizeOperators = []string{">=", "<=", ">", "<"}
+
+// Query 解析后的查询，各子句之间为AND
+type Query struct {
+	Clauses []Clause
+}
+
+// Clause 查询子句，Terms之间为OR，Negate为true时排除匹配任一条件的资源
+type Clause struct {
+	Negate bool
+	Terms  []Term
+}
+
+// Term 单个查询条件
+type Term struct {
+	Field  string    // 字段限定符，普通关键词为空
+	Value  string    // 关键词、短语或字段值
+	Phrase bool      // 是否为引号包围的短语
+	Op     string    // size的比较符
+	Bytes  int64     // size的字节数
+	Time   time.Time // after/before的日期
+}
+
+// QueryError 查询语法错误，Position为出错位置（从0开始的字符下标）
+type QueryError struct {
+	Position int    `json:"position"`
+	Token    string `json:"token"`
+	Message  string `json:"message"`
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("位置%d附近的 %q: %s", e.Position, e.Token, e.Message)
+}
+
+// tokenKind 词法单元类型
+type tokenKind int
+
+const (
+	tokenTerm tokenKind = iota
+	tokenOr
+	tokenLParen
+	tokenRParen
+)
+
+// queryToken 词法单元
+type queryToken struct {
+	kind   tokenKind
+	pos    int
+	text   string // 原始文本，用于错误提示
+	negate bool
+	term   Term
+}
+
+// ParseQuery 解析高级查询语法：
+//
+//	"权力的游戏"          短语
+//	-预告                  排除
+//	4K OR 1080P            任一匹配，也可写作 (4K OR 1080P)
+//	source:aliyun type:mkv tag:4K size:>10GB after:2024-01-01 before:2025-01-01
+func ParseQuery(input string) (*Query, error) {
+	tokens, err := lexQuery([]rune(input))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens}
+	query := &Query{}
+	for !p.done() {
+		clause, err := p.parseClause()
+		if err != nil {
+			return nil, err
+		}
+		query.Clauses = append(query.Clauses, clause)
+	}
+	if len(query.Clauses) == 0 {
+		return nil, &QueryError{Message: "搜索条件不能为空"}
+	}
+	return query, nil
+}
+
+// HasText 是否包含需要由搜索引擎检索的关键词
+func (q *Query) HasText() bool {
+	for _, clause := range q.Clauses {
+		if clause.Negate {
+			continue
+		}
+		for _, term := range clause.Terms {
+			if term.Field == FieldText {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Text 返回全部未排除的关键词，用于高亮和搜索记录
+func (q *Query) Text() string {
+	var words []string
+	for _, clause := range q.Clauses {
+		if clause.Negate {
+			continue
+		}
+		for _, term := range clause.Terms {
+			if term.Field == FieldText {
+				words = append(words, term.Value)
+			}
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// queryParser 语法分析器
+type queryParser struct {
+	tokens []queryToken
+	i      int
+}
+
+func (p *queryParser) done() bool {
+	return p.i >= len(p.tokens)
+}
+
+// parseClause 解析 unit (OR unit)*
+func (p *queryParser) parseClause() (Clause, error) {
+	first := p.tokens[p.i]
+	clause, err := p.parseUnit()
+	if err != nil {
+		return Clause{}, err
+	}
+
+	for !p.done() && p.tokens[p.i].kind == tokenOr {
+		or := p.tokens[p.i]
+		p.i++
+		if p.done() {
+			return Clause{}, &QueryError{Position: or.pos, Token: or.text, Message: "OR 后面缺少搜索条件"}
+		}
+		next := p.tokens[p.i]
+		unit, err := p.parseUnit()
+		if err != nil {
+			return Clause{}, err
+		}
+		if clause.Negate || unit.Negate {
+			token := first
+			if unit.Negate {
+				token = next
+			}
+			return Clause{}, &QueryError{Position: token.pos, Token: token.text, Message: "OR 条件中不能使用排除，请改用 -(a OR b)"}
+		}
+		clause.Terms = append(clause.Terms, unit.Terms...)
+	}
+	return clause, nil
+}
+
+// parseUnit 解析单个条件或括号分组
+func (p *queryParser) parseUnit() (Clause, error) {
+	token := p.tokens[p.i]
+	p.i++
+
+	switch token.kind {
+	case tokenTerm:
+		return Clause{Negate: token.negate, Terms: []Term{token.term}}, nil
+	case tokenLParen:
+		return p.parseGroup(token)
+	case tokenOr:
+		return Clause{}, &QueryError{Position: token.pos, Token: token.text, Message: "OR 前面缺少搜索条件"}
+	default:
+		return Clause{}, &QueryError{Position: token.pos, Token: token.text, Message: "多余的右括号"}
+	}
+}
+
+// parseGroup 解析括号内的 term (OR term)*，不支持嵌套
+func (p *queryParser) parseGroup(open queryToken) (Clause, error) {
+	clause := Clause{Negate: open.negate}
+	expectTerm := true
+	for {
+		if p.done() {
+			return Clause{}, &QueryError{Position: open.pos, Token: open.text, Message: "括号未闭合"}
+		}
+		token := p.tokens[p.i]
+		p.i++
+
+		switch {
+		case token.kind == tokenRParen:
+			if len(clause.Terms) == 0 {
+				return Clause{}, &QueryError{Position: open.pos, Token: "()", Message: "括号内缺少搜索条件"}
+			}
+			if expectTerm {
+				return Clause{}, &QueryError{Position: token.pos, Token: token.text, Message: "OR 后面缺少搜索条件"}
+			}
+			return clause, nil
+		case token.kind == tokenLParen:
+			return Clause{}, &QueryError{Position: token.pos, Token: token.text, Message: "不支持嵌套括号"}
+		case token.kind == tokenOr:
+			if expectTerm {
+				return Clause{}, &QueryError{Position: token.pos, Token: token.text, Message: "OR 前面缺少搜索条件"}
+			}
+			expectTerm = true
+		default:
+			if !expectTerm {
+				return Clause{}, &QueryError{Position: token.pos, Token: token.text, Message: "括号内的条件之间需要用 OR 连接"}
+			}
+			if token.negate {
+				return Clause{}, &QueryError{Position: token.pos, Token: token.text, Message: "括号内不能使用排除，请将 - 放在括号前"}
+			}
+			clause.Terms = append(clause.Terms, token.term)
+			expectTerm = false
+		}
+	}
+}
+
+// lexQuery 词法分析
+func lexQuery(input []rune) ([]queryToken, error) {
+	var tokens []queryToken
+	i := 0
+	for i < len(input) {
+		r := input[i]
+		if unicode.IsSpace(r) {
+			i++
+			continue
+		}
+
+		start := i
+		negate := false
+		if r == '-' {
+			if i+1 >= len(input) || unicode.IsSpace(input[i+1]) {
+				return nil, &QueryError{Position: start, Token: "-", Message: "排除符号 - 后面缺少搜索条件"}
+			}
+			negate = true
+			i++
+			r = input[i]
+		}
+
+		switch r {
+		case '(':
+			tokens = append(tokens, queryToken{kind: tokenLParen, pos: start, text: string(input[start : i+1]), negate: negate})
+			i++
+			continue
+		case ')':
+			if negate {
+				return nil, &QueryError{Position: start, Token: "-)", Message: "排除符号 - 后面缺少搜索条件"}
+			}
+			tokens = append(tokens, queryToken{kind: tokenRParen, pos: start, text: ")"})
+			i++
+			continue
+		case '"':
+			value, end, err := readQuoted(input, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			if strings.TrimSpace(value) == "" {
+				return nil, &QueryError{Position: start, Token: string(input[start:i]), Message: "短语不能为空"}
+			}
+			tokens = append(tokens, queryToken{
+				kind:   tokenTerm,
+				pos:    start,
+				text:   string(input[start:i]),
+				negate: negate,
+				term:   Term{Field: FieldText, Value: value, Phrase: true},
+			})
+			continue
+		}
+
+		// 普通词或字段限定符，读到空白或括号为止，字段值可以用引号包围
+		wordStart := i
+		var field string
+		var value []rune
+		quoted := false
+		for i < len(input) && !unicode.IsSpace(input[i]) && input[i] != '(' && input[i] != ')' {
+			if input[i] == ':' && field == "" && isFieldName(input[wordStart:i]) {
+				field = strings.ToLower(string(input[wordStart:i]))
+				value = value[:0]
+				i++
+				if i < len(input) && input[i] == '"' {
+					quotedValue, end, err := readQuoted(input, i)
+					if err != nil {
+						return nil, err
+					}
+					value = []rune(quotedValue)
+					i = end
+					quoted = true
+					break
+				}
+				continue
+			}
+			value = append(value, input[i])
+			i++
+		}
+		text := string(input[start:i])
+
+		if field == "" {
+			word := string(value)
+			if word == "OR" && !negate {
+				tokens = append(tokens, queryToken{kind: tokenOr, pos: start, text: text})
+				continue
+			}
+			tokens = append(tokens, queryToken{
+				kind:   tokenTerm,
+				pos:    start,
+				text:   text,
+				negate: negate,
+				term:   Term{Field: FieldText, Value: word},
+			})
+			continue
+		}
+
+		term, err := parseFieldTerm(field, strings.TrimSpace(string(value)), quoted)
+		if err != nil {
+			return nil, &QueryError{Position: start, Token: text, Message: err.Error()}
+		}
+		tokens = append(tokens, queryToken{kind: tokenTerm, pos: start, text: text, negate: negate, term: term})
+	}
+	return tokens, nil
+}
+
+// readQuoted 读取从input[start]的引号开始的内容，返回内容和闭合引号之后的位置
+func readQuoted(input []rune, start int) (string, int, error) {
+	for i := start + 1; i < len(input); i++ {
+		if input[i] == '"' {
+			return string(input[start+1 : i]), i + 1, nil
+		}
+	}
+	return "", 0, &QueryError{Position: start, Token: string(input[start:]), Message: "引号未闭合"}
+}
+
+// isFieldName 判断冒号前的内容是否为支持的字段名（不区分大小写），
+// 其他前缀如 Re:Zero、https://... 作为普通关键词的一部分
+func isFieldName(name []rune) bool {
+	return queryFields[strings.ToLower(string(name))]
+}
+
+// parseFieldTerm 解析并校验字段限定符的值
+func parseFieldTerm(field, value string, quoted bool) (Term, error) {
+	if value == "" {
+		return Term{}, fmt.Errorf("%s: 后面缺少值", field)
+	}
+
+	term := Term{Field: field, Value: value, Phrase: quoted}
+	switch field {
+	case FieldSize:
+		for _, op := range sizeOperators {
+			if strings.HasPrefix(value, op) {
+				term.Op = op
+				break
+			}
+		}
+		if term.Op == "" {
+			return Term{}, fmt.Errorf("size 需要比较符，如 size:>10GB、size:<=700MB")
+		}
+		bytes, err := common.ParseSize(strings.TrimPrefix(value, term.Op))
+		if err != nil {
+			return Term{}, fmt.Errorf("无法识别的大小 %q，支持的单位：B、KB、MB、GB、TB", strings.TrimPrefix(value, term.Op))
+		}
+		term.Bytes = bytes
+	case FieldAfter, FieldBefore:
+		date, err := time.ParseInLocation(queryDateLayout, value, time.Local)
+		if err != nil {
+			return Term{}, fmt.Errorf("日期格式错误 %q，应为 YYYY-MM-DD", value)
+		}
+		term.Time = date
+	}
+	return term, nil
+}
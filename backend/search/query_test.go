@@ -0,0 +1,182 @@
+package search
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseQueryFieldPrefix(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Term
+	}{
+		{"colon in title", "Re:Zero", []Term{{Field: FieldText, Value: "Re:Zero"}}},
+		{"unknown field", "Note:重要", []Term{{Field: FieldText, Value: "Note:重要"}}},
+		{"share url", "https://pan.baidu.com/s/1AbCdEfGh",
+			[]Term{{Field: FieldText, Value: "https://pan.baidu.com/s/1AbCdEfGh"}}},
+		{"url with field-like path", "http://example.com/tag:4K",
+			[]Term{{Field: FieldText, Value: "http://example.com/tag:4K"}}},
+		{"empty unknown field", "Re:", []Term{{Field: FieldText, Value: "Re:"}}},
+		{"known field", "tag:4K", []Term{{Field: FieldTag, Value: "4K"}}},
+		{"known field uppercase", "Source:aliyun", []Term{{Field: FieldSource, Value: "aliyun"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := ParseQuery(tt.input)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) error: %v", tt.input, err)
+			}
+			var got []Term
+			for _, clause := range query.Clauses {
+				got = append(got, clause.Terms...)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseQuery(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	date := func(s string) time.Time {
+		d, _ := time.ParseInLocation(queryDateLayout, s, time.Local)
+		return d
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  []Clause
+	}{
+		{"keywords", "流浪地球 高清", []Clause{
+			{Terms: []Term{{Field: FieldText, Value: "流浪地球"}}},
+			{Terms: []Term{{Field: FieldText, Value: "高清"}}},
+		}},
+		{"phrase", `"权力的游戏 第八季"`, []Clause{
+			{Terms: []Term{{Field: FieldText, Value: "权力的游戏 第八季", Phrase: true}}},
+		}},
+		{"negate", "电影 -预告", []Clause{
+			{Terms: []Term{{Field: FieldText, Value: "电影"}}},
+			{Negate: true, Terms: []Term{{Field: FieldText, Value: "预告"}}},
+		}},
+		{"negate phrase", `-"抢先 版"`, []Clause{
+			{Negate: true, Terms: []Term{{Field: FieldText, Value: "抢先 版", Phrase: true}}},
+		}},
+		{"or", "4K OR 1080P", []Clause{
+			{Terms: []Term{{Field: FieldText, Value: "4K"}, {Field: FieldText, Value: "1080P"}}},
+		}},
+		{"lowercase or is a keyword", "4K or 1080P", []Clause{
+			{Terms: []Term{{Field: FieldText, Value: "4K"}}},
+			{Terms: []Term{{Field: FieldText, Value: "or"}}},
+			{Terms: []Term{{Field: FieldText, Value: "1080P"}}},
+		}},
+		{"group", "(4K OR 1080P) 电影", []Clause{
+			{Terms: []Term{{Field: FieldText, Value: "4K"}, {Field: FieldText, Value: "1080P"}}},
+			{Terms: []Term{{Field: FieldText, Value: "电影"}}},
+		}},
+		{"negated group", "-(预告 OR 花絮)", []Clause{
+			{Negate: true, Terms: []Term{{Field: FieldText, Value: "预告"}, {Field: FieldText, Value: "花絮"}}},
+		}},
+		{"hyphen inside word", "spider-man", []Clause{
+			{Terms: []Term{{Field: FieldText, Value: "spider-man"}}},
+		}},
+		{"fields", `source:aliyun type:mkv tag:"蓝光 原盘"`, []Clause{
+			{Terms: []Term{{Field: FieldSource, Value: "aliyun"}}},
+			{Terms: []Term{{Field: FieldType, Value: "mkv"}}},
+			{Terms: []Term{{Field: FieldTag, Value: "蓝光 原盘", Phrase: true}}},
+		}},
+		{"size", "size:>=1.5GB size:<700MB", []Clause{
+			{Terms: []Term{{Field: FieldSize, Value: ">=1.5GB", Op: ">=", Bytes: 1610612736}}},
+			{Terms: []Term{{Field: FieldSize, Value: "<700MB", Op: "<", Bytes: 734003200}}},
+		}},
+		{"dates", "after:2024-01-01 before:2025-01-01", []Clause{
+			{Terms: []Term{{Field: FieldAfter, Value: "2024-01-01", Time: date("2024-01-01")}}},
+			{Terms: []Term{{Field: FieldBefore, Value: "2025-01-01", Time: date("2025-01-01")}}},
+		}},
+		{"field or", "source:aliyun OR source:quark", []Clause{
+			{Terms: []Term{{Field: FieldSource, Value: "aliyun"}, {Field: FieldSource, Value: "quark"}}},
+		}},
+		{"negated field", "-type:iso", []Clause{
+			{Negate: true, Terms: []Term{{Field: FieldType, Value: "iso"}}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := ParseQuery(tt.input)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) error: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(query.Clauses, tt.want) {
+				t.Errorf("ParseQuery(%q) = %+v, want %+v", tt.input, query.Clauses, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		position int
+		token    string
+	}{
+		{"empty", "   ", 0, ""},
+		{"dangling minus", "电影 -", 3, "-"},
+		{"minus before space", "- 电影", 0, "-"},
+		{"unclosed quote", `电影 "权力的`, 3, `"权力的`},
+		{"empty phrase", `""`, 0, `""`},
+		{"leading or", "OR 电影", 0, "OR"},
+		{"trailing or", "电影 OR", 3, "OR"},
+		{"unclosed group", "(4K OR 1080P", 0, "("},
+		{"extra right paren", "电影)", 2, ")"},
+		{"empty group", "()", 0, "()"},
+		{"nested group", "(4K OR (1080P))", 7, "("},
+		{"group without or", "(4K 1080P)", 4, "1080P"},
+		{"negate inside group", "(4K OR -1080P)", 7, "-1080P"},
+		{"negate in or", "电影 OR -预告", 6, "-预告"},
+		{"field without value", "tag:", 0, "tag:"},
+		{"size without operator", "size:10GB", 0, "size:10GB"},
+		{"size bad unit", "size:>10XB", 0, "size:>10XB"},
+		{"bad date", "after:2024/01/01", 0, "after:2024/01/01"},
+		{"unclosed field quote", `tag:"蓝光`, 4, `"蓝光`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseQuery(tt.input)
+			var queryErr *QueryError
+			if !errors.As(err, &queryErr) {
+				t.Fatalf("ParseQuery(%q) error = %v, want *QueryError", tt.input, err)
+			}
+			if queryErr.Position != tt.position || queryErr.Token != tt.token {
+				t.Errorf("ParseQuery(%q) error at %d %q, want %d %q (%s)",
+					tt.input, queryErr.Position, queryErr.Token, tt.position, tt.token, queryErr.Message)
+			}
+		})
+	}
+}
+
+func TestQueryText(t *testing.T) {
+	query, err := ParseQuery(`"权力的游戏" 4K OR 1080P -预告 source:aliyun`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !query.HasText() {
+		t.Error("HasText() = false, want true")
+	}
+	if got, want := query.Text(), "权力的游戏 4K 1080P"; got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+
+	fieldsOnly, err := ParseQuery("source:aliyun -预告")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fieldsOnly.HasText() {
+		t.Error("HasText() = true for a query without positive keywords")
+	}
+}
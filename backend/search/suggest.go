@@ -0,0 +1,440 @@
+package search
+
+import (
+	"log"
+	"math"
+	"pan-search-api/config"
+	"pan-search-api/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// 搜索建议默认配置
+const (
+	defaultSuggestRefreshInterval = 5 * time.Minute
+	defaultSuggestHalfLife        = 72 * time.Hour
+	defaultSuggestLookback        = 30 * 24 * time.Hour
+	defaultSuggestMaxTitles       = 20000
+)
+
+const (
+	// 每个前缀节点预先保存的候选数量，也是单次建议的上限
+	suggestTopK = 10
+	// 超过该深度的节点不保存候选，查询时遍历子树；输入较长时子树很小
+	suggestTopDepth = 8
+	// 过长的搜索词不作为建议
+	maxSuggestionLength = 50
+	// 前缀树中键的最大长度，更长的输入不再有建议，以限制索引的内存占用
+	maxSuggestKeyLength = 24
+	// 标题和标签的权重系数，低于近期被搜索过的关键词
+	titleWeightFactor = 0.1
+)
+
+// suggestion 建议词及其权重
+type suggestion struct {
+	text   string
+	weight float64
+}
+
+// suggestionWeights 按小写合并的建议词权重，保留首次出现的写法
+type suggestionWeights map[string]*suggestion
+
+// add 累加建议词的权重
+func (w suggestionWeights) add(text string, weight float64) {
+	key := strings.ToLower(text)
+	if sg, ok := w[key]; ok {
+		sg.weight += weight
+		return
+	}
+	w[key] = &suggestion{text: text, weight: weight}
+}
+
+// trieNode 前缀树节点，子节点较少，使用切片比map节省内存
+type trieNode struct {
+	label    rune
+	children []*trieNode
+	entries  []int32 // 以该节点结尾的建议词
+	top      []int32 // 子树中权重最高的建议词，按权重降序
+}
+
+// child 查找子节点
+func (n *trieNode) child(r rune) *trieNode {
+	for _, c := range n.children {
+		if c.label == r {
+			return c
+		}
+	}
+	return nil
+}
+
+// suggestIndex 不可变的前缀索引，重建时整体替换
+type suggestIndex struct {
+	root        *trieNode
+	suggestions []suggestion
+}
+
+// Suggester 从搜索记录、资源标题和标签定时构建前缀索引，查询时不访问数据库
+type Suggester struct {
+	db        *gorm.DB
+	cfg       config.SuggestConfig
+	mu        sync.RWMutex
+	index     *suggestIndex
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+var (
+	suggesterMu      sync.RWMutex
+	defaultSuggester *Suggester
+)
+
+// StartSuggester 启动定时重建并设为默认实例，启动后立即在后台构建一次
+func StartSuggester(db *gorm.DB, cfg config.SuggestConfig) *Suggester {
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = defaultSuggestRefreshInterval
+	}
+	if cfg.HalfLife <= 0 {
+		cfg.HalfLife = defaultSuggestHalfLife
+	}
+	if cfg.Lookback <= 0 {
+		cfg.Lookback = defaultSuggestLookback
+	}
+	if cfg.MaxTitles <= 0 {
+		cfg.MaxTitles = defaultSuggestMaxTitles
+	}
+
+	s := &Suggester{db: db, cfg: cfg, done: make(chan struct{})}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.loop()
+	}()
+
+	suggesterMu.Lock()
+	defaultSuggester = s
+	suggesterMu.Unlock()
+	return s
+}
+
+// DefaultSuggester 返回默认实例，未启动时返回nil
+func DefaultSuggester() *Suggester {
+	suggesterMu.RLock()
+	defer suggesterMu.RUnlock()
+	return defaultSuggester
+}
+
+// Stop 停止定时重建，等待正在进行的重建完成后返回
+func (s *Suggester) Stop() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+	s.wg.Wait()
+}
+
+// loop 立即构建一次，之后按间隔重建
+func (s *Suggester) loop() {
+	ticker := time.NewTicker(s.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Refresh(); err != nil {
+			log.Printf("Failed to build suggestion index: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Refresh 从数据库重建前缀索引
+func (s *Suggester) Refresh() error {
+	weights := make(suggestionWeights)
+	if err := s.loadKeywords(weights); err != nil {
+		return err
+	}
+	if err := s.loadTitles(weights); err != nil {
+		return err
+	}
+	if err := s.loadTags(weights); err != nil {
+		return err
+	}
+
+	index := buildSuggestIndex(weights)
+	s.mu.Lock()
+	s.index = index
+	s.mu.Unlock()
+	return nil
+}
+
+// Suggest 返回以prefix开头的建议词，按权重降序，最多limit条。
+// 汉字建议词同时可以用拼音全拼或首字母的前缀匹配
+func (s *Suggester) Suggest(prefix string, limit int) []string {
+	s.mu.RLock()
+	index := s.index
+	s.mu.RUnlock()
+
+	suggestions := []string{}
+	key := normalizeSuggestion(prefix)
+	if index == nil || key == "" {
+		return suggestions
+	}
+	if limit > suggestTopK {
+		limit = suggestTopK
+	}
+
+	// 拼音可以带空格或隔音符输入，如 "liu lang"
+	ids := index.lookup(strings.ToLower(key))
+	if normalized, ok := PinyinQuery(key); ok && normalized != strings.ToLower(key) {
+		ids = append(ids, index.lookup(normalized)...)
+	}
+	for _, id := range topIDs(ids, limit) {
+		suggestions = append(suggestions, index.suggestions[id].text)
+	}
+	return suggestions
+}
+
+// lookup 返回以key为前缀的候选
+func (idx *suggestIndex) lookup(key string) []int32 {
+	node := idx.root
+	depth := 0
+	for _, r := range key {
+		if node = node.child(r); node == nil {
+			return nil
+		}
+		depth++
+	}
+	if depth > suggestTopDepth {
+		return idx.collect(node)
+	}
+	// 返回副本，索引会被并发读取，调用方可能修改结果
+	return append([]int32(nil), node.top...)
+}
+
+// loadKeywords 按天统计搜索次数并随时间衰减，只统计有结果的搜索。
+// 最近一次搜索没有结果的关键词不作为建议，例如资源已被删除
+func (s *Suggester) loadKeywords(weights suggestionWeights) error {
+	now := time.Now()
+	var rows []struct {
+		Keyword  string
+		Day      time.Time
+		Hits     int64
+		LastHit  *time.Time
+		LastZero *time.Time
+	}
+	if err := s.db.Model(&models.SearchRecord{}).
+		Select("keyword, DATE(search_time) AS day, "+
+			"SUM(CASE WHEN result_count > 0 THEN 1 ELSE 0 END) AS hits, "+
+			"MAX(CASE WHEN result_count > 0 THEN search_time END) AS last_hit, "+
+			"MAX(CASE WHEN result_count = 0 THEN search_time END) AS last_zero").
+		Where("search_time >= ?", now.Add(-s.cfg.Lookback)).
+		Group("keyword, DATE(search_time)").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	type keywordStat struct {
+		weight            float64
+		lastHit, lastZero time.Time
+	}
+	stats := make(map[string]*keywordStat)
+	for _, row := range rows {
+		keyword := normalizeSuggestion(row.Keyword)
+		if keyword == "" || utf8.RuneCountInString(keyword) > maxSuggestionLength {
+			continue
+		}
+		stat, ok := stats[keyword]
+		if !ok {
+			stat = &keywordStat{}
+			stats[keyword] = stat
+		}
+		// 按当天中午计算衰减，精度足够且不需要逐条读取记录
+		age := now.Sub(row.Day.Add(12 * time.Hour))
+		if age < 0 {
+			age = 0
+		}
+		stat.weight += float64(row.Hits) * math.Pow(0.5, float64(age)/float64(s.cfg.HalfLife))
+		if row.LastHit != nil && row.LastHit.After(stat.lastHit) {
+			stat.lastHit = *row.LastHit
+		}
+		if row.LastZero != nil && row.LastZero.After(stat.lastZero) {
+			stat.lastZero = *row.LastZero
+		}
+	}
+
+	for keyword, stat := range stats {
+		if stat.weight > 0 && !stat.lastZero.After(stat.lastHit) {
+			weights.add(keyword, stat.weight)
+		}
+	}
+	return nil
+}
+
+// loadTitles 收录浏览量最高的资源标题
+func (s *Suggester) loadTitles(weights suggestionWeights) error {
+	var resources []models.Resource
+	if err := s.db.Select("title", "view_count", "download_count").
+		Where("valid = ?", true).
+		Order("view_count DESC").
+		Limit(s.cfg.MaxTitles).
+		Find(&resources).Error; err != nil {
+		return err
+	}
+
+	for _, resource := range resources {
+		title := normalizeSuggestion(resource.Title)
+		if title == "" || utf8.RuneCountInString(title) > maxSuggestionLength {
+			continue
+		}
+		popularity := float64(resource.ViewCount + resource.DownloadCount)
+		weights.add(title, titleWeightFactor*(1+math.Log1p(popularity)))
+	}
+	return nil
+}
+
+// loadTags 收录有效资源的标签，按资源数量计算权重
+func (s *Suggester) loadTags(weights suggestionWeights) error {
+	var tags []struct {
+		TagName string
+		Count   int64
+	}
+	if err := s.db.Model(&models.ResourceTag{}).
+		Select("resource_tags.tag_name, COUNT(*) AS count").
+		Joins("JOIN resources ON resources.id = resource_tags.resource_id").
+		Where("resources.valid = ? AND resources.deleted_at IS NULL", true).
+		Group("resource_tags.tag_name").
+		Scan(&tags).Error; err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		name := normalizeSuggestion(tag.TagName)
+		if name == "" {
+			continue
+		}
+		weights.add(name, titleWeightFactor*(1+math.Log1p(float64(tag.Count))))
+	}
+	return nil
+}
+
+// buildSuggestIndex 构建前缀树，建议词按小写插入，含汉字的同时插入拼音全拼和首字母
+func buildSuggestIndex(weights suggestionWeights) *suggestIndex {
+	index := &suggestIndex{root: &trieNode{}}
+	for _, sg := range weights {
+		index.suggestions = append(index.suggestions, *sg)
+	}
+	// 权重相同时按文本排序，保证每次构建的结果一致
+	sort.Slice(index.suggestions, func(i, j int) bool {
+		a, b := index.suggestions[i], index.suggestions[j]
+		if a.weight != b.weight {
+			return a.weight > b.weight
+		}
+		return a.text < b.text
+	})
+
+	for i, sg := range index.suggestions {
+		id := int32(i)
+		index.insert(strings.ToLower(sg.text), id)
+		if hasHan(sg.text) {
+			full, initials := Pinyin(sg.text)
+			index.insert(full, id)
+			index.insert(initials, id)
+		}
+	}
+	index.computeTop(index.root, 0)
+	return index
+}
+
+// insert 插入一个键，超过maxSuggestKeyLength的部分被截断
+func (idx *suggestIndex) insert(key string, id int32) {
+	if key == "" {
+		return
+	}
+	node := idx.root
+	depth := 0
+	for _, r := range key {
+		if depth >= maxSuggestKeyLength {
+			break
+		}
+		child := node.child(r)
+		if child == nil {
+			child = &trieNode{label: r}
+			node.children = append(node.children, child)
+		}
+		node = child
+		depth++
+	}
+	for _, existing := range node.entries {
+		if existing == id {
+			return
+		}
+	}
+	node.entries = append(node.entries, id)
+}
+
+// computeTop 自底向上计算各节点的候选，只保存深度不超过suggestTopDepth的节点。
+// 建议词ID按权重降序分配，ID越小权重越高
+func (idx *suggestIndex) computeTop(node *trieNode, depth int) []int32 {
+	candidates := append([]int32{}, node.entries...)
+	for _, child := range node.children {
+		candidates = append(candidates, idx.computeTop(child, depth+1)...)
+	}
+	top := topIDs(candidates, suggestTopK)
+	if depth <= suggestTopDepth {
+		node.top = top
+	}
+	return top
+}
+
+// collect 遍历子树获取候选，用于未保存候选的深层节点
+func (idx *suggestIndex) collect(node *trieNode) []int32 {
+	var candidates []int32
+	var walk func(n *trieNode)
+	walk = func(n *trieNode) {
+		candidates = append(candidates, n.entries...)
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+	walk(node)
+	return topIDs(candidates, suggestTopK)
+}
+
+// topIDs 去重后返回权重最高的k个ID
+func topIDs(ids []int32, k int) []int32 {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	top := make([]int32, 0, k)
+	for i, id := range ids {
+		if i > 0 && id == ids[i-1] {
+			continue
+		}
+		if len(top) >= k {
+			break
+		}
+		top = append(top, id)
+	}
+	return top
+}
+
+// normalizeSuggestion 去除首尾空白并合并连续空白
+func normalizeSuggestion(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// hasHan 是否包含汉字
+func hasHan(text string) bool {
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package search
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testSuggester(words map[string]float64) *Suggester {
+	weights := make(suggestionWeights)
+	for text, weight := range words {
+		weights.add(text, weight)
+	}
+	return &Suggester{index: buildSuggestIndex(weights)}
+}
+
+func TestSuggest(t *testing.T) {
+	s := testSuggester(map[string]float64{
+		"流浪地球":            10,
+		"流浪地球2":           8,
+		"流星花园":            5,
+		"Python教程":        6,
+		"python入门":        3,
+		"Java":            2,
+		"game of thrones": 4,
+	})
+
+	tests := []struct {
+		name   string
+		prefix string
+		limit  int
+		want   []string
+	}{
+		{"chinese prefix", "流浪", 10, []string{"流浪地球", "流浪地球2"}},
+		{"weight order", "流", 10, []string{"流浪地球", "流浪地球2", "流星花园"}},
+		{"limit", "流", 2, []string{"流浪地球", "流浪地球2"}},
+		{"case insensitive", "PYTH", 10, []string{"Python教程", "python入门"}},
+		{"full pinyin", "liulang", 10, []string{"流浪地球", "流浪地球2"}},
+		{"pinyin with spaces", "liu lang", 10, []string{"流浪地球", "流浪地球2"}},
+		{"pinyin initials", "lx", 10, []string{"流星花园"}},
+		{"surrounding space", "  game  of ", 10, []string{"game of thrones"}},
+		{"exact word", "java", 10, []string{"Java"}},
+		{"no match", "rust", 10, []string{}},
+		{"empty prefix", "   ", 10, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Suggest(tt.prefix, tt.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Suggest(%q) = %q, want %q", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestBeforeBuild(t *testing.T) {
+	if got := (&Suggester{}).Suggest("流浪", 10); len(got) != 0 {
+		t.Errorf("Suggest() before build = %q, want empty", got)
+	}
+}
+
+func TestSuggestLimitCapped(t *testing.T) {
+	words := make(map[string]float64)
+	for i := 0; i < suggestTopK+5; i++ {
+		words[fmt.Sprintf("movie %02d", i)] = float64(100 - i)
+	}
+	s := testSuggester(words)
+
+	got := s.Suggest("movie", 100)
+	if len(got) != suggestTopK || got[0] != "movie 00" {
+		t.Errorf("Suggest() = %q, want top %d starting with %q", got, suggestTopK, "movie 00")
+	}
+}
+
+func TestSuggestDeepPrefix(t *testing.T) {
+	// 超过suggestTopDepth的节点不保存候选，查询时遍历子树
+	long := "abcdefghijklmnop"
+	s := testSuggester(map[string]float64{long + "1": 2, long + "2": 1, "abcdefghijklmnoz": 3})
+
+	got := s.Suggest(long, 10)
+	want := []string{long + "1", long + "2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest(%q) = %q, want %q", long, got, want)
+	}
+
+	// 超过maxSuggestKeyLength的输入不再有建议
+	if got := s.Suggest(strings.Repeat("a", maxSuggestKeyLength+1), 10); len(got) != 0 {
+		t.Errorf("Suggest(too long) = %q, want empty", got)
+	}
+}
+
+func TestSuggestionWeightsMergeCase(t *testing.T) {
+	weights := make(suggestionWeights)
+	weights.add("Python", 1)
+	weights.add("python", 2)
+
+	// 按小写合并，保留首次出现的写法
+	if len(weights) != 1 || weights["python"].text != "Python" || weights["python"].weight != 3 {
+		t.Errorf("suggestionWeights = %+v, want one entry Python with weight 3", weights["python"])
+	}
+}
+
+func TestTopIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []int32
+		k    int
+		want []int32
+	}{
+		{"sorted and deduplicated", []int32{5, 1, 3, 1, 5}, 10, []int32{1, 3, 5}},
+		{"capped", []int32{4, 3, 2, 1}, 2, []int32{1, 2}},
+		{"empty", nil, 3, []int32{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := topIDs(tt.ids, tt.k); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("topIDs(%v, %d) = %v, want %v", tt.ids, tt.k, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeSuggestion(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"  流浪地球  ", "流浪地球"},
+		{"game \t of\n thrones", "game of thrones"},
+		{"   ", ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeSuggestion(tt.input); got != tt.want {
+			t.Errorf("normalizeSuggestion(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package search
+
+import (
+	"bufio"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 用户词典中未指定词频时使用的默认词频
+const defaultWordFreq = 10000
+
+//go:embed dict/builtin.txt
+var builtinDict string
+
+// Tokenizer 分词器，索引和查询使用同一个分词器
+type Tokenizer interface {
+	Tokenize(text string) []string
+}
+
+// fingerprinter 可以给出分词规则指纹的分词器，指纹变化时embedded索引需要重建
+type fingerprinter interface {
+	Fingerprint() string
+}
+
+// Dictionary 分词词典，词语统一转为小写
+type Dictionary struct {
+	freq   map[string]float64
+	total  float64
+	maxLen int // 最长词语的字符数
+}
+
+// NewDictionary 创建空词典
+func NewDictionary() *Dictionary {
+	return &Dictionary{freq: make(map[string]float64)}
+}
+
+// AddWord 添加词语，已存在时覆盖词频
+func (d *Dictionary) AddWord(word string, freq float64) {
+	word = strings.ToLower(strings.TrimSpace(word))
+	if word == "" || freq <= 0 {
+		return
+	}
+
+	d.total += freq - d.freq[word]
+	d.freq[word] = freq
+	if n := utf8.RuneCountInString(word); n > d.maxLen {
+		d.maxLen = n
+	}
+}
+
+// Load 读取词典，每行格式为"词语 [词频]"，#开头的行为注释
+func (d *Dictionary) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		freq := float64(defaultWordFreq)
+		if len(fields) > 1 {
+			value, err := strconv.ParseFloat(fields[1], 64)
+			if err != nil || value <= 0 {
+				return fmt.Errorf("line %d: invalid frequency %q", line, fields[1])
+			}
+			freq = value
+		}
+		d.AddWord(fields[0], freq)
+	}
+	return scanner.Err()
+}
+
+// LoadFile 读取词典文件
+func (d *Dictionary) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := d.Load(file); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Contains 判断词语是否在词典中
+func (d *Dictionary) Contains(word string) bool {
+	_, ok := d.freq[word]
+	return ok
+}
+
+// Size 词语数量
+func (d *Dictionary) Size() int {
+	return len(d.freq)
+}
+
+// Fingerprint 词典内容的摘要
+func (d *Dictionary) Fingerprint() string {
+	words := make([]string, 0, len(d.freq))
+	for word := range d.freq {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+
+	h := sha1.New()
+	for _, word := range words {
+		fmt.Fprintf(h, "%s %g\n", word, d.freq[word])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// logProb 词语的对数概率，不在词典中的词语按词频1计算
+func (d *Dictionary) logProb(word string) float64 {
+	freq, ok := d.freq[word]
+	if !ok {
+		freq = 1
+	}
+	return math.Log(freq) - math.Log(d.total+1)
+}
+
+// Segmenter 基于词典的中文分词器，按最大概率路径切分，英文和数字连续的部分作为一个词
+type Segmenter struct {
+	dict *Dictionary
+}
+
+// NewSegmenter 创建分词器
+func NewSegmenter(dict *Dictionary) *Segmenter {
+	return &Segmenter{dict: dict}
+}
+
+// Cut 切分文本，结果已转为小写，标点和空白被丢弃
+func (s *Segmenter) Cut(text string) []string {
+	var tokens []string
+	for _, run := range splitRuns(strings.ToLower(text)) {
+		tokens = append(tokens, s.cutRun(run)...)
+	}
+	return tokens
+}
+
+// Tokenize 搜索模式切分，在Cut的基础上补充长词中包含的词典词语和英文数字片段，
+// 使"地球"能命中"流浪地球"，"python"能命中"python3"
+func (s *Segmenter) Tokenize(text string) []string {
+	var tokens []string
+	for _, word := range s.Cut(text) {
+		runes := []rune(word)
+		if len(runes) > 2 {
+			for size := 2; size < len(runes); size++ {
+				for i := 0; i+size <= len(runes); i++ {
+					if sub := string(runes[i : i+size]); s.dict.Contains(sub) {
+						tokens = append(tokens, sub)
+					}
+				}
+			}
+		}
+
+		units := alnumUnits(runes)
+		for _, unit := range units {
+			if unit != word {
+				tokens = append(tokens, unit)
+			}
+			for _, part := range alnumParts(unit) {
+				if part != unit {
+					tokens = append(tokens, part)
+				}
+			}
+		}
+		tokens = append(tokens, word)
+	}
+	return tokens
+}
+
+// Fingerprint 分词规则指纹
+func (s *Segmenter) Fingerprint() string {
+	return "segmenter:" + s.dict.Fingerprint()
+}
+
+// cutRun 切分一段不含分隔符的文本
+func (s *Segmenter) cutRun(run []rune) []string {
+	n := len(run)
+	// unitEnd[i] 为从i开始的默认切分单元的结束位置：汉字为单字，英文数字为整段
+	unitEnd := make([]int, n)
+	for i := n - 1; i >= 0; i-- {
+		switch {
+		case unicode.Is(unicode.Han, run[i]):
+			unitEnd[i] = i + 1
+		case i+1 < n && !unicode.Is(unicode.Han, run[i+1]):
+			unitEnd[i] = unitEnd[i+1]
+		default:
+			unitEnd[i] = i + 1
+		}
+	}
+
+	// 从后向前动态规划，route[i]为run[i:]的最大对数概率，next[i]为第一个词的结束位置
+	route := make([]float64, n+1)
+	next := make([]int, n+1)
+	for i := n - 1; i >= 0; i-- {
+		end := unitEnd[i]
+		route[i] = s.dict.logProb(string(run[i:end])) + route[end]
+		next[i] = end
+
+		for j := i + 2; j <= n && j-i <= s.dict.maxLen; j++ {
+			word := string(run[i:j])
+			if !s.dict.Contains(word) {
+				continue
+			}
+			// 词语不能截断英文数字片段
+			if j < n && !isHanBoundary(run, j) {
+				continue
+			}
+			if score := s.dict.logProb(word) + route[j]; score > route[i] {
+				route[i] = score
+				next[i] = j
+			}
+		}
+	}
+
+	var tokens []string
+	for i := 0; i < n; i = next[i] {
+		tokens = append(tokens, string(run[i:next[i]]))
+	}
+	return tokens
+}
+
+var (
+	defaultDict     *Dictionary
+	defaultDictOnce sync.Once
+)
+
+// BuiltinDictionary 返回加载了内置词典的默认词典，首次调用时加载
+func BuiltinDictionary() *Dictionary {
+	defaultDictOnce.Do(func() {
+		defaultDict = NewDictionary()
+		if err := defaultDict.Load(strings.NewReader(builtinDict)); err != nil {
+			panic("search: invalid builtin dictionary: " + err.Error())
+		}
+	})
+	return defaultDict
+}
+
+// LoadUserDictionary 将用户词典加入默认词典，需在初始化搜索引擎之前调用
+func LoadUserDictionary(path string) error {
+	return BuiltinDictionary().LoadFile(path)
+}
+
+// DefaultTokenizer 返回索引和查询使用的默认分词器
+func DefaultTokenizer() Tokenizer {
+	return NewSegmenter(BuiltinDictionary())
+}
+
+// splitRuns 按标点和空白切分为若干段，每段只包含汉字、字母和数字
+func splitRuns(text string) [][]rune {
+	var runs [][]rune
+	var current []rune
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			current = append(current, r)
+			continue
+		}
+		if len(current) > 0 {
+			runs = append(runs, current)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		runs = append(runs, current)
+	}
+	return runs
+}
+
+// isHanBoundary 判断run[i-1]和run[i]之间是否可以切分，英文数字片段内部不能切分
+func isHanBoundary(run []rune, i int) bool {
+	return unicode.Is(unicode.Han, run[i-1]) || unicode.Is(unicode.Han, run[i])
+}
+
+// alnumUnits 提取词语中的英文数字片段
+func alnumUnits(runes []rune) []string {
+	var units []string
+	start := -1
+	for i, r := range runes {
+		if unicode.Is(unicode.Han, r) {
+			if start >= 0 {
+				units = append(units, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		units = append(units, string(runes[start:]))
+	}
+	return units
+}
+
+// alnumParts 按字母和数字的交界切分英文数字片段，丢弃单个字母
+func alnumParts(unit string) []string {
+	var parts []string
+	var current []rune
+	flush := func() {
+		if len(current) > 1 || (len(current) == 1 && unicode.IsDigit(current[0])) {
+			parts = append(parts, string(current))
+		}
+		current = current[:0]
+	}
+	for _, r := range unit {
+		if len(current) > 0 && unicode.IsDigit(r) != unicode.IsDigit(current[len(current)-1]) {
+			flush()
+		}
+		current = append(current, r)
+	}
+	flush()
+	return parts
+}
+
+// QueryTerms 将查询切分为关键词，供直接查询数据库的引擎使用
+func QueryTerms(query string) []string {
+	return NewSegmenter(BuiltinDictionary()).Cut(query)
+}
@@ -0,0 +1,147 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testDictionary 测试用的小词典，结果不受内置词典更新的影响
+func testDictionary(t *testing.T) *Dictionary {
+	t.Helper()
+	dict := NewDictionary()
+	err := dict.Load(strings.NewReader(`# 测试词典
+流浪 100
+地球 200
+流浪地球 1000
+高清 500
+蓝光 300
+原盘 300
+权力的游戏 800
+游戏 400
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dict
+}
+
+func TestSegmenterCut(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"dictionary word", "流浪地球", []string{"流浪地球"}},
+		{"han with digit", "流浪地球2高清", []string{"流浪地球", "2", "高清"}},
+		{"longest probable word", "权力的游戏第八季", []string{"权力的游戏", "第", "八", "季"}},
+		{"alnum run kept whole", "Python3入门", []string{"python3", "入", "门"}},
+		{"lowercase and spaces", "蓝光原盘 4K HDR", []string{"蓝光", "原盘", "4k", "hdr"}},
+		{"mixed alnum", "iPhone15ProMax", []string{"iphone15promax"}},
+		{"han then alnum", "高清mkv版", []string{"高清", "mkv", "版"}},
+		{"punctuation dropped", "流浪地球，高清！", []string{"流浪地球", "高清"}},
+		{"only punctuation", "  ,.!!  ", nil},
+		{"empty", "", nil},
+	}
+
+	s := NewSegmenter(testDictionary(t))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Cut(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Cut(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegmenterTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"sub words of long word", "流浪地球", []string{"流浪", "地球", "流浪地球"}},
+		{"sub word at end", "权力的游戏", []string{"游戏", "权力的游戏"}},
+		{"letters and digits split", "Python3", []string{"python", "3", "python3"}},
+		{"single letters dropped", "a1b2", []string{"1", "2", "a1b2"}},
+		{"mixed alnum", "iPhone15ProMax", []string{"iphone", "15", "promax", "iphone15promax"}},
+		{"digit prefix", "4K", []string{"4", "4k"}},
+		{"short word unchanged", "高清", []string{"高清"}},
+		{"empty", "", nil},
+	}
+
+	s := NewSegmenter(testDictionary(t))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegmenterDoesNotSplitAlnum(t *testing.T) {
+	dict := testDictionary(t)
+	// 词典中的词语跨越英文数字片段时不能使用
+	dict.AddWord("地球a", 100000)
+	got := NewSegmenter(dict).Cut("地球abc")
+	if want := []string{"地球", "abc"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Cut = %q, want %q", got, want)
+	}
+}
+
+func TestDictionaryLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		words   []string
+		wantErr bool
+	}{
+		{"default frequency", "流浪地球\n", []string{"流浪地球"}, false},
+		{"comments and blank lines", "# 注释\n\n高清 100\n", []string{"高清"}, false},
+		{"lowercased", "iPhone 100\n", []string{"iphone"}, false},
+		{"invalid frequency", "高清 abc\n", nil, true},
+		{"zero frequency", "高清 0\n", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dict := NewDictionary()
+			err := dict.Load(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, word := range tt.words {
+				if !dict.Contains(word) {
+					t.Errorf("dictionary should contain %q", word)
+				}
+			}
+		})
+	}
+}
+
+func TestDictionaryFingerprint(t *testing.T) {
+	a, b := testDictionary(t), testDictionary(t)
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Fatal("same words should have the same fingerprint")
+	}
+	b.AddWord("新词", 10)
+	if a.Fingerprint() == b.Fingerprint() {
+		t.Error("fingerprint should change after adding a word")
+	}
+	c := testDictionary(t)
+	c.AddWord("高清", 1)
+	if a.Fingerprint() == c.Fingerprint() {
+		t.Error("fingerprint should change after changing a frequency")
+	}
+}
+
+func TestBuiltinDictionary(t *testing.T) {
+	if BuiltinDictionary().Size() == 0 {
+		t.Fatal("builtin dictionary is empty")
+	}
+	// 查询和索引使用同一个词典，切分结果应当一致
+	if got := QueryTerms("流浪地球2 高清"); len(got) == 0 || got[len(got)-1] != "高清" {
+		t.Errorf("QueryTerms = %q", got)
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"pan-search-api/models"
+	"pan-search-api/search"
+	"pan-search-api/shareurl"
+
+	"gorm.io/gorm"
+)
+
+// 回填时每批处理的资源数
+const backfillBatchSize = 500
+
+// BackfillResources 重新计算全部资源（含已删除）的派生字段：标题和标签的拼音、文件大小的字节数、网盘分享去重键，返回处理的资源数
+func BackfillResources(db *gorm.DB) (int64, error) {
+	var total int64
+	var lastID string
+	for {
+		var resources []models.Resource
+		if err := db.Unscoped().Preload("Tags").
+			Select("id", "title", "size", "download_url").
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(backfillBatchSize).
+			Find(&resources).Error; err != nil {
+			return total, err
+		}
+
+		for _, resource := range resources {
+			if err := backfillResource(db, &resource); err != nil {
+				return total, err
+			}
+		}
+		total += int64(len(resources))
+
+		if len(resources) < backfillBatchSize {
+			return total, nil
+		}
+		lastID = resources[len(resources)-1].ID
+	}
+}
+
+// backfillResource 重新计算单个资源的派生字段
+func backfillResource(db *gorm.DB, resource *models.Resource) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		full, initials := search.Pinyin(resource.Title)
+		// UpdateColumns 不修改 updated_at
+		if err := tx.Unscoped().Model(&models.Resource{}).Where("id = ?", resource.ID).
+			UpdateColumns(map[string]interface{}{
+				"title_pinyin":   full,
+				"title_initials": initials,
+				"size_bytes":     ParseSizeBytes(resource.Size),
+				"share_key":      shareKey(resource.DownloadURL),
+			}).Error; err != nil {
+			return err
+		}
+
+		for _, tag := range resource.Tags {
+			full, initials := search.Pinyin(tag.TagName)
+			if err := tx.Model(&models.ResourceTag{}).Where("id = ?", tag.ID).
+				UpdateColumns(map[string]interface{}{
+					"pinyin":   full,
+					"initials": initials,
+				}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// shareKey 下载链接的分享去重键，无法识别时为空
+func shareKey(downloadURL string) string {
+	if share, ok := shareurl.Parse(downloadURL); ok {
+		return share.Key()
+	}
+	return ""
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+	"pan-search-api/models"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// CategoryAll 表示全部分类的伪分类值，不存储在数据库中
+const CategoryAll = "all"
+
+var (
+	// ErrCategoryCycle 父分类不能是自身或其子孙分类
+	ErrCategoryCycle = errors.New("父分类不能是自身或其子分类")
+	// ErrParentCategoryNotFound 父分类不存在
+	ErrParentCategoryNotFound = errors.New("父分类不存在")
+	// ErrCategoryInUse 分类下仍有子分类或资源
+	ErrCategoryInUse = errors.New("分类下仍有子分类或资源，不能删除")
+)
+
+// CategoryNode 分类树节点
+type CategoryNode struct {
+	models.Category
+	Children []*CategoryNode
+}
+
+// BuildCategoryTree 将分类列表组装为树，同级按SortOrder排序；父分类不在列表中的节点作为根节点
+func BuildCategoryTree(categories []models.Category) []*CategoryNode {
+	nodes := make(map[uint]*CategoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID] = &CategoryNode{Category: category}
+	}
+
+	var roots []*CategoryNode
+	for _, category := range categories {
+		node := nodes[category.ID]
+		if category.ParentID != nil {
+			if parent, ok := nodes[*category.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	sortCategoryNodes(roots)
+	return roots
+}
+
+// CategoryDescendantIDs 返回分类及其全部子孙分类的ID，分类不存在时返回空切片
+func CategoryDescendantIDs(db *gorm.DB, value string) ([]uint, error) {
+	var categories []models.Category
+	if err := db.Select("id", "value", "parent_id").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	var rootID uint
+	children := make(map[uint][]uint)
+	for _, category := range categories {
+		if category.Value == value {
+			rootID = category.ID
+		}
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+	if rootID == 0 {
+		return []uint{}, nil
+	}
+
+	ids := []uint{rootID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids, nil
+}
+
+// CheckCategoryParent 检查将分类id的父分类设为parentID是否会形成环
+func CheckCategoryParent(db *gorm.DB, id, parentID uint) error {
+	if err := ensureCategory(db, parentID); err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			return ErrParentCategoryNotFound
+		}
+		return err
+	}
+	if id == 0 {
+		return nil
+	}
+
+	var categories []models.Category
+	if err := db.Select("id", "parent_id").Find(&categories).Error; err != nil {
+		return err
+	}
+	parents := make(map[uint]*uint, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+
+	// 沿父链向上查找，遇到自身说明成环
+	for current, depth := parentID, 0; depth <= len(categories); depth++ {
+		if current == id {
+			return ErrCategoryCycle
+		}
+		parent := parents[current]
+		if parent == nil {
+			return nil
+		}
+		current = *parent
+	}
+	return ErrCategoryCycle
+}
+
+// DeleteCategory 删除没有子分类和资源的分类
+func DeleteCategory(db *gorm.DB, id uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureCategory(tx, id); err != nil {
+			return err
+		}
+
+		var children, resources int64
+		if err := tx.Model(&models.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Resource{}).Where("category_id = ?", id).Count(&resources).Error; err != nil {
+			return err
+		}
+		if children > 0 || resources > 0 {
+			return ErrCategoryInUse
+		}
+
+		return tx.Delete(&models.Category{}, id).Error
+	})
+}
+
+// sortCategoryNodes 递归按SortOrder、ID排序
+func sortCategoryNodes(nodes []*CategoryNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].SortOrder != nodes[j].SortOrder {
+			return nodes[i].SortOrder < nodes[j].SortOrder
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+	for _, node := range nodes {
+		sortCategoryNodes(node.Children)
+	}
+}
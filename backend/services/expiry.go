@@ -0,0 +1,135 @@
+package services
+
+import (
+	"log"
+	"pan-search-api/config"
+	"pan-search-api/models"
+	"pan-search-api/search"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 过期处理默认配置
+const (
+	defaultSweepInterval = 5 * time.Minute
+	// DefaultExpiringSoonWindow 即将过期列表默认的时间范围
+	DefaultExpiringSoonWindow = 72 * time.Hour
+)
+
+// 每批标记失效的资源数
+const sweepBatchSize = 500
+
+// InvalidReasonExpired 资源因过期被标记失效，修改过期时间使其不再过期时自动恢复有效
+const InvalidReasonExpired = "expired"
+
+// WhereVisible 只查询有效且未过期的资源。includeExpired为true时同时包含已过期的资源，
+// 不论是否已被标记失效
+func WhereVisible(db *gorm.DB, includeExpired bool) *gorm.DB {
+	now := time.Now()
+	if includeExpired {
+		return db.Where("resources.valid = ? OR resources.expire_time <= ?", true, now)
+	}
+	return db.Where("resources.valid = ? AND (resources.expire_time IS NULL OR resources.expire_time > ?)", true, now)
+}
+
+// ExpirySweeper 定时将已过期的资源标记为失效并从搜索索引中移除
+type ExpirySweeper struct {
+	db        *gorm.DB
+	interval  time.Duration
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// StartExpirySweeper 启动定时处理，启动后立即在后台处理一次
+func StartExpirySweeper(db *gorm.DB, cfg config.ExpiryConfig) *ExpirySweeper {
+	interval := cfg.SweepInterval
+	if interval <= 0 {
+		interval = defaultSweepInterval
+	}
+
+	s := &ExpirySweeper{db: db, interval: interval, done: make(chan struct{})}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.loop()
+	}()
+	return s
+}
+
+// Stop 停止定时处理，等待正在进行的处理完成后返回
+func (s *ExpirySweeper) Stop() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+	s.wg.Wait()
+}
+
+// loop 立即处理一次，之后按间隔处理
+func (s *ExpirySweeper) loop() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if count, err := SweepExpired(s.db); err != nil {
+			log.Printf("Failed to sweep expired resources: %v", err)
+		} else if count > 0 {
+			log.Printf("Marked %d expired resources invalid", count)
+		}
+		select {
+		case <-ticker.C:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// SweepExpired 将已过期的有效资源标记为失效，返回处理的资源数
+func SweepExpired(db *gorm.DB) (int, error) {
+	now := time.Now()
+	total := 0
+	for {
+		var candidates, ids []string
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&models.Resource{}).
+				Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("valid = ? AND expire_time <= ?", true, now).
+				Order("expire_time ASC").
+				Limit(sweepBatchSize).
+				Pluck("id", &candidates).Error; err != nil {
+				return err
+			}
+			if len(candidates) == 0 {
+				return nil
+			}
+
+			// 再次检查过期条件，查询之后被修改过期时间或有效状态的资源不标记失效
+			if err := tx.Model(&models.Resource{}).
+				Where("id IN ? AND valid = ? AND expire_time <= ?", candidates, true, now).
+				Updates(map[string]interface{}{"valid": false, "invalid_reason": InvalidReasonExpired}).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.Resource{}).
+				Where("id IN ? AND valid = ? AND invalid_reason = ?", candidates, false, InvalidReasonExpired).
+				Pluck("id", &ids).Error
+		})
+		if err != nil {
+			return total, err
+		}
+
+		// 只从索引移除实际标记失效的资源
+		for _, id := range ids {
+			if err := search.Default().Remove(id); err != nil {
+				log.Printf("Failed to remove resource %s from search index: %v", id, err)
+			}
+		}
+
+		total += len(ids)
+		if len(candidates) < sweepBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"errors"
+	"pan-search-api/models"
+	"pan-search-api/search"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// recordingEngine 记录索引写入和移除的搜索引擎
+type recordingEngine struct {
+	mu      sync.Mutex
+	indexed []string
+	removed []string
+}
+
+func (e *recordingEngine) Name() string { return "recording" }
+
+func (e *recordingEngine) Search(query string, limit int) ([]search.Hit, error) { return nil, nil }
+
+func (e *recordingEngine) Index(doc search.Document) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.indexed = append(e.indexed, doc.ID)
+	return nil
+}
+
+func (e *recordingEngine) Remove(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.removed = append(e.removed, id)
+	return nil
+}
+
+func (e *recordingEngine) Close() error { return nil }
+
+func useRecordingEngine(t *testing.T) *recordingEngine {
+	t.Helper()
+	engine := &recordingEngine{}
+	old := search.Default()
+	search.SetDefault(engine)
+	t.Cleanup(func() { search.SetDefault(old) })
+	return engine
+}
+
+// createExpiryResources 创建各种过期和有效状态的资源
+func createExpiryResources(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	createTestResource(t, db, "active", nil)
+	createTestResource(t, db, "future", &future)
+	createTestResource(t, db, "expired", &past)
+	createTestResource(t, db, "swept", &past)
+	createTestResource(t, db, "dead", &past)
+	createTestResource(t, db, "deadNoExpiry", nil)
+
+	invalid := map[string]string{"swept": InvalidReasonExpired, "dead": "", "deadNoExpiry": ""}
+	for id, reason := range invalid {
+		if err := db.Model(&models.Resource{}).Where("id = ?", id).
+			Updates(map[string]interface{}{"valid": false, "invalid_reason": reason}).Error; err != nil {
+			t.Fatalf("mark %s invalid: %v", id, err)
+		}
+	}
+}
+
+func TestWhereVisible(t *testing.T) {
+	db := testDB(t)
+	createExpiryResources(t, db)
+
+	tests := []struct {
+		name           string
+		includeExpired bool
+		want           []string
+	}{
+		{"visible only", false, []string{"active", "future"}},
+		// 已过期的资源不论是否已被标记失效都包含，其他原因失效的不包含
+		{"include expired", true, []string{"active", "dead", "expired", "future", "swept"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []string
+			if err := WhereVisible(db.Model(&models.Resource{}), tt.includeExpired).
+				Order("id").Pluck("id", &ids).Error; err != nil {
+				t.Fatalf("query error = %v", err)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("WhereVisible(%v) = %v, want %v", tt.includeExpired, ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestSweepExpired(t *testing.T) {
+	db := testDB(t)
+	engine := useRecordingEngine(t)
+	createExpiryResources(t, db)
+
+	count, err := SweepExpired(db)
+	if err != nil || count != 1 {
+		t.Fatalf("SweepExpired() = %d, %v, want 1, nil", count, err)
+	}
+	if !reflect.DeepEqual(engine.removed, []string{"expired"}) {
+		t.Errorf("removed from index = %v, want [expired]", engine.removed)
+	}
+
+	tests := []struct {
+		id         string
+		wantValid  bool
+		wantReason string
+	}{
+		{"active", true, ""},
+		{"future", true, ""},
+		{"expired", false, InvalidReasonExpired},
+		{"swept", false, InvalidReasonExpired},
+		// 其他原因失效的资源不记录为过期
+		{"dead", false, ""},
+	}
+	for _, tt := range tests {
+		if valid, reason := resourceValid(t, db, tt.id); valid != tt.wantValid || reason != tt.wantReason {
+			t.Errorf("%s: valid = %v, reason = %q, want %v, %q", tt.id, valid, reason, tt.wantValid, tt.wantReason)
+		}
+	}
+
+	if count, err := SweepExpired(db); err != nil || count != 0 {
+		t.Errorf("second SweepExpired() = %d, %v, want 0, nil", count, err)
+	}
+}
+
+func TestSweepExpiredExtendedConcurrently(t *testing.T) {
+	db := testDB(t)
+	engine := useRecordingEngine(t)
+	past := time.Now().Add(-time.Hour)
+	createTestResource(t, db, "a", &past)
+	createTestResource(t, db, "b", &past)
+
+	// 查询候选之后、标记失效之前，管理员延长了b的过期时间
+	extend := func(tx *gorm.DB) {
+		if tx.Statement.Table == "resources" {
+			tx.Session(&gorm.Session{NewDB: true}).Exec(
+				"UPDATE resources SET expire_time = ? WHERE id = ?", time.Now().Add(time.Hour), "b")
+		}
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("test:extend", extend); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := SweepExpired(db)
+	if err != nil || count != 1 {
+		t.Fatalf("SweepExpired() = %d, %v, want 1, nil", count, err)
+	}
+	if valid, reason := resourceValid(t, db, "b"); !valid || reason != "" {
+		t.Errorf("extended resource: valid = %v, reason = %q, want true, \"\"", valid, reason)
+	}
+	if !reflect.DeepEqual(engine.removed, []string{"a"}) {
+		t.Errorf("removed from index = %v, want [a]", engine.removed)
+	}
+}
+
+func TestUpdateResourceExtendsExpired(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour)
+	valid := true
+
+	tests := []struct {
+		name       string
+		id         string
+		input      models.ResourceUpdate
+		wantErr    error
+		wantValid  bool
+		wantReason string
+	}{
+		{"restore expired", "swept", models.ResourceUpdate{ExpireTime: &future}, nil, true, ""},
+		{"restore on clear", "swept", models.ResourceUpdate{ClearExpireTime: true}, nil, true, ""},
+		// 其他原因失效的资源需要明确传入valid
+		{"other reason", "dead", models.ResourceUpdate{ExpireTime: &future}, ErrResourceInvalid, false, ""},
+		{"other reason with valid", "dead", models.ResourceUpdate{ExpireTime: &future, Valid: &valid}, nil, true, ""},
+		{"not expired", "deadNoExpiry", models.ResourceUpdate{ExpireTime: &future}, nil, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			engine := useRecordingEngine(t)
+			createExpiryResources(t, db)
+
+			_, err := UpdateResource(db, tt.id, tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateResource(%s) error = %v, want %v", tt.id, err, tt.wantErr)
+			}
+			valid, reason := resourceValid(t, db, tt.id)
+			if valid != tt.wantValid || reason != tt.wantReason {
+				t.Errorf("valid = %v, reason = %q, want %v, %q", valid, reason, tt.wantValid, tt.wantReason)
+			}
+			// 恢复有效的资源重新加入索引
+			var wantIndexed []string
+			if tt.wantErr == nil && tt.wantValid {
+				wantIndexed = []string{tt.id}
+			}
+			if !reflect.DeepEqual(engine.indexed, wantIndexed) {
+				t.Errorf("indexed = %v, want %v", engine.indexed, wantIndexed)
+			}
+		})
+	}
+}
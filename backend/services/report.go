@@ -0,0 +1,210 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"pan-search-api/common"
+	"pan-search-api/config"
+	"pan-search-api/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 举报原因
+const (
+	ReportDeadLink      = "dead_link"
+	ReportWrongCode     = "wrong_code"
+	ReportWrongCategory = "wrong_category"
+	ReportIllegal       = "illegal"
+	ReportOther         = "other"
+)
+
+// ReportReasons 支持的举报原因，值表示是否计入自动隐藏
+var ReportReasons = map[string]bool{
+	ReportDeadLink:      true,
+	ReportWrongCode:     true,
+	ReportWrongCategory: false,
+	ReportIllegal:       true,
+	ReportOther:         false,
+}
+
+// 举报状态
+const (
+	ReportPending   = "pending"
+	ReportResolved  = "resolved"
+	ReportDismissed = "dismissed"
+)
+
+// 举报默认配置
+const (
+	defaultReportRateLimit  = 10
+	defaultReportRateWindow = time.Hour
+)
+
+// ReporterKey 区分举报人的SQL表达式，登录用户按用户ID，匿名用户按IP
+const ReporterKey = "CASE WHEN user_id <> '' THEN user_id ELSE ip_address END"
+
+var (
+	// ErrInvalidReportReason 不支持的举报原因
+	ErrInvalidReportReason = errors.New("举报原因只能为dead_link/wrong_code/wrong_category/illegal/other")
+	// ErrReportRateLimited 举报次数超过限制
+	ErrReportRateLimited = errors.New("举报过于频繁，请稍后再试")
+	// ErrDuplicateReport 同一资源已有该用户待处理的举报
+	ErrDuplicateReport = errors.New("已经举报过该资源，请等待处理")
+	// ErrNoPendingReports 资源没有待处理的举报
+	ErrNoPendingReports = errors.New("该资源没有待处理的举报")
+)
+
+// Reporter 举报人，未登录时UserID为空
+type Reporter struct {
+	UserID string
+	IP     string
+}
+
+// CreateReport 提交举报，同一用户或IP在时间窗口内的举报数受限，
+// 不同登录用户的有效举报达到阈值时自动隐藏资源
+func CreateReport(db *gorm.DB, cfg config.ReportConfig, resourceID string, reporter Reporter, input models.ReportCreate) (*models.ResourceReport, error) {
+	if _, ok := ReportReasons[input.Reason]; !ok {
+		return nil, ErrInvalidReportReason
+	}
+	if cfg.RateLimit <= 0 {
+		cfg.RateLimit = defaultReportRateLimit
+	}
+	if cfg.RateWindow <= 0 {
+		cfg.RateWindow = defaultReportRateWindow
+	}
+
+	var (
+		report    *models.ResourceReport
+		reporters int64
+	)
+	// 检查和写入在同一事务中，锁定资源行使同一资源的举报依次处理，
+	// 登录用户同时锁定用户行，避免并发请求同时通过频率和重复检查
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var resource models.Resource
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "valid").First(&resource, "id = ?", resourceID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrResourceNotFound
+			}
+			return err
+		}
+		if reporter.UserID != "" {
+			var userIDs []string
+			if err := tx.Model(&models.User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", reporter.UserID).Pluck("id", &userIDs).Error; err != nil {
+				return err
+			}
+		}
+
+		// 登录用户同时按用户和IP限制，避免切换账号或退出登录绕过
+		var recent int64
+		query := tx.Model(&models.ResourceReport{}).Where("created_at >= ?", time.Now().Add(-cfg.RateWindow))
+		if reporter.UserID != "" {
+			query = query.Where("ip_address = ? OR user_id = ?", reporter.IP, reporter.UserID)
+		} else {
+			query = query.Where("ip_address = ?", reporter.IP)
+		}
+		if err := query.Count(&recent).Error; err != nil {
+			return err
+		}
+		if recent >= int64(cfg.RateLimit) {
+			return ErrReportRateLimited
+		}
+
+		var duplicates int64
+		query = tx.Model(&models.ResourceReport{}).Where("resource_id = ? AND status = ?", resourceID, ReportPending)
+		if reporter.UserID != "" {
+			query = query.Where("user_id = ?", reporter.UserID)
+		} else {
+			query = query.Where("user_id = '' AND ip_address = ?", reporter.IP)
+		}
+		if err := query.Count(&duplicates).Error; err != nil {
+			return err
+		}
+		if duplicates > 0 {
+			return ErrDuplicateReport
+		}
+
+		report = &models.ResourceReport{
+			ID:          common.GenerateID(),
+			ResourceID:  resourceID,
+			Reason:      input.Reason,
+			Description: strings.TrimSpace(input.Description),
+			UserID:      reporter.UserID,
+			IPAddress:   reporter.IP,
+			Status:      ReportPending,
+			CreatedAt:   time.Now(),
+		}
+		if err := tx.Create(report).Error; err != nil {
+			return err
+		}
+
+		// 匿名举报的IP可以伪造或轮换，只有登录用户的举报计入自动隐藏
+		if !resource.Valid || reporter.UserID == "" || !ReportReasons[input.Reason] || cfg.AutoHideThreshold <= 0 {
+			return nil
+		}
+		var err error
+		reporters, err = countAutoHideReporters(tx, resourceID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.AutoHideThreshold > 0 && reporters >= int64(cfg.AutoHideThreshold) {
+		log.Printf("Resource %s hidden after reports from %d users", resourceID, reporters)
+		if _, err := SetResourceValid(db, resourceID, false); err != nil {
+			log.Printf("Failed to auto-hide reported resource %s: %v", resourceID, err)
+		}
+	}
+	return report, nil
+}
+
+// countAutoHideReporters 统计对资源提交待处理有效举报的登录用户数
+func countAutoHideReporters(db *gorm.DB, resourceID string) (int64, error) {
+	var reasons []string
+	for reason, counted := range ReportReasons {
+		if counted {
+			reasons = append(reasons, reason)
+		}
+	}
+
+	var reporters int64
+	if err := db.Model(&models.ResourceReport{}).
+		Select("COUNT(DISTINCT user_id)").
+		Where("resource_id = ? AND status = ? AND reason IN ? AND user_id <> ''", resourceID, ReportPending, reasons).
+		Scan(&reporters).Error; err != nil {
+		return 0, err
+	}
+	return reporters, nil
+}
+
+// ResolveReports 处理资源全部待处理的举报，传入valid时同时修改资源有效状态，返回处理的举报数
+func ResolveReports(db *gorm.DB, resourceID, adminID string, input models.ReportResolve) (int64, error) {
+	now := time.Now()
+	result := db.Model(&models.ResourceReport{}).
+		Where("resource_id = ? AND status = ?", resourceID, ReportPending).
+		Updates(map[string]interface{}{
+			"status":      input.Status,
+			"admin_notes": strings.TrimSpace(input.AdminNotes),
+			"resolved_by": adminID,
+			"resolved_at": now,
+		})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, ErrNoPendingReports
+	}
+
+	if input.Valid != nil {
+		if _, err := SetResourceValid(db, resourceID, *input.Valid); err != nil {
+			return result.RowsAffected, err
+		}
+	}
+	return result.RowsAffected, nil
+}
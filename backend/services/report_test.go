@@ -0,0 +1,265 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"pan-search-api/config"
+	"pan-search-api/models"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDB 每个测试使用独立的内存SQLite数据库
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Category{}, &models.Resource{}, &models.ResourceTag{}, &models.ResourceReport{}); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	// users表的ENUM列SQLite不支持，只建举报加锁用到的列
+	if err := db.Exec("CREATE TABLE users (id VARCHAR(32) PRIMARY KEY)").Error; err != nil {
+		t.Fatalf("create users table: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// createTestResource 创建有效资源，expireTime为nil时不过期
+func createTestResource(t *testing.T, db *gorm.DB, id string, expireTime *time.Time) {
+	t.Helper()
+	resource := models.Resource{
+		ID:          id,
+		Title:       "资源" + id,
+		Size:        "1GB",
+		Type:        "video",
+		CategoryID:  1,
+		Source:      "test",
+		DownloadURL: "https://pan.baidu.com/s/1" + id,
+		Valid:       true,
+		ExpireTime:  expireTime,
+		UploadTime:  time.Now(),
+	}
+	if err := db.Create(&resource).Error; err != nil {
+		t.Fatalf("create resource %s: %v", id, err)
+	}
+}
+
+// resourceValid 读取资源的有效状态和失效原因
+func resourceValid(t *testing.T, db *gorm.DB, id string) (bool, string) {
+	t.Helper()
+	var resource models.Resource
+	if err := db.Unscoped().First(&resource, "id = ?", id).Error; err != nil {
+		t.Fatalf("load resource %s: %v", id, err)
+	}
+	return resource.Valid, resource.InvalidReason
+}
+
+func TestCreateReportValidation(t *testing.T) {
+	db := testDB(t)
+	createTestResource(t, db, "r1", nil)
+	reporter := Reporter{IP: "10.0.0.1"}
+
+	tests := []struct {
+		name       string
+		resourceID string
+		reason     string
+		wantErr    error
+	}{
+		{"dead link", "r1", ReportDeadLink, nil},
+		{"unknown reason", "r1", "spam", ErrInvalidReportReason},
+		{"empty reason", "r1", "", ErrInvalidReportReason},
+		{"unknown resource", "missing", ReportDeadLink, ErrResourceNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateReport(db, config.ReportConfig{}, tt.resourceID, reporter, models.ReportCreate{Reason: tt.reason})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateReport(%q, %q) error = %v, want %v", tt.resourceID, tt.reason, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateReportRateLimit(t *testing.T) {
+	db := testDB(t)
+	for _, id := range []string{"r1", "r2", "r3", "r4"} {
+		createTestResource(t, db, id, nil)
+	}
+	// 时间窗口之前的举报不计入
+	old := models.ResourceReport{ID: "old", ResourceID: "r4", Reason: ReportOther, IPAddress: "10.0.0.1",
+		Status: ReportResolved, CreatedAt: time.Now().Add(-2 * time.Hour)}
+	if err := db.Create(&old).Error; err != nil {
+		t.Fatalf("create old report: %v", err)
+	}
+	cfg := config.ReportConfig{RateLimit: 2, RateWindow: time.Hour}
+
+	tests := []struct {
+		name       string
+		resourceID string
+		reporter   Reporter
+		wantErr    error
+	}{
+		{"first", "r1", Reporter{IP: "10.0.0.1"}, nil},
+		{"second", "r2", Reporter{IP: "10.0.0.1"}, nil},
+		{"over limit", "r3", Reporter{IP: "10.0.0.1"}, ErrReportRateLimited},
+		// 登录后同一IP仍然受限
+		{"login on same ip", "r3", Reporter{UserID: "u1", IP: "10.0.0.1"}, ErrReportRateLimited},
+		{"other ip", "r3", Reporter{UserID: "u1", IP: "10.0.0.2"}, nil},
+		{"user second", "r4", Reporter{UserID: "u1", IP: "10.0.0.3"}, nil},
+		// 切换IP后同一用户仍然受限
+		{"user over limit", "r1", Reporter{UserID: "u1", IP: "10.0.0.4"}, ErrReportRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateReport(db, cfg, tt.resourceID, tt.reporter, models.ReportCreate{Reason: ReportDeadLink})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateReport(%q, %+v) error = %v, want %v", tt.resourceID, tt.reporter, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCreateReportDuplicate(t *testing.T) {
+	db := testDB(t)
+	createTestResource(t, db, "r1", nil)
+
+	tests := []struct {
+		name     string
+		reporter Reporter
+		wantErr  error
+	}{
+		{"anonymous", Reporter{IP: "10.0.0.1"}, nil},
+		{"same ip", Reporter{IP: "10.0.0.1"}, ErrDuplicateReport},
+		// 登录用户按用户ID判断重复，与同一IP的匿名举报无关
+		{"user on same ip", Reporter{UserID: "u1", IP: "10.0.0.1"}, nil},
+		{"same user other ip", Reporter{UserID: "u1", IP: "10.0.0.2"}, ErrDuplicateReport},
+		{"other user", Reporter{UserID: "u2", IP: "10.0.0.2"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateReport(db, config.ReportConfig{}, "r1", tt.reporter, models.ReportCreate{Reason: ReportWrongCode})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateReport(%+v) error = %v, want %v", tt.reporter, err, tt.wantErr)
+			}
+		})
+	}
+
+	// 举报处理后可以再次举报
+	if _, err := ResolveReports(db, "r1", "admin", models.ReportResolve{Status: ReportDismissed}); err != nil {
+		t.Fatalf("ResolveReports() error = %v", err)
+	}
+	if _, err := CreateReport(db, config.ReportConfig{}, "r1", Reporter{IP: "10.0.0.1"}, models.ReportCreate{Reason: ReportWrongCode}); err != nil {
+		t.Errorf("CreateReport() after resolve error = %v", err)
+	}
+}
+
+func TestCreateReportAutoHide(t *testing.T) {
+	cfg := config.ReportConfig{AutoHideThreshold: 2}
+
+	tests := []struct {
+		name      string
+		reports   []Reporter
+		reasons   []string
+		wantValid bool
+	}{
+		{
+			name:      "distinct users",
+			reports:   []Reporter{{UserID: "u1", IP: "10.0.0.1"}, {UserID: "u2", IP: "10.0.0.1"}},
+			reasons:   []string{ReportDeadLink, ReportIllegal},
+			wantValid: false,
+		},
+		{
+			name:      "below threshold",
+			reports:   []Reporter{{UserID: "u1", IP: "10.0.0.1"}},
+			reasons:   []string{ReportDeadLink},
+			wantValid: true,
+		},
+		{
+			// 匿名举报的IP可以伪造，不计入
+			name:      "anonymous ips",
+			reports:   []Reporter{{IP: "10.0.0.1"}, {IP: "10.0.0.2"}, {IP: "10.0.0.3"}, {UserID: "u1", IP: "10.0.0.4"}},
+			reasons:   []string{ReportDeadLink, ReportDeadLink, ReportDeadLink, ReportDeadLink},
+			wantValid: true,
+		},
+		{
+			// 分类错误等原因不计入
+			name:      "uncounted reason",
+			reports:   []Reporter{{UserID: "u1", IP: "10.0.0.1"}, {UserID: "u2", IP: "10.0.0.2"}},
+			reasons:   []string{ReportDeadLink, ReportWrongCategory},
+			wantValid: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			createTestResource(t, db, "r1", nil)
+			for i, reporter := range tt.reports {
+				input := models.ReportCreate{Reason: tt.reasons[i]}
+				if _, err := CreateReport(db, cfg, "r1", reporter, input); err != nil {
+					t.Fatalf("CreateReport(%+v) error = %v", reporter, err)
+				}
+			}
+			if valid, _ := resourceValid(t, db, "r1"); valid != tt.wantValid {
+				t.Errorf("resource valid = %v, want %v", valid, tt.wantValid)
+			}
+		})
+	}
+}
+
+func TestResolveReports(t *testing.T) {
+	valid, invalid := true, false
+
+	tests := []struct {
+		name      string
+		reports   int
+		input     models.ReportResolve
+		want      int64
+		wantErr   error
+		wantValid bool
+	}{
+		{"keep validity", 2, models.ReportResolve{Status: ReportDismissed}, 2, nil, true},
+		{"mark invalid", 1, models.ReportResolve{Status: ReportResolved, Valid: &invalid}, 1, nil, false},
+		{"mark valid", 1, models.ReportResolve{Status: ReportResolved, Valid: &valid}, 1, nil, true},
+		{"no pending reports", 0, models.ReportResolve{Status: ReportResolved, Valid: &invalid}, 0, ErrNoPendingReports, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			createTestResource(t, db, "r1", nil)
+			for i := 0; i < tt.reports; i++ {
+				reporter := Reporter{IP: fmt.Sprintf("10.0.0.%d", i+1)}
+				if _, err := CreateReport(db, config.ReportConfig{}, "r1", reporter, models.ReportCreate{Reason: ReportDeadLink}); err != nil {
+					t.Fatalf("CreateReport() error = %v", err)
+				}
+			}
+
+			got, err := ResolveReports(db, "r1", "admin", tt.input)
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("ResolveReports() = %d, %v, want %d, %v", got, err, tt.want, tt.wantErr)
+			}
+			if valid, _ := resourceValid(t, db, "r1"); valid != tt.wantValid {
+				t.Errorf("resource valid = %v, want %v", valid, tt.wantValid)
+			}
+
+			var pending int64
+			db.Model(&models.ResourceReport{}).Where("status = ?", ReportPending).Count(&pending)
+			if pending != 0 {
+				t.Errorf("pending reports = %d, want 0", pending)
+			}
+		})
+	}
+}
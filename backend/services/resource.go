@@ -0,0 +1,331 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"pan-search-api/common"
+	"pan-search-api/models"
+	"pan-search-api/search"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 单个标签最大长度
+const maxTagLength = 50
+
+var (
+	// ErrResourceNotFound 资源不存在
+	ErrResourceNotFound = errors.New("资源不存在")
+	// ErrCategoryNotFound 分类不存在
+	ErrCategoryNotFound = errors.New("分类不存在")
+	// ErrResourceInvalid 资源因过期以外的原因被标记失效，修改过期时间不会自动恢复
+	ErrResourceInvalid = errors.New("资源已被标记失效且不是因过期失效，修改过期时间时请同时传入valid")
+)
+
+// CreateResource 创建资源及其标签
+func CreateResource(db *gorm.DB, input models.ResourceCreate) (*models.Resource, error) {
+	input.DownloadURL = strings.TrimSpace(input.DownloadURL)
+	shareKey := NormalizeShare(&input)
+	if strings.TrimSpace(input.Source) == "" {
+		return nil, ErrSourceRequired
+	}
+
+	now := time.Now()
+	resource := models.Resource{
+		ID:          common.GenerateID(),
+		Title:       strings.TrimSpace(input.Title),
+		Description: strings.TrimSpace(input.Description),
+		Size:        strings.TrimSpace(input.Size),
+		Type:        strings.TrimSpace(input.Type),
+		CategoryID:  input.CategoryID,
+		Source:      strings.TrimSpace(input.Source),
+		DownloadURL: input.DownloadURL,
+		ShareKey:    shareKey,
+		ExtractCode: strings.TrimSpace(input.ExtractCode),
+		FileCount:   input.FileCount,
+		Valid:       true,
+		ExpireTime:  input.ExpireTime,
+		UploadTime:  now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if resource.FileCount == 0 {
+		resource.FileCount = 1
+	}
+	if input.Valid != nil {
+		resource.Valid = *input.Valid
+	}
+	if input.UploadTime != nil {
+		resource.UploadTime = *input.UploadTime
+	}
+	resource.TitlePinyin, resource.TitleInitials = search.Pinyin(resource.Title)
+	resource.SizeBytes = ParseSizeBytes(resource.Size)
+	tags := NormalizeTags(input.Tags)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureCategory(tx, resource.CategoryID); err != nil {
+			return err
+		}
+		// Select("*") 保证 valid=false 等零值也会写入，而不是使用列默认值
+		if err := tx.Select("*").Omit(clause.Associations).Create(&resource).Error; err != nil {
+			return err
+		}
+		return replaceTags(tx, resource.ID, tags)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return syncResource(db, resource.ID)
+}
+
+// UpdateResource 更新资源，传入标签时在同一事务中整体替换
+func UpdateResource(db *gorm.DB, id string, input models.ResourceUpdate) (*models.Resource, error) {
+	updates := map[string]interface{}{}
+	setString := func(column string, value *string) {
+		if value != nil {
+			updates[column] = strings.TrimSpace(*value)
+		}
+	}
+	setString("title", input.Title)
+	if input.Title != nil {
+		updates["title_pinyin"], updates["title_initials"] = search.Pinyin(strings.TrimSpace(*input.Title))
+	}
+	setString("description", input.Description)
+	setString("size", input.Size)
+	if input.Size != nil {
+		updates["size_bytes"] = ParseSizeBytes(*input.Size)
+	}
+	setString("type", input.Type)
+	setString("source", input.Source)
+	setString("extract_code", input.ExtractCode)
+	if input.DownloadURL != nil {
+		// 可识别的网盘链接替换为规范链接，同时更新来源，未传提取码时使用链接中的提取码
+		share := models.ResourceCreate{DownloadURL: strings.TrimSpace(*input.DownloadURL)}
+		updates["share_key"] = NormalizeShare(&share)
+		updates["download_url"] = share.DownloadURL
+		if share.Source != "" {
+			updates["source"] = share.Source
+		}
+		if input.ExtractCode == nil && share.ExtractCode != "" {
+			updates["extract_code"] = share.ExtractCode
+		}
+	}
+	if input.DownloadURL != nil || input.ExtractCode != nil {
+		// 链接变更后之前的检测结果不再适用，尽快重新检测
+		updates["link_status"] = ""
+		updates["check_failures"] = 0
+		updates["next_check_at"] = nil
+	}
+	if input.CategoryID != nil {
+		updates["category_id"] = *input.CategoryID
+	}
+	if input.FileCount != nil {
+		updates["file_count"] = *input.FileCount
+	}
+	if input.Valid != nil {
+		updates["valid"] = *input.Valid
+		updates["invalid_reason"] = ""
+	}
+	if input.ExpireTime != nil {
+		updates["expire_time"] = *input.ExpireTime
+	}
+	if input.ClearExpireTime {
+		updates["expire_time"] = nil
+	}
+	if input.UploadTime != nil {
+		updates["upload_time"] = *input.UploadTime
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var resource models.Resource
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&resource, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrResourceNotFound
+			}
+			return err
+		}
+
+		if input.CategoryID != nil {
+			if err := ensureCategory(tx, *input.CategoryID); err != nil {
+				return err
+			}
+		}
+		// 已过期并被标记失效的资源改为不过期：仅因过期失效的恢复有效并重新加入索引，
+		// 其他原因失效的需要同时明确传入valid
+		extended := input.ClearExpireTime || input.ExpireTime != nil && input.ExpireTime.After(time.Now())
+		expired := resource.ExpireTime != nil && !resource.ExpireTime.After(time.Now())
+		if extended && expired && !resource.Valid && input.Valid == nil {
+			if resource.InvalidReason != InvalidReasonExpired {
+				return ErrResourceInvalid
+			}
+			for column, value := range validityUpdates(true) {
+				updates[column] = value
+			}
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(&resource).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if input.Tags != nil {
+			return replaceTags(tx, id, NormalizeTags(*input.Tags))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return syncResource(db, id)
+}
+
+// SetResourceValid 修改资源有效状态，搜索和热门推荐直接读取该字段，修改后立即生效。
+// 恢复有效时清零链接检测的连续失效次数
+func SetResourceValid(db *gorm.DB, id string, valid bool) (*models.Resource, error) {
+	if err := db.Model(&models.Resource{}).Where("id = ?", id).Updates(validityUpdates(valid)).Error; err != nil {
+		return nil, err
+	}
+
+	// 状态未变化时RowsAffected同样为0，通过重新查询判断资源是否存在
+	return syncResource(db, id)
+}
+
+// validityUpdates 修改有效状态时需要更新的列，手动修改后不再记录失效原因
+func validityUpdates(valid bool) map[string]interface{} {
+	updates := map[string]interface{}{"valid": valid, "invalid_reason": ""}
+	if valid {
+		updates["check_failures"] = 0
+	}
+	return updates
+}
+
+// DeleteResource 软删除资源
+func DeleteResource(db *gorm.DB, id string) error {
+	result := db.Delete(&models.Resource{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrResourceNotFound
+	}
+
+	if err := search.Default().Remove(id); err != nil {
+		log.Printf("Failed to remove resource %s from search index: %v", id, err)
+	}
+	return nil
+}
+
+// RestoreResource 恢复已软删除的资源
+func RestoreResource(db *gorm.DB, id string) (*models.Resource, error) {
+	result := db.Unscoped().Model(&models.Resource{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrResourceNotFound
+	}
+
+	return syncResource(db, id)
+}
+
+// GetResource 查询资源及其分类、标签，withDeleted为true时包含已删除的资源
+func GetResource(db *gorm.DB, id string, withDeleted bool) (*models.Resource, error) {
+	query := db.Preload("Category").Preload("Tags")
+	if withDeleted {
+		query = query.Unscoped()
+	}
+
+	var resource models.Resource
+	if err := query.First(&resource, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrResourceNotFound
+		}
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// ParseSizeBytes 解析文件大小的字节数，无法解析时返回nil
+func ParseSizeBytes(size string) *int64 {
+	bytes, err := common.ParseSize(size)
+	if err != nil {
+		return nil
+	}
+	return &bytes
+}
+
+// syncResource 重新查询资源并同步到搜索索引，索引同步失败只记录日志，不影响数据库中已完成的修改
+func syncResource(db *gorm.DB, id string) (*models.Resource, error) {
+	resource, err := GetResource(db, id, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := search.SyncResource(resource); err != nil {
+		log.Printf("Failed to sync resource %s to search index: %v", id, err)
+	}
+	return resource, nil
+}
+
+// NormalizeTags 去除空白、过长和重复的标签
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]struct{}, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			continue
+		}
+		key := strings.ToLower(tag)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, tag)
+	}
+	return result
+}
+
+// ensureCategory 检查分类是否存在
+func ensureCategory(tx *gorm.DB, categoryID uint) error {
+	var count int64
+	if err := tx.Model(&models.Category{}).Where("id = ?", categoryID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+// replaceTags 替换资源的全部标签
+func replaceTags(tx *gorm.DB, resourceID string, tags []string) error {
+	if err := tx.Where("resource_id = ?", resourceID).Delete(&models.ResourceTag{}).Error; err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	rows := make([]models.ResourceTag, len(tags))
+	for i, tag := range tags {
+		full, initials := search.Pinyin(tag)
+		rows[i] = models.ResourceTag{
+			ResourceID: resourceID,
+			TagName:    tag,
+			Pinyin:     full,
+			Initials:   initials,
+			CreatedAt:  now,
+		}
+	}
+	return tx.Create(&rows).Error
+}
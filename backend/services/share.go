@@ -0,0 +1,43 @@
+package services
+
+import (
+	"errors"
+	"pan-search-api/models"
+	"pan-search-api/shareurl"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrSourceRequired 无法从链接识别网盘且未填写来源
+var ErrSourceRequired = errors.New("无法识别下载链接的网盘，请填写来源")
+
+// NormalizeShare 识别下载链接中的网盘分享，将链接替换为规范链接，来源设为网盘名，
+// 未填写提取码时使用链接或文本中的提取码。返回分享去重键，无法识别时返回空且不修改input
+func NormalizeShare(input *models.ResourceCreate) string {
+	share, ok := shareurl.Parse(input.DownloadURL)
+	if !ok {
+		return ""
+	}
+	input.DownloadURL = share.URL
+	input.Source = share.Provider
+	if strings.TrimSpace(input.ExtractCode) == "" {
+		input.ExtractCode = share.ExtractCode
+	}
+	return share.Key()
+}
+
+// ShareExists 检查网盘分享是否已收录，包含已删除的资源。
+// 无法识别的链接按原始下载链接比较
+func ShareExists(db *gorm.DB, shareKey, downloadURL string) (bool, error) {
+	query := db.Unscoped().Model(&models.Resource{})
+	if shareKey != "" {
+		query = query.Where("share_key = ?", shareKey)
+	} else {
+		query = query.Where("download_url = ?", downloadURL)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,472 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"math"
+	"pan-search-api/config"
+	"pan-search-api/models"
+	"pan-search-api/search"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 热门趋势默认配置
+const (
+	defaultTrendingInterval = 10 * time.Minute
+	defaultTopKeywords      = 200
+	defaultHitsPerKeyword   = 3
+)
+
+// 查询资源分类时每批的ID数量
+const trendingBatchSize = 1000
+
+// 一次下载计入的热度，下载比搜索更能反映资源本身的热度
+const (
+	downloadWeight = 3
+	searchWeight   = 1
+)
+
+// 趋势方向
+const (
+	TrendUp     = "up"
+	TrendDown   = "down"
+	TrendStable = "stable"
+	TrendNew    = "new" // 上一窗口没有热度
+)
+
+// TrendingWindows 支持的时间窗口，当前窗口与紧邻的上一个同长度窗口比较
+var TrendingWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+// DefaultTrendingWindow 默认时间窗口
+const DefaultTrendingWindow = "24h"
+
+// ErrInvalidTrendingWindow 不支持的时间窗口
+var ErrInvalidTrendingWindow = errors.New("window只能为24h或7d")
+
+// windowCounts 资源在一个时间窗口内的搜索和下载次数
+type windowCounts struct {
+	Searches  int64
+	Downloads int64
+}
+
+// score 窗口内的热度
+func (c windowCounts) score() int64 {
+	return c.Searches*searchWeight + c.Downloads*downloadWeight
+}
+
+// trendEntry 资源在当前窗口和上一窗口的热度，两个窗口分别统计
+type trendEntry struct {
+	ResourceID string
+	CategoryID uint
+	Current    windowCounts
+	Previous   windowCounts
+}
+
+// TrendItem 热门趋势中的一项，Rank和PrevRank从1开始，PrevRank为0表示上一窗口未上榜
+type TrendItem struct {
+	ResourceID   string
+	Rank         int
+	PrevRank     int
+	RankChange   int      // 排名上升为正
+	Trend        string   // up/down/stable/new
+	Searches     int64    // 当前窗口内命中该资源的搜索次数
+	Downloads    int64    // 当前窗口内的下载次数
+	Score        int64    // 当前窗口的热度
+	DeltaPercent *float64 // 热度相对上一窗口的变化百分比，上一窗口没有热度时为nil
+}
+
+// trendingSnapshot 定时计算的结果
+type trendingSnapshot struct {
+	computedAt time.Time
+	windows    map[string][]trendEntry
+}
+
+// searchBucket 一个整点小时内各资源命中的搜索次数
+type searchBucket map[string]int64
+
+// Trending 定时根据搜索记录和下载记录计算热门趋势，请求时只读取计算结果。
+// 搜索记录只有关键词，每个整点小时结束后按当时的搜索结果计算一次命中并保存，
+// 上一窗口使用当时保存的结果，不受之后资源增删的影响
+type Trending struct {
+	db        *gorm.DB
+	cfg       config.TrendingConfig
+	mu        sync.RWMutex
+	snapshot  *trendingSnapshot
+	bucketsMu sync.Mutex
+	buckets   map[time.Time]searchBucket // 已结束的整点小时，键为小时的开始时间
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+var (
+	trendingMu      sync.RWMutex
+	defaultTrending *Trending
+)
+
+// StartTrending 启动定时计算并设为默认实例，启动后立即在后台计算一次
+func StartTrending(db *gorm.DB, cfg config.TrendingConfig) *Trending {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultTrendingInterval
+	}
+	if cfg.TopKeywords <= 0 {
+		cfg.TopKeywords = defaultTopKeywords
+	}
+	if cfg.HitsPerKeyword <= 0 {
+		cfg.HitsPerKeyword = defaultHitsPerKeyword
+	}
+
+	t := &Trending{db: db, cfg: cfg, buckets: make(map[time.Time]searchBucket), done: make(chan struct{})}
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.loop()
+	}()
+
+	trendingMu.Lock()
+	defaultTrending = t
+	trendingMu.Unlock()
+	return t
+}
+
+// DefaultTrending 返回默认实例，未启动时返回nil
+func DefaultTrending() *Trending {
+	trendingMu.RLock()
+	defer trendingMu.RUnlock()
+	return defaultTrending
+}
+
+// Stop 停止定时计算，等待正在进行的计算完成后返回
+func (t *Trending) Stop() {
+	t.closeOnce.Do(func() {
+		close(t.done)
+	})
+	t.wg.Wait()
+}
+
+// loop 立即计算一次，之后按间隔重新计算
+func (t *Trending) loop() {
+	ticker := time.NewTicker(t.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.Refresh(); err != nil {
+			log.Printf("Failed to compute trending: %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-t.done:
+			return
+		}
+	}
+}
+
+// Refresh 重新计算全部时间窗口的热度。窗口按整点小时对齐，当前窗口包含进行中的小时
+func (t *Trending) Refresh() error {
+	now := time.Now()
+	snapshot := &trendingSnapshot{computedAt: now, windows: make(map[string][]trendEntry, len(TrendingWindows))}
+
+	t.bucketsMu.Lock()
+	defer t.bucketsMu.Unlock()
+
+	// 同一搜索词在本次计算中只检索一次
+	hitsCache := make(map[string][]string)
+	currentHour := now.Truncate(time.Hour)
+	var oldest time.Time
+	for name, window := range TrendingWindows {
+		currentStart := currentHour.Add(time.Hour - window)
+		previousStart := currentStart.Add(-window)
+		if oldest.IsZero() || previousStart.Before(oldest) {
+			oldest = previousStart
+		}
+
+		current, err := t.windowScores(currentStart, now, hitsCache)
+		if err != nil {
+			return err
+		}
+		previous, err := t.windowScores(previousStart, currentStart, hitsCache)
+		if err != nil {
+			return err
+		}
+
+		entries, err := t.mergeScores(current, previous)
+		if err != nil {
+			return err
+		}
+		snapshot.windows[name] = entries
+	}
+
+	// 清理所有窗口都不再使用的小时
+	for hour := range t.buckets {
+		if hour.Before(oldest) {
+			delete(t.buckets, hour)
+		}
+	}
+
+	t.mu.Lock()
+	t.snapshot = snapshot
+	t.mu.Unlock()
+	return nil
+}
+
+// ComputedAt 最近一次计算的时间，尚未计算完成时返回零值
+func (t *Trending) ComputedAt() time.Time {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.snapshot == nil {
+		return time.Time{}
+	}
+	return t.snapshot.computedAt
+}
+
+// Top 返回时间窗口内热度最高的资源，categoryIDs不为nil时只在这些分类中排名。
+// 尚未计算完成时返回空列表
+func (t *Trending) Top(window string, categoryIDs []uint, limit int) ([]TrendItem, error) {
+	if _, ok := TrendingWindows[window]; !ok {
+		return nil, ErrInvalidTrendingWindow
+	}
+
+	t.mu.RLock()
+	snapshot := t.snapshot
+	t.mu.RUnlock()
+	if snapshot == nil {
+		return nil, nil
+	}
+
+	entries := snapshot.windows[window]
+	if categoryIDs != nil {
+		allowed := make(map[uint]bool, len(categoryIDs))
+		for _, id := range categoryIDs {
+			allowed[id] = true
+		}
+		filtered := make([]trendEntry, 0, len(entries))
+		for _, entry := range entries {
+			if allowed[entry.CategoryID] {
+				filtered = append(filtered, entry)
+			}
+		}
+		entries = filtered
+	}
+
+	// 两个窗口分别按各自的统计排名，上一窗口的排名在同样的分类范围内计算
+	prevRanks := rankEntries(entries, func(e trendEntry) windowCounts { return e.Previous })
+	current := rankEntries(entries, func(e trendEntry) windowCounts { return e.Current })
+
+	var items []TrendItem
+	for i, entry := range current {
+		if i >= limit {
+			break
+		}
+		item := TrendItem{
+			ResourceID: entry.ResourceID,
+			Rank:       i + 1,
+			PrevRank:   prevRanks.rank(entry.ResourceID),
+			Searches:   entry.Current.Searches,
+			Downloads:  entry.Current.Downloads,
+			Score:      entry.Current.score(),
+		}
+		switch {
+		case item.PrevRank == 0:
+			item.Trend = TrendNew
+		case item.PrevRank > item.Rank:
+			item.Trend = TrendUp
+		case item.PrevRank < item.Rank:
+			item.Trend = TrendDown
+		default:
+			item.Trend = TrendStable
+		}
+		if item.PrevRank > 0 {
+			item.RankChange = item.PrevRank - item.Rank
+		}
+		if prev := entry.Previous.score(); prev > 0 {
+			delta := math.Round(float64(item.Score-prev)/float64(prev)*1000) / 10
+			item.DeltaPercent = &delta
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// windowScores 统计[start, end)内各资源的下载次数和命中的搜索次数，start为整点。
+// 已结束的小时使用保存的搜索命中，进行中的小时每次重新检索
+func (t *Trending) windowScores(start, end time.Time, hitsCache map[string][]string) (map[string]*windowCounts, error) {
+	scores := make(map[string]*windowCounts)
+	get := func(id string) *windowCounts {
+		score, ok := scores[id]
+		if !ok {
+			score = &windowCounts{}
+			scores[id] = score
+		}
+		return score
+	}
+
+	var downloads []struct {
+		ResourceID string
+		Count      int64
+	}
+	if err := t.db.Model(&models.DownloadRecord{}).
+		Select("resource_id, COUNT(*) AS count").
+		Where("download_time >= ? AND download_time < ?", start, end).
+		Group("resource_id").
+		Scan(&downloads).Error; err != nil {
+		return nil, err
+	}
+	for _, d := range downloads {
+		get(d.ResourceID).Downloads += d.Count
+	}
+
+	for hour := start; hour.Before(end); hour = hour.Add(time.Hour) {
+		bucket, ok := t.buckets[hour]
+		if !ok {
+			hourEnd := hour.Add(time.Hour)
+			closed := !hourEnd.After(end)
+			if !closed {
+				hourEnd = end
+			}
+			var err error
+			if bucket, err = t.hourSearches(hour, hourEnd, hitsCache); err != nil {
+				return nil, err
+			}
+			if closed {
+				t.buckets[hour] = bucket
+			}
+		}
+		for id, count := range bucket {
+			get(id).Searches += count
+		}
+	}
+	return scores, nil
+}
+
+// hourSearches 统计[start, end)内的热门搜索词，按当前的搜索结果将搜索次数计入排在前面的资源。
+// 服务启动前的小时在启动后补算，使用的是启动时的搜索结果
+func (t *Trending) hourSearches(start, end time.Time, hitsCache map[string][]string) (searchBucket, error) {
+	var keywords []struct {
+		Keyword string
+		Count   int64
+	}
+	if err := t.db.Model(&models.SearchRecord{}).
+		Select("keyword, COUNT(*) AS count").
+		Where("search_time >= ? AND search_time < ?", start, end).
+		Group("keyword").
+		Order("count DESC").
+		Limit(t.cfg.TopKeywords).
+		Scan(&keywords).Error; err != nil {
+		return nil, err
+	}
+
+	bucket := make(searchBucket)
+	for _, k := range keywords {
+		ids, err := t.keywordHits(k.Keyword, hitsCache)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			bucket[id] += k.Count
+		}
+	}
+	return bucket, nil
+}
+
+// keywordHits 检索搜索词，返回排在前面的资源ID；高级语法只取其中的关键词
+func (t *Trending) keywordHits(keyword string, cache map[string][]string) ([]string, error) {
+	if ids, ok := cache[keyword]; ok {
+		return ids, nil
+	}
+
+	var ids []string
+	if query, err := search.ParseQuery(keyword); err == nil && query.HasText() {
+		hits, err := search.Search(t.db, query.Text(), t.cfg.HitsPerKeyword)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range hits {
+			ids = append(ids, hit.ID)
+		}
+	}
+	cache[keyword] = ids
+	return ids, nil
+}
+
+// mergeScores 合并两个窗口的统计，只保留有效资源并记录分类
+func (t *Trending) mergeScores(current, previous map[string]*windowCounts) ([]trendEntry, error) {
+	ids := make([]string, 0, len(current)+len(previous))
+	for id := range current {
+		ids = append(ids, id)
+	}
+	for id := range previous {
+		if _, ok := current[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// 分批查询，避免IN的参数过多
+	var resources []models.Resource
+	for start := 0; start < len(ids); start += trendingBatchSize {
+		end := min(start+trendingBatchSize, len(ids))
+		var batch []models.Resource
+		if err := t.db.Select("id", "category_id").
+			Where("id IN ? AND valid = ?", ids[start:end], true).
+			Find(&batch).Error; err != nil {
+			return nil, err
+		}
+		resources = append(resources, batch...)
+	}
+
+	entries := make([]trendEntry, 0, len(resources))
+	for _, resource := range resources {
+		entry := trendEntry{ResourceID: resource.ID, CategoryID: resource.CategoryID}
+		if counts, ok := current[resource.ID]; ok {
+			entry.Current = *counts
+		}
+		if counts, ok := previous[resource.ID]; ok {
+			entry.Previous = *counts
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// rankedEntries 按热度降序排列的资源，热度为0的不参与排名
+type rankedEntries []trendEntry
+
+// rankEntries 按window选出的窗口排名，热度相同时该窗口下载多的在前，再按ID保证顺序稳定
+func rankEntries(entries []trendEntry, window func(trendEntry) windowCounts) rankedEntries {
+	ranked := make(rankedEntries, 0, len(entries))
+	for _, entry := range entries {
+		if window(entry).score() > 0 {
+			ranked = append(ranked, entry)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		ci, cj := window(ranked[i]), window(ranked[j])
+		if ci.score() != cj.score() {
+			return ci.score() > cj.score()
+		}
+		if ci.Downloads != cj.Downloads {
+			return ci.Downloads > cj.Downloads
+		}
+		return ranked[i].ResourceID < ranked[j].ResourceID
+	})
+	return ranked
+}
+
+// rank 返回资源的排名，未上榜时返回0
+func (r rankedEntries) rank(id string) int {
+	for i, entry := range r {
+		if entry.ResourceID == id {
+			return i + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func currentCounts(e trendEntry) windowCounts { return e.Current }
+
+func TestRankEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []trendEntry
+		want    []string
+	}{
+		{
+			name: "score descending",
+			entries: []trendEntry{
+				{ResourceID: "a", Current: windowCounts{Searches: 1}},
+				{ResourceID: "b", Current: windowCounts{Downloads: 1}},
+				{ResourceID: "c", Current: windowCounts{Searches: 5}},
+			},
+			want: []string{"c", "b", "a"},
+		},
+		{
+			// 热度相同时下载多的在前
+			name: "tie on downloads",
+			entries: []trendEntry{
+				{ResourceID: "a", Current: windowCounts{Searches: 3}},
+				{ResourceID: "b", Current: windowCounts{Downloads: 1}},
+			},
+			want: []string{"b", "a"},
+		},
+		{
+			name: "tie on id",
+			entries: []trendEntry{
+				{ResourceID: "b", Current: windowCounts{Searches: 2}},
+				{ResourceID: "a", Current: windowCounts{Searches: 2}},
+			},
+			want: []string{"a", "b"},
+		},
+		{
+			// 只看所选窗口，上一窗口的热度不影响排名
+			name: "zero score excluded",
+			entries: []trendEntry{
+				{ResourceID: "a", Previous: windowCounts{Downloads: 10}},
+				{ResourceID: "b", Current: windowCounts{Searches: 1}},
+			},
+			want: []string{"b"},
+		},
+		{
+			name: "empty",
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, entry := range rankEntries(tt.entries, currentCounts) {
+				got = append(got, entry.ResourceID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rankEntries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankedEntriesRank(t *testing.T) {
+	ranked := rankedEntries{{ResourceID: "a"}, {ResourceID: "b"}}
+	tests := []struct {
+		id   string
+		want int
+	}{
+		{"a", 1},
+		{"b", 2},
+		{"c", 0},
+	}
+
+	for _, tt := range tests {
+		if got := ranked.rank(tt.id); got != tt.want {
+			t.Errorf("rank(%q) = %d, want %d", tt.id, got, tt.want)
+		}
+	}
+}
+
+func testTrending(entries []trendEntry) *Trending {
+	return &Trending{snapshot: &trendingSnapshot{windows: map[string][]trendEntry{DefaultTrendingWindow: entries}}}
+}
+
+func TestTrendingTop(t *testing.T) {
+	tr := testTrending([]trendEntry{
+		// 上一窗口第2，当前第1
+		{ResourceID: "up", CategoryID: 1, Current: windowCounts{Downloads: 10}, Previous: windowCounts{Searches: 2}},
+		// 上一窗口第1，当前第2
+		{ResourceID: "down", CategoryID: 2, Current: windowCounts{Searches: 6}, Previous: windowCounts{Downloads: 4}},
+		{ResourceID: "new", CategoryID: 1, Current: windowCounts{Searches: 2}},
+		// 当前窗口没有热度，不上榜
+		{ResourceID: "gone", CategoryID: 1, Previous: windowCounts{Searches: 1}},
+	})
+
+	items, err := tr.Top(DefaultTrendingWindow, nil, 10)
+	if err != nil {
+		t.Fatalf("Top() error = %v", err)
+	}
+
+	type result struct {
+		ID         string
+		Rank       int
+		PrevRank   int
+		RankChange int
+		Trend      string
+		Score      int64
+		Delta      float64
+	}
+	want := []result{
+		{"up", 1, 2, 1, TrendUp, 30, 1400},
+		{"down", 2, 1, -1, TrendDown, 6, -50},
+		{"new", 3, 0, 0, TrendNew, 2, 0},
+	}
+	var got []result
+	for _, item := range items {
+		r := result{item.ResourceID, item.Rank, item.PrevRank, item.RankChange, item.Trend, item.Score, 0}
+		if item.DeltaPercent != nil {
+			r.Delta = *item.DeltaPercent
+		}
+		got = append(got, r)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Top() = %+v, want %+v", got, want)
+	}
+	if items[2].DeltaPercent != nil {
+		t.Errorf("Top() new item DeltaPercent = %v, want nil", *items[2].DeltaPercent)
+	}
+}
+
+func TestTrendingTopFilters(t *testing.T) {
+	tr := testTrending([]trendEntry{
+		{ResourceID: "a", CategoryID: 1, Current: windowCounts{Searches: 3}, Previous: windowCounts{Searches: 1}},
+		{ResourceID: "b", CategoryID: 2, Current: windowCounts{Searches: 2}, Previous: windowCounts{Searches: 5}},
+		{ResourceID: "c", CategoryID: 1, Current: windowCounts{Searches: 1}, Previous: windowCounts{Searches: 2}},
+	})
+
+	tests := []struct {
+		name        string
+		categoryIDs []uint
+		limit       int
+		want        []string
+		wantPrev    []int
+	}{
+		{"all", nil, 10, []string{"a", "b", "c"}, []int{3, 1, 2}},
+		{"limit", nil, 2, []string{"a", "b"}, []int{3, 1}},
+		// 上一窗口的排名在同样的分类范围内计算
+		{"category", []uint{1}, 10, []string{"a", "c"}, []int{2, 1}},
+		{"no category matches", []uint{}, 10, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := tr.Top(DefaultTrendingWindow, tt.categoryIDs, tt.limit)
+			if err != nil {
+				t.Fatalf("Top() error = %v", err)
+			}
+			var ids []string
+			var prev []int
+			for _, item := range items {
+				ids = append(ids, item.ResourceID)
+				prev = append(prev, item.PrevRank)
+			}
+			if !reflect.DeepEqual(ids, tt.want) || !reflect.DeepEqual(prev, tt.wantPrev) {
+				t.Errorf("Top() = %v (prev %v), want %v (prev %v)", ids, prev, tt.want, tt.wantPrev)
+			}
+		})
+	}
+}
+
+func TestTrendingTopErrors(t *testing.T) {
+	if _, err := testTrending(nil).Top("30d", nil, 10); !errors.Is(err, ErrInvalidTrendingWindow) {
+		t.Errorf("Top(30d) error = %v, want %v", err, ErrInvalidTrendingWindow)
+	}
+	// 尚未计算完成
+	items, err := (&Trending{}).Top(DefaultTrendingWindow, nil, 10)
+	if err != nil || items != nil {
+		t.Errorf("Top() before refresh = %v, %v, want nil, nil", items, err)
+	}
+}
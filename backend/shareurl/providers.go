@@ -0,0 +1,174 @@
+package shareurl
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// provider 一个网盘的链接格式
+type provider struct {
+	name      string
+	match     func(host string) bool
+	parse     func(u *url.URL) string // 返回分享ID，无法识别时返回空
+	canonical func(id string) string
+}
+
+// providers 支持的网盘，按顺序匹配域名
+var providers = []provider{
+	{
+		name:  "baidu",
+		match: domains("pan.baidu.com", "yun.baidu.com"),
+		parse: func(u *url.URL) string {
+			// 旧版链接 /share/init?surl=xxx 中的ID省略了开头的1
+			if surl := u.Query().Get("surl"); surl != "" {
+				return "1" + surl
+			}
+			return pathSegment(u.Path, "/s/")
+		},
+		canonical: prefix("https://pan.baidu.com/s/"),
+	},
+	{
+		name:      "aliyun",
+		match:     domains("alipan.com", "aliyundrive.com"),
+		parse:     pathID("/s/"),
+		canonical: prefix("https://www.alipan.com/s/"),
+	},
+	{
+		name:      "quark",
+		match:     domains("pan.quark.cn"),
+		parse:     pathID("/s/"),
+		canonical: prefix("https://pan.quark.cn/s/"),
+	},
+	{
+		name:      "115",
+		match:     domains("115.com", "115cdn.com", "anxia.com"),
+		parse:     pathID("/s/"),
+		canonical: prefix("https://115.com/s/"),
+	},
+	{
+		name:  "tianyi",
+		match: domains("cloud.189.cn"),
+		parse: func(u *url.URL) string {
+			// 网页版 /web/share?code=xxx，手机版 /share.html#/t/xxx
+			if id := u.Query().Get("code"); strings.HasPrefix(u.Path, "/web/share") && id != "" {
+				return id
+			}
+			if id := pathSegment(u.Fragment, "/t/"); id != "" {
+				return id
+			}
+			return pathSegment(u.Path, "/t/")
+		},
+		canonical: prefix("https://cloud.189.cn/t/"),
+	},
+	{
+		name:      "xunlei",
+		match:     domains("pan.xunlei.com"),
+		parse:     pathID("/s/"),
+		canonical: prefix("https://pan.xunlei.com/s/"),
+	},
+	{
+		name: "lanzou",
+		match: func(host string) bool {
+			return lanzouHost.MatchString(host)
+		},
+		parse: func(u *url.URL) string {
+			// 文件和文件夹链接都只有一级路径，如 /iAbc123、/b0abc123；/tp/xxx 为手机版
+			path := u.Path
+			if rest, ok := strings.CutPrefix(path, "/tp/"); ok {
+				path = rest
+			}
+			id := strings.Trim(path, "/")
+			if strings.Contains(id, "/") || id == "u" {
+				return ""
+			}
+			return id
+		},
+		canonical: prefix("https://www.lanzoui.com/"),
+	},
+	{
+		name:  "123pan",
+		match: domains("123pan.com", "123pan.cn", "123684.com", "123865.com", "123912.com", "123592.com"),
+		parse: func(u *url.URL) string {
+			return strings.TrimSuffix(pathSegment(u.Path, "/s/"), ".html")
+		},
+		canonical: prefix("https://www.123pan.com/s/"),
+	},
+	{
+		name:      "uc",
+		match:     domains("drive.uc.cn"),
+		parse:     pathID("/s/"),
+		canonical: prefix("https://drive.uc.cn/s/"),
+	},
+	{
+		name:  "caiyun",
+		match: domains("caiyun.139.com", "yun.139.com"),
+		parse: func(u *url.URL) string {
+			// 手机版 /m/i?xxx 的ID就是整个查询串
+			if strings.HasPrefix(u.Path, "/m/i") {
+				id, _, _ := strings.Cut(u.RawQuery, "&")
+				return id
+			}
+			for _, p := range []string{"/link/w/i/", "/w/i/"} {
+				if id := pathSegment(u.Path, p); id != "" {
+					return id
+				}
+			}
+			return ""
+		},
+		canonical: prefix("https://caiyun.139.com/m/i?"),
+	},
+	{
+		name:  "weiyun",
+		match: domains("share.weiyun.com"),
+		parse: func(u *url.URL) string {
+			return strings.Trim(u.Path, "/")
+		},
+		canonical: prefix("https://share.weiyun.com/"),
+	},
+	{
+		name:      "pikpak",
+		match:     domains("mypikpak.com"),
+		parse:     pathID("/s/"),
+		canonical: prefix("https://mypikpak.com/s/"),
+	},
+}
+
+// 蓝奏云使用大量备用域名，如 lanzoux.com、lanzoui.com、lanzn.com，子域名因用户而异
+var lanzouHost = regexp.MustCompile(`(^|\.)(lanzou[a-z]?|lanzn)\.com$`)
+
+// domains 域名相同或为其子域名时匹配
+func domains(names ...string) func(string) bool {
+	return func(host string) bool {
+		for _, name := range names {
+			if host == name || strings.HasSuffix(host, "."+name) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// pathID 取出路径中前缀后的一级作为分享ID
+func pathID(prefix string) func(*url.URL) string {
+	return func(u *url.URL) string {
+		return pathSegment(u.Path, prefix)
+	}
+}
+
+// pathSegment 取出path中prefix之后的第一级路径，不以prefix开头时返回空
+func pathSegment(path, prefix string) string {
+	rest, ok := strings.CutPrefix(path, prefix)
+	if !ok {
+		return ""
+	}
+	segment, _, _ := strings.Cut(rest, "/")
+	return segment
+}
+
+// prefix 在分享ID前拼接固定前缀生成规范链接
+func prefix(base string) func(string) string {
+	return func(id string) string {
+		return base + id
+	}
+}
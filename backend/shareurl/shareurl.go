@@ -0,0 +1,159 @@
+// Package shareurl 识别国内常见网盘的分享链接，从粘贴的文本中提取分享ID和提取码并规范化链接
+package shareurl
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Share 解析出的网盘分享
+type Share struct {
+	Provider    string `json:"provider"` // 网盘名，与资源的source一致，如 baidu、aliyun
+	ShareID     string `json:"shareId"`
+	ExtractCode string `json:"extractCode,omitempty"`
+	URL         string `json:"url"` // 规范化的分享链接，不含提取码
+}
+
+// Key 去重键，同一网盘的同一分享不论链接写法都相同
+func (s Share) Key() string {
+	return s.Provider + ":" + s.ShareID
+}
+
+var (
+	// 文本中的链接，允许省略协议；路径只取URL字符，避免吞掉紧跟的中文
+	urlPattern = regexp.MustCompile(`(?i)(?:https?://)?(?:[a-z0-9-]+\.)+[a-z]{2,}(?::\d+)?(?:[/?#][A-Za-z0-9\-._~/?#@!$&*+,;=%:]*)?`)
+	// 提取码的写法，如 提取码: abcd、密码：abcd、访问码 abcd、pwd=abcd
+	codePattern = regexp.MustCompile(`(?i)(?:提取码|提取碼|访问码|訪問碼|密码|密碼|口令|(?:^|[^a-z])(?:pwd|password|passcode))[\s\x{3000}]*(?:[:：=]|为|是)?[\s\x{3000}]*([a-z0-9]{2,8})(?:[^a-z0-9]|$)`)
+	// 分享ID只允许字母、数字、下划线和连字符
+	idPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,64}$`)
+	// 提取码为2到8位字母或数字
+	extractCodePattern = regexp.MustCompile(`^[A-Za-z0-9]{2,8}$`)
+)
+
+// 链接末尾通常不属于链接的标点
+const trailingPunctuation = ".,;:!?)]}'\""
+
+// 链接参数中表示提取码的参数名
+var codeParams = []string{"pwd", "password", "passcode"}
+
+// ParseURL 解析单个分享链接，缺少协议时按https处理
+func ParseURL(raw string) (Share, bool) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return Share{}, false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	for _, p := range providers {
+		if !p.match(host) {
+			continue
+		}
+		id := p.parse(u)
+		if !idPattern.MatchString(id) {
+			return Share{}, false
+		}
+		code := queryCode(u)
+		if !extractCodePattern.MatchString(code) {
+			code = ""
+		}
+		return Share{Provider: p.name, ShareID: id, ExtractCode: code, URL: p.canonical(id)}, true
+	}
+	return Share{}, false
+}
+
+// Parse 从文本中解析第一个可识别的分享链接
+func Parse(text string) (Share, bool) {
+	shares := ParseAll(text)
+	if len(shares) == 0 {
+		return Share{}, false
+	}
+	return shares[0], true
+}
+
+// ParseAll 按出现顺序解析文本中全部可识别的分享链接，同一分享只返回一次。
+// 链接中没有提取码时，从链接与下一个链接之间的文本中查找；
+// 如果第一个链接之前就出现了提取码，则认为提取码写在链接前面
+func ParseAll(text string) []Share {
+	type located struct {
+		share      Share
+		start, end int
+	}
+
+	var found []located
+	for _, loc := range urlPattern.FindAllStringIndex(text, -1) {
+		raw := strings.TrimRight(text[loc[0]:loc[1]], trailingPunctuation)
+		// 邮箱等前面紧跟字母数字的不是独立的链接
+		if loc[0] > 0 && isURLChar(text[loc[0]-1]) {
+			continue
+		}
+		if share, ok := ParseURL(raw); ok {
+			found = append(found, located{share: share, start: loc[0], end: loc[0] + len(raw)})
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+
+	codeBefore := findCode(text[:found[0].start]) != ""
+	for i := range found {
+		if found[i].share.ExtractCode != "" {
+			continue
+		}
+		var segment string
+		switch {
+		case codeBefore && i == 0:
+			segment = text[:found[i].start]
+		case codeBefore:
+			segment = text[found[i-1].end:found[i].start]
+		case i+1 < len(found):
+			segment = text[found[i].end:found[i+1].start]
+		default:
+			segment = text[found[i].end:]
+		}
+		found[i].share.ExtractCode = findCode(segment)
+	}
+
+	seen := make(map[string]int, len(found))
+	shares := make([]Share, 0, len(found))
+	for _, f := range found {
+		if i, ok := seen[f.share.Key()]; ok {
+			if shares[i].ExtractCode == "" {
+				shares[i].ExtractCode = f.share.ExtractCode
+			}
+			continue
+		}
+		seen[f.share.Key()] = len(shares)
+		shares = append(shares, f.share)
+	}
+	return shares
+}
+
+// findCode 查找文本中第一个提取码
+func findCode(text string) string {
+	match := codePattern.FindStringSubmatch(text)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// queryCode 读取链接参数中的提取码
+func queryCode(u *url.URL) string {
+	query := u.Query()
+	for _, name := range codeParams {
+		if code := query.Get(name); code != "" {
+			return code
+		}
+	}
+	return ""
+}
+
+// isURLChar 是否为链接中的字母、数字或点
+func isURLChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '@' || c == '-'
+}
@@ -0,0 +1,369 @@
+package shareurl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want Share
+		ok   bool
+	}{
+		// 百度网盘
+		{"baidu", "https://pan.baidu.com/s/1AbCdEfGhIjKlMnOpQrStUv",
+			Share{"baidu", "1AbCdEfGhIjKlMnOpQrStUv", "", "https://pan.baidu.com/s/1AbCdEfGhIjKlMnOpQrStUv"}, true},
+		{"baidu pwd param", "https://pan.baidu.com/s/1AbCdEfGh?pwd=x7k2",
+			Share{"baidu", "1AbCdEfGh", "x7k2", "https://pan.baidu.com/s/1AbCdEfGh"}, true},
+		{"baidu http", "http://pan.baidu.com/s/1AbCdEfGh",
+			Share{"baidu", "1AbCdEfGh", "", "https://pan.baidu.com/s/1AbCdEfGh"}, true},
+		{"baidu without scheme", "pan.baidu.com/s/1AbCdEfGh",
+			Share{"baidu", "1AbCdEfGh", "", "https://pan.baidu.com/s/1AbCdEfGh"}, true},
+		{"baidu uppercase host", "https://PAN.BAIDU.COM/s/1AbCdEfGh",
+			Share{"baidu", "1AbCdEfGh", "", "https://pan.baidu.com/s/1AbCdEfGh"}, true},
+		{"baidu share init", "https://pan.baidu.com/share/init?surl=AbCdEfGh",
+			Share{"baidu", "1AbCdEfGh", "", "https://pan.baidu.com/s/1AbCdEfGh"}, true},
+		{"baidu share init with pwd", "https://pan.baidu.com/share/init?surl=AbCdEfGh&pwd=abcd",
+			Share{"baidu", "1AbCdEfGh", "abcd", "https://pan.baidu.com/s/1AbCdEfGh"}, true},
+		{"baidu yun domain", "https://yun.baidu.com/s/1AbCdEfGh",
+			Share{"baidu", "1AbCdEfGh", "", "https://pan.baidu.com/s/1AbCdEfGh"}, true},
+		{"baidu trailing slash", "https://pan.baidu.com/s/1AbCdEfGh/",
+			Share{"baidu", "1AbCdEfGh", "", "https://pan.baidu.com/s/1AbCdEfGh"}, true},
+		{"baidu with fragment", "https://pan.baidu.com/s/1AbCdEfGh#list/path=%2F",
+			Share{"baidu", "1AbCdEfGh", "", "https://pan.baidu.com/s/1AbCdEfGh"}, true},
+		{"baidu with hyphen and underscore", "https://pan.baidu.com/s/1Ab-Cd_Ef",
+			Share{"baidu", "1Ab-Cd_Ef", "", "https://pan.baidu.com/s/1Ab-Cd_Ef"}, true},
+		{"baidu invalid pwd ignored", "https://pan.baidu.com/s/1AbCdEfGh?pwd=a",
+			Share{"baidu", "1AbCdEfGh", "", "https://pan.baidu.com/s/1AbCdEfGh"}, true},
+		{"baidu disk home", "https://pan.baidu.com/disk/home", Share{}, false},
+		{"baidu empty id", "https://pan.baidu.com/s/", Share{}, false},
+
+		// 阿里云盘
+		{"aliyundrive", "https://www.aliyundrive.com/s/AbC123dEf45",
+			Share{"aliyun", "AbC123dEf45", "", "https://www.alipan.com/s/AbC123dEf45"}, true},
+		{"alipan", "https://www.alipan.com/s/AbC123dEf45",
+			Share{"aliyun", "AbC123dEf45", "", "https://www.alipan.com/s/AbC123dEf45"}, true},
+		{"aliyun folder", "https://www.aliyundrive.com/s/AbC123dEf45/folder/63f0a1b2c3d4",
+			Share{"aliyun", "AbC123dEf45", "", "https://www.alipan.com/s/AbC123dEf45"}, true},
+		{"aliyun without www", "aliyundrive.com/s/AbC123dEf45",
+			Share{"aliyun", "AbC123dEf45", "", "https://www.alipan.com/s/AbC123dEf45"}, true},
+		{"aliyun drive page", "https://www.aliyundrive.com/drive", Share{}, false},
+
+		// 夸克网盘
+		{"quark", "https://pan.quark.cn/s/1a2b3c4d5e6f",
+			Share{"quark", "1a2b3c4d5e6f", "", "https://pan.quark.cn/s/1a2b3c4d5e6f"}, true},
+		{"quark with pwd", "https://pan.quark.cn/s/1a2b3c4d5e6f?pwd=Ab12",
+			Share{"quark", "1a2b3c4d5e6f", "Ab12", "https://pan.quark.cn/s/1a2b3c4d5e6f"}, true},
+		{"quark list fragment", "https://pan.quark.cn/s/1a2b3c4d5e6f#/list/share",
+			Share{"quark", "1a2b3c4d5e6f", "", "https://pan.quark.cn/s/1a2b3c4d5e6f"}, true},
+
+		// 115网盘
+		{"115", "https://115.com/s/sw3abcd1234",
+			Share{"115", "sw3abcd1234", "", "https://115.com/s/sw3abcd1234"}, true},
+		{"115 password", "https://115.com/s/sw3abcd1234?password=q1w2",
+			Share{"115", "sw3abcd1234", "q1w2", "https://115.com/s/sw3abcd1234"}, true},
+		{"115cdn", "https://115cdn.com/s/sw3abcd1234?password=q1w2#",
+			Share{"115", "sw3abcd1234", "q1w2", "https://115.com/s/sw3abcd1234"}, true},
+		{"anxia", "https://anxia.com/s/sw3abcd1234",
+			Share{"115", "sw3abcd1234", "", "https://115.com/s/sw3abcd1234"}, true},
+
+		// 天翼云盘
+		{"tianyi", "https://cloud.189.cn/t/AbCdEf123456",
+			Share{"tianyi", "AbCdEf123456", "", "https://cloud.189.cn/t/AbCdEf123456"}, true},
+		{"tianyi web share", "https://cloud.189.cn/web/share?code=AbCdEf123456",
+			Share{"tianyi", "AbCdEf123456", "", "https://cloud.189.cn/t/AbCdEf123456"}, true},
+		{"tianyi h5", "https://h5.cloud.189.cn/share.html#/t/AbCdEf123456",
+			Share{"tianyi", "AbCdEf123456", "", "https://cloud.189.cn/t/AbCdEf123456"}, true},
+		{"tianyi home", "https://cloud.189.cn/web/main/", Share{}, false},
+
+		// 迅雷云盘
+		{"xunlei", "https://pan.xunlei.com/s/VNaBcDeFgHiJkLmN",
+			Share{"xunlei", "VNaBcDeFgHiJkLmN", "", "https://pan.xunlei.com/s/VNaBcDeFgHiJkLmN"}, true},
+		{"xunlei pwd with hash", "https://pan.xunlei.com/s/VNaBcDeFgHiJkLmN?pwd=mn3k#",
+			Share{"xunlei", "VNaBcDeFgHiJkLmN", "mn3k", "https://pan.xunlei.com/s/VNaBcDeFgHiJkLmN"}, true},
+
+		// 蓝奏云
+		{"lanzou file", "https://wwi.lanzoui.com/iAbC123dEf",
+			Share{"lanzou", "iAbC123dEf", "", "https://www.lanzoui.com/iAbC123dEf"}, true},
+		{"lanzoux folder", "https://wws.lanzoux.com/b0abc123d",
+			Share{"lanzou", "b0abc123d", "", "https://www.lanzoui.com/b0abc123d"}, true},
+		{"lanzous", "https://www.lanzous.com/iAbC123dEf",
+			Share{"lanzou", "iAbC123dEf", "", "https://www.lanzoui.com/iAbC123dEf"}, true},
+		{"lanzn", "https://xyz.lanzn.com/iAbC123dEf",
+			Share{"lanzou", "iAbC123dEf", "", "https://www.lanzoui.com/iAbC123dEf"}, true},
+		{"lanzou without subdomain", "lanzouw.com/iAbC123dEf",
+			Share{"lanzou", "iAbC123dEf", "", "https://www.lanzoui.com/iAbC123dEf"}, true},
+		{"lanzou mobile", "https://wwa.lanzoui.com/tp/iAbC123dEf",
+			Share{"lanzou", "iAbC123dEf", "", "https://www.lanzoui.com/iAbC123dEf"}, true},
+		{"lanzou id starting with tp", "https://wwa.lanzoui.com/tpAbC123",
+			Share{"lanzou", "tpAbC123", "", "https://www.lanzoui.com/tpAbC123"}, true},
+		{"lanzou user page", "https://wwa.lanzoui.com/u/someone", Share{}, false},
+		{"lanzou home", "https://www.lanzoui.com/", Share{}, false},
+		{"not lanzou", "https://lanzouxyz.com/iAbC123dEf", Share{}, false},
+
+		// 123云盘
+		{"123pan", "https://www.123pan.com/s/abcD-EfGh",
+			Share{"123pan", "abcD-EfGh", "", "https://www.123pan.com/s/abcD-EfGh"}, true},
+		{"123pan html suffix", "https://www.123pan.com/s/abcD-EfGh.html",
+			Share{"123pan", "abcD-EfGh", "", "https://www.123pan.com/s/abcD-EfGh"}, true},
+		{"123pan mirror", "https://www.123684.com/s/abcD-EfGh?pwd=ZX90",
+			Share{"123pan", "abcD-EfGh", "ZX90", "https://www.123pan.com/s/abcD-EfGh"}, true},
+		{"123pan cn", "https://www.123pan.cn/s/abcD-EfGh",
+			Share{"123pan", "abcD-EfGh", "", "https://www.123pan.com/s/abcD-EfGh"}, true},
+
+		// 其他网盘
+		{"uc", "https://drive.uc.cn/s/a1b2c3d4e5f6",
+			Share{"uc", "a1b2c3d4e5f6", "", "https://drive.uc.cn/s/a1b2c3d4e5f6"}, true},
+		{"uc with public param", "https://drive.uc.cn/s/a1b2c3d4e5f6?public=1",
+			Share{"uc", "a1b2c3d4e5f6", "", "https://drive.uc.cn/s/a1b2c3d4e5f6"}, true},
+		{"caiyun mobile", "https://caiyun.139.com/m/i?0u5CaBcDeFgH",
+			Share{"caiyun", "0u5CaBcDeFgH", "", "https://caiyun.139.com/m/i?0u5CaBcDeFgH"}, true},
+		{"caiyun web", "https://caiyun.139.com/w/i/0u5CaBcDeFgH",
+			Share{"caiyun", "0u5CaBcDeFgH", "", "https://caiyun.139.com/m/i?0u5CaBcDeFgH"}, true},
+		{"caiyun link", "https://yun.139.com/link/w/i/0u5CaBcDeFgH",
+			Share{"caiyun", "0u5CaBcDeFgH", "", "https://caiyun.139.com/m/i?0u5CaBcDeFgH"}, true},
+		{"weiyun", "https://share.weiyun.com/AbCdEfGh",
+			Share{"weiyun", "AbCdEfGh", "", "https://share.weiyun.com/AbCdEfGh"}, true},
+		{"pikpak", "https://mypikpak.com/s/VNabcdefghijk",
+			Share{"pikpak", "VNabcdefghijk", "", "https://mypikpak.com/s/VNabcdefghijk"}, true},
+
+		// 无法识别
+		{"unknown host", "https://example.com/s/abcdef", Share{}, false},
+		{"lookalike host", "https://pan.baidu.com.evil.com/s/1AbCdEfGh", Share{}, false},
+		{"suffix without dot", "https://notpan.quark.cn.example/s/abc", Share{}, false},
+		{"invalid id characters", "https://pan.quark.cn/s/abc%20def", Share{}, false},
+		{"too short id", "https://pan.quark.cn/s/ab", Share{}, false},
+		{"empty", "", Share{}, false},
+		{"not a url", "提取码: abcd", Share{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseURL(tt.raw)
+			if ok != tt.ok || got != tt.want {
+				t.Errorf("ParseURL(%q) = %+v, %v; want %+v, %v", tt.raw, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Share
+	}{
+		{
+			"baidu copy text",
+			"链接: https://pan.baidu.com/s/1AbCdEfGh 提取码: x7k2 复制这段内容后打开百度网盘手机App，操作更方便哦",
+			[]Share{{"baidu", "1AbCdEfGh", "x7k2", "https://pan.baidu.com/s/1AbCdEfGh"}},
+		},
+		{
+			"baidu copy text with pwd param",
+			"链接：https://pan.baidu.com/s/1AbCdEfGh?pwd=x7k2 \n提取码：x7k2 \n--来自百度网盘超级会员V5的分享",
+			[]Share{{"baidu", "1AbCdEfGh", "x7k2", "https://pan.baidu.com/s/1AbCdEfGh"}},
+		},
+		{
+			"full width colon and space",
+			"链接：https://pan.baidu.com/s/1AbCdEfGh　提取码：　Q9w8",
+			[]Share{{"baidu", "1AbCdEfGh", "Q9w8", "https://pan.baidu.com/s/1AbCdEfGh"}},
+		},
+		{
+			"code directly after url",
+			"https://pan.baidu.com/s/1AbCdEfGh提取码:abcd",
+			[]Share{{"baidu", "1AbCdEfGh", "abcd", "https://pan.baidu.com/s/1AbCdEfGh"}},
+		},
+		{
+			"code without separator",
+			"https://pan.quark.cn/s/1a2b3c4d5e6f 提取码 ab12",
+			[]Share{{"quark", "1a2b3c4d5e6f", "ab12", "https://pan.quark.cn/s/1a2b3c4d5e6f"}},
+		},
+		{
+			"code written before url",
+			"提取码：zz99 链接：https://pan.baidu.com/s/1AbCdEfGh",
+			[]Share{{"baidu", "1AbCdEfGh", "zz99", "https://pan.baidu.com/s/1AbCdEfGh"}},
+		},
+		{
+			"quark share text",
+			"我用夸克网盘分享了「流浪地球2.mp4」，点击链接即可保存。打开「夸克APP」，无需下载在线播放视频，畅享原画5倍速，支持电视投屏。\n链接：https://pan.quark.cn/s/1a2b3c4d5e6f",
+			[]Share{{"quark", "1a2b3c4d5e6f", "", "https://pan.quark.cn/s/1a2b3c4d5e6f"}},
+		},
+		{
+			"aliyun share text",
+			"「流浪地球2」https://www.aliyundrive.com/s/AbC123dEf45 点击链接保存，或者复制本段内容，打开「阿里云盘」APP ，无需下载极速在线查看，视频原画倍速播放。",
+			[]Share{{"aliyun", "AbC123dEf45", "", "https://www.alipan.com/s/AbC123dEf45"}},
+		},
+		{
+			"aliyun share text with code",
+			"「资料合集」https://www.alipan.com/s/AbC123dEf45 提取码: 8p3m 点击链接保存",
+			[]Share{{"aliyun", "AbC123dEf45", "8p3m", "https://www.alipan.com/s/AbC123dEf45"}},
+		},
+		{
+			"tianyi access code",
+			"https://cloud.189.cn/t/AbCdEf123456（访问码：5x9d）",
+			[]Share{{"tianyi", "AbCdEf123456", "5x9d", "https://cloud.189.cn/t/AbCdEf123456"}},
+		},
+		{
+			"lanzou password",
+			"https://wwi.lanzoui.com/iAbC123dEf\n密码:6g3h",
+			[]Share{{"lanzou", "iAbC123dEf", "6g3h", "https://www.lanzoui.com/iAbC123dEf"}},
+		},
+		{
+			"115 share text",
+			"https://115.com/s/sw3abcd1234?password=q1w2# 访问码：q1w2 复制这段内容，可在115App中直接打开！",
+			[]Share{{"115", "sw3abcd1234", "q1w2", "https://115.com/s/sw3abcd1234"}},
+		},
+		{
+			"xunlei share text",
+			"分享文件：电影.mkv\n链接：https://pan.xunlei.com/s/VNaBcDeFgHiJkLmN?pwd=mn3k#\n复制这段内容后打开手机迅雷App，查看更方便",
+			[]Share{{"xunlei", "VNaBcDeFgHiJkLmN", "mn3k", "https://pan.xunlei.com/s/VNaBcDeFgHiJkLmN"}},
+		},
+		{
+			"123pan share text",
+			"https://www.123pan.com/s/abcD-EfGh.html提取码:zx90",
+			[]Share{{"123pan", "abcD-EfGh", "zx90", "https://www.123pan.com/s/abcD-EfGh"}},
+		},
+		{
+			"bare domain in text",
+			"资源地址pan.baidu.com/s/1AbCdEfGh 密码 k2j3",
+			[]Share{{"baidu", "1AbCdEfGh", "k2j3", "https://pan.baidu.com/s/1AbCdEfGh"}},
+		},
+		{
+			"trailing punctuation",
+			"下载：https://pan.quark.cn/s/1a2b3c4d5e6f。",
+			[]Share{{"quark", "1a2b3c4d5e6f", "", "https://pan.quark.cn/s/1a2b3c4d5e6f"}},
+		},
+		{
+			"url in parentheses",
+			"(https://pan.quark.cn/s/1a2b3c4d5e6f)",
+			[]Share{{"quark", "1a2b3c4d5e6f", "", "https://pan.quark.cn/s/1a2b3c4d5e6f"}},
+		},
+		{
+			"english pwd label",
+			"link: https://pan.quark.cn/s/1a2b3c4d5e6f pwd: ab12",
+			[]Share{{"quark", "1a2b3c4d5e6f", "ab12", "https://pan.quark.cn/s/1a2b3c4d5e6f"}},
+		},
+		{
+			"multiple shares each with code",
+			"百度：https://pan.baidu.com/s/1AbCdEfGh 提取码：aaaa\n夸克：https://pan.quark.cn/s/1a2b3c4d5e6f 提取码：bbbb",
+			[]Share{
+				{"baidu", "1AbCdEfGh", "aaaa", "https://pan.baidu.com/s/1AbCdEfGh"},
+				{"quark", "1a2b3c4d5e6f", "bbbb", "https://pan.quark.cn/s/1a2b3c4d5e6f"},
+			},
+		},
+		{
+			"multiple shares with codes before",
+			"提取码：aaaa 链接：https://pan.baidu.com/s/1AbCdEfGh\n提取码：bbbb 链接：https://pan.quark.cn/s/1a2b3c4d5e6f",
+			[]Share{
+				{"baidu", "1AbCdEfGh", "aaaa", "https://pan.baidu.com/s/1AbCdEfGh"},
+				{"quark", "1a2b3c4d5e6f", "bbbb", "https://pan.quark.cn/s/1a2b3c4d5e6f"},
+			},
+		},
+		{
+			"only first share has code",
+			"https://pan.baidu.com/s/1AbCdEfGh 提取码：aaaa\nhttps://www.alipan.com/s/AbC123dEf45",
+			[]Share{
+				{"baidu", "1AbCdEfGh", "aaaa", "https://pan.baidu.com/s/1AbCdEfGh"},
+				{"aliyun", "AbC123dEf45", "", "https://www.alipan.com/s/AbC123dEf45"},
+			},
+		},
+		{
+			"duplicate share merged",
+			"https://pan.baidu.com/s/1AbCdEfGh 备用：https://pan.baidu.com/share/init?surl=AbCdEfGh 提取码：aaaa",
+			[]Share{{"baidu", "1AbCdEfGh", "aaaa", "https://pan.baidu.com/s/1AbCdEfGh"}},
+		},
+		{
+			"unknown urls ignored",
+			"官网 https://example.com/download 网盘 https://pan.quark.cn/s/1a2b3c4d5e6f",
+			[]Share{{"quark", "1a2b3c4d5e6f", "", "https://pan.quark.cn/s/1a2b3c4d5e6f"}},
+		},
+		{
+			"code too long ignored",
+			"https://pan.quark.cn/s/1a2b3c4d5e6f 提取码：abcdefghijk",
+			[]Share{{"quark", "1a2b3c4d5e6f", "", "https://pan.quark.cn/s/1a2b3c4d5e6f"}},
+		},
+		{
+			"pwd inside word ignored",
+			"https://pan.quark.cn/s/1a2b3c4d5e6f resetpwd: ab12",
+			[]Share{{"quark", "1a2b3c4d5e6f", "", "https://pan.quark.cn/s/1a2b3c4d5e6f"}},
+		},
+		{
+			"email is not a share",
+			"联系 admin@pan.baidu.com/s/1AbCdEfGh",
+			nil,
+		},
+		{"no share", "这里没有链接，提取码：abcd", nil},
+		{"empty", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseAll(tt.text)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAll(%q)\n got  %+v\n want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	share, ok := Parse("链接: https://pan.baidu.com/s/1AbCdEfGh 提取码: x7k2\n备用: https://pan.quark.cn/s/1a2b3c4d5e6f")
+	if !ok || share.Provider != "baidu" || share.ExtractCode != "x7k2" {
+		t.Errorf("Parse returned %+v, %v", share, ok)
+	}
+	if _, ok := Parse("没有链接"); ok {
+		t.Error("Parse should fail without a share link")
+	}
+}
+
+func TestKeyIgnoresURLForm(t *testing.T) {
+	forms := []string{
+		"https://pan.baidu.com/s/1AbCdEfGh",
+		"http://yun.baidu.com/s/1AbCdEfGh?pwd=abcd",
+		"pan.baidu.com/share/init?surl=AbCdEfGh",
+	}
+	var keys []string
+	for _, form := range forms {
+		share, ok := ParseURL(form)
+		if !ok {
+			t.Fatalf("ParseURL(%q) failed", form)
+		}
+		keys = append(keys, share.Key())
+	}
+	for _, key := range keys {
+		if key != "baidu:1AbCdEfGh" {
+			t.Errorf("key = %q, want %q", key, "baidu:1AbCdEfGh")
+		}
+	}
+}
+
+func TestCanonicalURLRoundTrip(t *testing.T) {
+	for _, raw := range []string{
+		"https://pan.baidu.com/s/1AbCdEfGh",
+		"https://www.aliyundrive.com/s/AbC123dEf45",
+		"https://pan.quark.cn/s/1a2b3c4d5e6f",
+		"https://115cdn.com/s/sw3abcd1234",
+		"https://h5.cloud.189.cn/share.html#/t/AbCdEf123456",
+		"https://pan.xunlei.com/s/VNaBcDeFgHiJkLmN",
+		"https://wwi.lanzoux.com/iAbC123dEf",
+		"https://www.123684.com/s/abcD-EfGh.html",
+		"https://drive.uc.cn/s/a1b2c3d4e5f6",
+		"https://yun.139.com/link/w/i/0u5CaBcDeFgH",
+		"https://share.weiyun.com/AbCdEfGh",
+		"https://mypikpak.com/s/VNabcdefghijk",
+	} {
+		share, ok := ParseURL(raw)
+		if !ok {
+			t.Fatalf("ParseURL(%q) failed", raw)
+		}
+		again, ok := ParseURL(share.URL)
+		if !ok || again.Key() != share.Key() || again.URL != share.URL {
+			t.Errorf("canonical URL %q of %q does not round trip: %+v", share.URL, raw, again)
+		}
+	}
+}
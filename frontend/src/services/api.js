@@ -70,9 +70,9 @@ class ApiService {
     return apiClient.get('/resources/search', { params })
   }
 
-  // 获取热门推荐
-  async getHotResources(limit = 10) {
-    return apiClient.get('/resources/hot', { params: { limit } })
+  // 获取热门推荐，window为24h或7d，category为空时不限分类
+  async getHotResources(limit = 10, window = '24h', category) {
+    return apiClient.get('/resources/hot', { params: { limit, window, category } })
   }
 
   // 提交资源求助
@@ -90,14 +90,19 @@ class ApiService {
     return apiClient.post(`/resources/${resourceId}/download`, data)
   }
 
+  // 举报资源，reason为dead_link/wrong_code/wrong_category/illegal/other
+  async reportResource(resourceId, reason, description = '') {
+    return apiClient.post(`/resources/${resourceId}/report`, { reason, description })
+  }
+
   // 获取搜索建议
   async getSearchSuggestions(keyword) {
     return apiClient.get('/search/suggestions', { params: { q: keyword } })
   }
 
-  // 获取求助请求状态
-  async getRequestStatus(requestId) {
-    return apiClient.get(`/requests/${requestId}`)
+  // 获取求助请求状态，params 为 { token } 或 { contact }
+  async getRequestStatus(requestId, params) {
+    return apiClient.get(`/requests/${requestId}`, { params })
   }
 
   // 用户登录